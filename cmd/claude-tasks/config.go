@@ -0,0 +1,136 @@
+package main
+
+import (
+	"bytes"
+	"errors"
+	"flag"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+
+	"gopkg.in/yaml.v3"
+
+	"github.com/kylemclaren/claude-tasks/internal/api"
+	"github.com/kylemclaren/claude-tasks/internal/db"
+)
+
+// configFlag is set by the global --config flag; empty means ~/.claude-tasks/config.yaml if it exists
+var configFlag string
+
+// config holds the loaded config file. Fields left out of the file keep their zero values.
+var config fileConfig
+
+// fileConfig is the schema of the config file. Flags and environment variables
+// override it, and it only supplies defaults for settings stored in the database.
+type fileConfig struct {
+	DataDir        string   `yaml:"data_dir"`        // Relative paths are resolved against the config file
+	Port           int      `yaml:"port"`            // serve
+	UsageCheck     *bool    `yaml:"usage_check"`     // serve and daemon; false is --no-usage-check
+	CORSOrigins    []string `yaml:"cors_origins"`    // Default for the api_allowed_origins setting
+	RequestLogging *bool    `yaml:"request_logging"` // Default for the api_request_logging setting
+
+	// Replaces the allowed_working_dirs setting, which the API can't change; [] lifts the restriction
+	AllowedWorkingDirs *[]string `yaml:"allowed_working_dirs"`
+	// Replaces the claude_binary setting, also closed to the API; "" restores claude on PATH
+	ClaudeBinary *string `yaml:"claude_binary"`
+}
+
+// loadConfig reads --config, or the default config file when present, into config
+func loadConfig() error {
+	path := configFlag
+	if path == "" {
+		homeDir, err := os.UserHomeDir()
+		if err != nil {
+			return nil // No default location to look in
+		}
+		path = filepath.Join(homeDir, ".claude-tasks", "config.yaml")
+	}
+
+	data, err := os.ReadFile(path)
+	if errors.Is(err, os.ErrNotExist) && configFlag == "" {
+		return nil
+	}
+	if err != nil {
+		return fmt.Errorf("reading config file: %w", err)
+	}
+
+	// Reject unknown keys so a typo doesn't silently fall back to a default
+	dec := yaml.NewDecoder(bytes.NewReader(data))
+	dec.KnownFields(true)
+	var cfg fileConfig
+	if err := dec.Decode(&cfg); err != nil && !errors.Is(err, io.EOF) {
+		return fmt.Errorf("parsing config file %s: %w", path, err)
+	}
+	if cfg.Port != 0 && (cfg.Port < 1 || cfg.Port > 65535) {
+		return fmt.Errorf("config file %s: port must be between 1 and 65535", path)
+	}
+	if len(cfg.CORSOrigins) > 0 {
+		if err := api.ValidateOrigins(cfg.CORSOrigins); err != nil {
+			return fmt.Errorf("config file %s: cors_origins: %w", path, err)
+		}
+	}
+	if cfg.AllowedWorkingDirs != nil {
+		for i, dir := range *cfg.AllowedWorkingDirs {
+			if !filepath.IsAbs(dir) {
+				return fmt.Errorf("config file %s: allowed_working_dirs must be absolute paths: %s", path, dir)
+			}
+			(*cfg.AllowedWorkingDirs)[i] = filepath.Clean(dir)
+		}
+	}
+	if cfg.DataDir != "" && !filepath.IsAbs(cfg.DataDir) {
+		cfg.DataDir = filepath.Join(filepath.Dir(path), cfg.DataDir)
+	}
+
+	config = cfg
+	return nil
+}
+
+// applyConfig stores the settings the config file owns outright. Call it in
+// every command that runs tasks, before the scheduler starts.
+func applyConfig(database *db.DB) error {
+	if config.AllowedWorkingDirs != nil {
+		if err := database.SetAllowedWorkingDirs(*config.AllowedWorkingDirs); err != nil {
+			return fmt.Errorf("saving allowed_working_dirs: %w", err)
+		}
+	}
+	if config.ClaudeBinary != nil {
+		if err := database.SetClaudeBinary(strings.TrimSpace(*config.ClaudeBinary)); err != nil {
+			return fmt.Errorf("saving claude_binary: %w", err)
+		}
+	}
+	return nil
+}
+
+// settingDefaults returns the database settings the config file provides defaults for
+func (c fileConfig) settingDefaults() map[string]string {
+	defaults := make(map[string]string)
+	if len(c.CORSOrigins) > 0 {
+		defaults["api_allowed_origins"] = strings.Join(c.CORSOrigins, ",")
+	}
+	if c.RequestLogging != nil {
+		defaults["api_request_logging"] = strconv.FormatBool(*c.RequestLogging)
+	}
+	return defaults
+}
+
+// usageCheckDisabled resolves --no-usage-check, falling back to usage_check in the config file
+func usageCheckDisabled(fs *flag.FlagSet, noUsageCheck bool) bool {
+	if isFlagPassed(fs, "no-usage-check") || config.UsageCheck == nil {
+		return noUsageCheck
+	}
+	return !*config.UsageCheck
+}
+
+// isFlagPassed reports whether name was given explicitly on the command line
+func isFlagPassed(fs *flag.FlagSet, name string) bool {
+	passed := false
+	fs.Visit(func(f *flag.Flag) {
+		if f.Name == name {
+			passed = true
+		}
+	})
+	return passed
+}
@@ -0,0 +1,13 @@
+//go:build windows
+
+package main
+
+import "syscall"
+
+// detachedProcess is DETACHED_PROCESS from the Win32 API (not exported by syscall)
+const detachedProcess = 0x00000008
+
+// detachAttr starts the child without a console so it outlives the parent
+func detachAttr() *syscall.SysProcAttr {
+	return &syscall.SysProcAttr{CreationFlags: syscall.CREATE_NEW_PROCESS_GROUP | detachedProcess}
+}
@@ -0,0 +1,100 @@
+package main
+
+import (
+	"bufio"
+	"flag"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/kylemclaren/claude-tasks/internal/db"
+)
+
+// maxReportedProblems limits how many integrity_check lines are printed
+const maxReportedProblems = 20
+
+// runDoctor checks tasks.db for corruption, vacuums it, and rebuilds indexes if needed
+func runDoctor() error {
+	doctorCmd := flag.NewFlagSet("doctor", flag.ExitOnError)
+	yes := doctorCmd.Bool("yes", false, "Rebuild indexes without asking when problems are found")
+	_ = doctorCmd.Parse(os.Args[2:])
+
+	dataDir, err := getDataDir()
+	if err != nil {
+		return fmt.Errorf("getting home directory: %w", err)
+	}
+	dbPath := filepath.Join(dataDir, "tasks.db")
+	if _, running := isDaemonRunning(filepath.Join(dataDir, "daemon.pid")); running {
+		fmt.Println("Note: the daemon is running; stop it first (claude-tasks stop) if VACUUM reports the database is locked")
+	}
+
+	database, err := db.New(dbPath)
+	if err != nil {
+		return fmt.Errorf("opening database: %w", err)
+	}
+	defer database.Close()
+
+	fmt.Printf("Checking %s...\n", dbPath)
+	problems := checkIntegrity(database)
+	printProblems(problems)
+
+	fmt.Println("Running VACUUM...")
+	if err := database.Vacuum(); err != nil {
+		fmt.Printf("VACUUM failed: %v\n", err)
+	} else {
+		fmt.Println("VACUUM done")
+	}
+
+	if len(problems) == 0 {
+		return nil
+	}
+
+	if !*yes && !confirm("Rebuild indexes?") {
+		return fmt.Errorf("database has integrity problems; back up %s before further repair", dbPath)
+	}
+	fmt.Println("Rebuilding indexes...")
+	if err := database.Reindex(); err != nil {
+		return fmt.Errorf("reindex: %w", err)
+	}
+
+	problems = checkIntegrity(database)
+	printProblems(problems)
+	if len(problems) > 0 {
+		return fmt.Errorf("problems remain; restore %s from a backup or export what you can with the sqlite3 .recover command", dbPath)
+	}
+	return nil
+}
+
+// checkIntegrity runs the integrity check, reporting a failure to run it as a problem
+func checkIntegrity(database *db.DB) []string {
+	problems, err := database.IntegrityCheck()
+	if err != nil {
+		return []string{err.Error()}
+	}
+	return problems
+}
+
+// printProblems reports integrity_check output, truncated to maxReportedProblems lines
+func printProblems(problems []string) {
+	if len(problems) == 0 {
+		fmt.Println("Integrity check: ok")
+		return
+	}
+	fmt.Printf("Integrity check found %d problems:\n", len(problems))
+	for i, p := range problems {
+		if i == maxReportedProblems {
+			fmt.Printf("  ... and %d more\n", len(problems)-maxReportedProblems)
+			break
+		}
+		fmt.Printf("  %s\n", p)
+	}
+}
+
+// confirm asks a yes/no question on stdin; anything but y/yes is no
+func confirm(question string) bool {
+	fmt.Printf("%s [y/N] ", question)
+	answer, _ := bufio.NewReader(os.Stdin).ReadString('\n')
+	answer = strings.ToLower(strings.TrimSpace(answer))
+	return answer == "y" || answer == "yes"
+}
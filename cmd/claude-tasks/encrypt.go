@@ -0,0 +1,31 @@
+package main
+
+import (
+	"fmt"
+	"path/filepath"
+
+	"github.com/kylemclaren/claude-tasks/internal/db"
+)
+
+// runEncryptSecrets encrypts webhook URLs stored before an encryption key was configured
+func runEncryptSecrets() error {
+	dataDir, err := getDataDir()
+	if err != nil {
+		return fmt.Errorf("getting home directory: %w", err)
+	}
+	database, err := db.New(filepath.Join(dataDir, "tasks.db"))
+	if err != nil {
+		return fmt.Errorf("initializing database: %w", err)
+	}
+	defer database.Close()
+
+	if !database.EncryptionEnabled() {
+		return fmt.Errorf("set %s to the key to encrypt with", db.EncryptionKeyEnv)
+	}
+	n, err := database.EncryptSecrets()
+	if err != nil {
+		return fmt.Errorf("encrypting webhook URLs: %w", err)
+	}
+	fmt.Printf("Encrypted webhook URLs in %d task(s) and setting(s)\n", n)
+	return nil
+}
@@ -0,0 +1,134 @@
+package main
+
+import (
+	"encoding/csv"
+	"encoding/json"
+	"flag"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"slices"
+	"strconv"
+	"time"
+
+	"github.com/kylemclaren/claude-tasks/internal/db"
+)
+
+// runRecord is one row of `claude-tasks export-runs` output
+type runRecord struct {
+	ID          int64        `json:"id"`
+	Seq         int64        `json:"seq"` // Run number within the task
+	StartedAt   time.Time    `json:"started_at"`
+	EndedAt     *time.Time   `json:"ended_at,omitempty"`
+	Status      db.RunStatus `json:"status"`
+	DurationMs  *int64       `json:"duration_ms,omitempty"` // nil while the run is still going
+	Error       string       `json:"error,omitempty"`
+	OutputBytes int64        `json:"output_bytes"`
+}
+
+// runExportRuns writes a task's run history, oldest first, as CSV or JSON
+func runExportRuns() error {
+	exportCmd := flag.NewFlagSet("export-runs", flag.ExitOnError)
+	format := exportCmd.String("format", "csv", "Output format: csv or json")
+	output := exportCmd.String("output", "", "Write to this file instead of stdout")
+	_ = exportCmd.Parse(os.Args[2:])
+
+	if exportCmd.NArg() != 1 {
+		return fmt.Errorf("usage: claude-tasks export-runs [--format csv|json] [--output <file>] <task-id>")
+	}
+	taskID, err := strconv.ParseInt(exportCmd.Arg(0), 10, 64)
+	if err != nil {
+		return fmt.Errorf("invalid task ID %q", exportCmd.Arg(0))
+	}
+	if *format != "csv" && *format != "json" {
+		return fmt.Errorf("--format must be csv or json")
+	}
+
+	dataDir, err := getDataDir()
+	if err != nil {
+		return fmt.Errorf("getting home directory: %w", err)
+	}
+	database, err := db.New(filepath.Join(dataDir, "tasks.db"))
+	if err != nil {
+		return fmt.Errorf("initializing database: %w", err)
+	}
+	defer database.Close()
+
+	if _, err := database.GetTask(taskID); err != nil {
+		return fmt.Errorf("task %d not found", taskID)
+	}
+	runs, err := database.GetAllTaskRuns(taskID)
+	if err != nil {
+		return fmt.Errorf("listing runs: %w", err)
+	}
+	slices.Reverse(runs)
+
+	records := make([]runRecord, 0, len(runs))
+	for _, run := range runs {
+		record := runRecord{
+			ID:          run.ID,
+			Seq:         run.Seq,
+			StartedAt:   run.StartedAt,
+			EndedAt:     run.EndedAt,
+			Status:      run.Status,
+			Error:       run.Error,
+			OutputBytes: int64(len(run.Output)),
+		}
+		if run.EndedAt != nil {
+			ms := run.EndedAt.Sub(run.StartedAt).Milliseconds()
+			record.DurationMs = &ms
+		}
+		// File-backed runs only keep a preview in the DB
+		if run.OutputPath != "" {
+			if info, err := os.Stat(run.OutputPath); err == nil {
+				record.OutputBytes = info.Size()
+			}
+		}
+		records = append(records, record)
+	}
+
+	var w io.Writer = os.Stdout
+	if *output != "" {
+		f, err := os.Create(*output)
+		if err != nil {
+			return fmt.Errorf("creating output file: %w", err)
+		}
+		defer f.Close()
+		w = f
+	}
+
+	if *format == "json" {
+		enc := json.NewEncoder(w)
+		enc.SetIndent("", "  ")
+		return enc.Encode(records)
+	}
+	return writeRunsCSV(w, records)
+}
+
+// writeRunsCSV writes records with a header row; times are RFC3339 and unset values are empty
+func writeRunsCSV(w io.Writer, records []runRecord) error {
+	cw := csv.NewWriter(w)
+	_ = cw.Write([]string{"id", "seq", "started_at", "ended_at", "status", "duration_ms", "error", "output_bytes"})
+	for _, r := range records {
+		var endedAt, duration string
+		if r.EndedAt != nil {
+			endedAt = r.EndedAt.Format(time.RFC3339)
+		}
+		if r.DurationMs != nil {
+			duration = strconv.FormatInt(*r.DurationMs, 10)
+		}
+		_ = cw.Write([]string{
+			strconv.FormatInt(r.ID, 10),
+			strconv.FormatInt(r.Seq, 10),
+			r.StartedAt.Format(time.RFC3339),
+			endedAt,
+			string(r.Status),
+			duration,
+			r.Error,
+			strconv.FormatInt(r.OutputBytes, 10),
+		})
+	}
+	cw.Flush()
+	return cw.Error()
+}
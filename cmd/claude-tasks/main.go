@@ -2,24 +2,47 @@ package main
 
 import (
 	"context"
+	"encoding/json"
 	"flag"
 	"fmt"
 	"net/http"
 	"os"
+	"os/exec"
 	"os/signal"
 	"path/filepath"
+	"strings"
 	"syscall"
 	"time"
+	"unicode/utf8"
 
 	"github.com/kylemclaren/claude-tasks/internal/api"
+	"github.com/kylemclaren/claude-tasks/internal/cronexpr"
 	"github.com/kylemclaren/claude-tasks/internal/db"
 	"github.com/kylemclaren/claude-tasks/internal/scheduler"
 	"github.com/kylemclaren/claude-tasks/internal/tui"
 	"github.com/kylemclaren/claude-tasks/internal/upgrade"
+	"github.com/kylemclaren/claude-tasks/internal/usage"
 	"github.com/kylemclaren/claude-tasks/internal/version"
+	"github.com/kylemclaren/claude-tasks/internal/webhook"
 )
 
+// dataDirFlag is set by the global --data flag and takes precedence over CLAUDE_TASKS_DATA
+var dataDirFlag string
+
 func main() {
+	// Strip global flags so subcommands see their own arguments at os.Args[2:]
+	args, err := parseGlobalFlags(os.Args)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Error: %v\n\n", err)
+		printHelp()
+		os.Exit(1)
+	}
+	os.Args = args
+	if err := loadConfig(); err != nil {
+		fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+		os.Exit(1)
+	}
+
 	// Handle CLI commands
 	if len(os.Args) > 1 {
 		switch os.Args[1] {
@@ -27,7 +50,10 @@ func main() {
 			fmt.Println(version.Info())
 			return
 		case "upgrade":
-			if err := upgrade.Upgrade(); err != nil {
+			upgradeCmd := flag.NewFlagSet("upgrade", flag.ExitOnError)
+			retries := upgradeCmd.Int("retries", upgrade.DefaultRetries, "How many times to resume a failed download")
+			_ = upgradeCmd.Parse(os.Args[2:])
+			if err := upgrade.Upgrade(*retries); err != nil {
 				fmt.Fprintf(os.Stderr, "Error: %v\n", err)
 				os.Exit(1)
 			}
@@ -47,6 +73,48 @@ func main() {
 				os.Exit(1)
 			}
 			return
+		case "status":
+			if err := runStatus(); err != nil {
+				fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+				os.Exit(1)
+			}
+			return
+		case "add":
+			if err := runAdd(); err != nil {
+				fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+				os.Exit(1)
+			}
+			return
+		case "sync":
+			if err := runSync(); err != nil {
+				fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+				os.Exit(1)
+			}
+			return
+		case "doctor":
+			if err := runDoctor(); err != nil {
+				fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+				os.Exit(1)
+			}
+			return
+		case "encrypt-secrets":
+			if err := runEncryptSecrets(); err != nil {
+				fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+				os.Exit(1)
+			}
+			return
+		case "export-runs":
+			if err := runExportRuns(); err != nil {
+				fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+				os.Exit(1)
+			}
+			return
+		case "stop":
+			if err := runStop(); err != nil {
+				fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+				os.Exit(1)
+			}
+			return
 		default:
 			fmt.Fprintf(os.Stderr, "Unknown command: %s\n\n", os.Args[1])
 			printHelp()
@@ -55,14 +123,10 @@ func main() {
 	}
 
 	// Determine database path
-	dataDir := os.Getenv("CLAUDE_TASKS_DATA")
-	if dataDir == "" {
-		homeDir, err := os.UserHomeDir()
-		if err != nil {
-			fmt.Fprintf(os.Stderr, "Error getting home directory: %v\n", err)
-			os.Exit(1)
-		}
-		dataDir = filepath.Join(homeDir, ".claude-tasks")
+	dataDir, err := getDataDir()
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Error getting home directory: %v\n", err)
+		os.Exit(1)
 	}
 
 	dbPath := filepath.Join(dataDir, "tasks.db")
@@ -75,6 +139,10 @@ func main() {
 		os.Exit(1)
 	}
 	defer database.Close()
+	if err := applyConfig(database); err != nil {
+		fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+		os.Exit(1)
+	}
 
 	// Check if daemon is running
 	daemonPID, daemonRunning := isDaemonRunning(pidPath)
@@ -94,20 +162,28 @@ func main() {
 	}
 
 	// Run TUI
-	if err := tui.Run(database, sched, daemonRunning); err != nil {
+	daemonAlive := func() bool {
+		_, running := isDaemonRunning(pidPath)
+		return running
+	}
+	if err := tui.Run(database, sched, daemonRunning, daemonAlive); err != nil {
 		fmt.Fprintf(os.Stderr, "Error running TUI: %v\n", err)
 		os.Exit(1)
 	}
 }
 
 func runDaemon() error {
-	dataDir := os.Getenv("CLAUDE_TASKS_DATA")
-	if dataDir == "" {
-		homeDir, err := os.UserHomeDir()
-		if err != nil {
-			return fmt.Errorf("getting home directory: %w", err)
-		}
-		dataDir = filepath.Join(homeDir, ".claude-tasks")
+	// Parse flags for daemon command
+	daemonCmd := flag.NewFlagSet("daemon", flag.ExitOnError)
+	foreground := daemonCmd.Bool("foreground", true, "Run in the foreground (false detaches into the background)")
+	noUsageCheck := daemonCmd.Bool("no-usage-check", false, "Disable usage fetching and threshold enforcement")
+	verbose := daemonCmd.Bool("verbose", false, "Print task output to stdout as it runs, prefixed with the task name")
+	_ = daemonCmd.Parse(os.Args[2:])
+	noUsage := usageCheckDisabled(daemonCmd, *noUsageCheck)
+
+	dataDir, err := getDataDir()
+	if err != nil {
+		return fmt.Errorf("getting home directory: %w", err)
 	}
 
 	dbPath := filepath.Join(dataDir, "tasks.db")
@@ -118,6 +194,17 @@ func runDaemon() error {
 		return fmt.Errorf("daemon already running (PID %d)", pid)
 	}
 
+	if !*foreground {
+		var childArgs []string
+		if noUsage {
+			childArgs = append(childArgs, "--no-usage-check")
+		}
+		if *verbose {
+			childArgs = append(childArgs, "--verbose")
+		}
+		return detachDaemon(dataDir, pidPath, childArgs)
+	}
+
 	// Write PID file
 	if err := os.WriteFile(pidPath, []byte(fmt.Sprintf("%d", os.Getpid())), 0644); err != nil {
 		return fmt.Errorf("writing PID file: %w", err)
@@ -129,8 +216,17 @@ func runDaemon() error {
 		return fmt.Errorf("initializing database: %w", err)
 	}
 	defer database.Close()
+	if err := applyConfig(database); err != nil {
+		return err
+	}
 
 	sched := scheduler.New(database)
+	if noUsage {
+		sched.DisableUsageCheck()
+	}
+	if *verbose {
+		sched.EchoOutput(os.Stdout)
+	}
 	if err := sched.Start(); err != nil {
 		return fmt.Errorf("starting scheduler: %w", err)
 	}
@@ -140,28 +236,40 @@ func runDaemon() error {
 	fmt.Printf("PID: %d\n", os.Getpid())
 	fmt.Printf("Database: %s\n", dbPath)
 
-	// Wait for shutdown signal
-	sigCh := make(chan os.Signal, 1)
-	signal.Notify(sigCh, syscall.SIGINT, syscall.SIGTERM)
-	<-sigCh
+	waitForShutdown(sched.Reload)
 
 	fmt.Println("\nShutting down...")
 	return nil
 }
 
+// waitForShutdown blocks until SIGINT or SIGTERM, calling reload on each SIGHUP
+func waitForShutdown(reload func()) {
+	sigCh := make(chan os.Signal, 1)
+	signal.Notify(sigCh, syscall.SIGINT, syscall.SIGTERM, syscall.SIGHUP)
+	defer signal.Stop(sigCh)
+	for sig := range sigCh {
+		if sig != syscall.SIGHUP {
+			return
+		}
+		fmt.Println("SIGHUP received, reloading tasks and settings")
+		reload()
+	}
+}
+
 func runServer() error {
 	// Parse flags for serve command
 	serveCmd := flag.NewFlagSet("serve", flag.ExitOnError)
 	port := serveCmd.Int("port", 8080, "HTTP server port")
+	noUsageCheck := serveCmd.Bool("no-usage-check", false, "Disable usage fetching and threshold enforcement")
 	_ = serveCmd.Parse(os.Args[2:])
+	if !isFlagPassed(serveCmd, "port") && config.Port != 0 {
+		*port = config.Port
+	}
+	noUsage := usageCheckDisabled(serveCmd, *noUsageCheck)
 
-	dataDir := os.Getenv("CLAUDE_TASKS_DATA")
-	if dataDir == "" {
-		homeDir, err := os.UserHomeDir()
-		if err != nil {
-			return fmt.Errorf("getting home directory: %w", err)
-		}
-		dataDir = filepath.Join(homeDir, ".claude-tasks")
+	dataDir, err := getDataDir()
+	if err != nil {
+		return fmt.Errorf("getting home directory: %w", err)
 	}
 
 	dbPath := filepath.Join(dataDir, "tasks.db")
@@ -171,22 +279,38 @@ func runServer() error {
 		return fmt.Errorf("initializing database: %w", err)
 	}
 	defer database.Close()
+	if err := applyConfig(database); err != nil {
+		return err
+	}
+	database.SetSettingDefaults(config.settingDefaults())
 
 	sched := scheduler.New(database)
+	if noUsage {
+		sched.DisableUsageCheck()
+	}
 	if err := sched.Start(); err != nil {
 		return fmt.Errorf("starting scheduler: %w", err)
 	}
 	defer sched.Stop()
 
 	server := api.NewServer(database, sched)
+	if noUsage {
+		server.DisableUsageCheck()
+	}
 
 	addr := fmt.Sprintf(":%d", *port)
 	fmt.Printf("claude-tasks API server starting on %s\n", addr)
 	fmt.Printf("Database: %s\n", dbPath)
 
+	// No route streams responses, so a fixed WriteTimeout is safe; a streaming
+	// route would need to extend its deadline via http.ResponseController.
 	srv := &http.Server{
-		Addr:    addr,
-		Handler: server.Router(),
+		Addr:              addr,
+		Handler:           server.Router(),
+		ReadHeaderTimeout: 10 * time.Second,
+		ReadTimeout:       30 * time.Second,
+		WriteTimeout:      60 * time.Second,
+		IdleTimeout:       120 * time.Second,
 	}
 
 	// Start server in goroutine
@@ -196,10 +320,7 @@ func runServer() error {
 		}
 	}()
 
-	// Wait for shutdown signal
-	sigCh := make(chan os.Signal, 1)
-	signal.Notify(sigCh, syscall.SIGINT, syscall.SIGTERM)
-	<-sigCh
+	waitForShutdown(server.Reload)
 
 	fmt.Println("\nShutting down server...")
 
@@ -209,6 +330,365 @@ func runServer() error {
 	return srv.Shutdown(ctx)
 }
 
+// statusReport is the output of the status command
+type statusReport struct {
+	Daemon struct {
+		Running bool `json:"running"`
+		PID     int  `json:"pid,omitempty"`
+	} `json:"daemon"`
+	Database     string       `json:"database"`
+	TotalTasks   int          `json:"total_tasks"`
+	EnabledTasks int          `json:"enabled_tasks"`
+	RunningTasks int          `json:"running_tasks"`
+	Usage        *statusUsage `json:"usage,omitempty"`
+	UsageError   string       `json:"usage_error,omitempty"`
+}
+
+// statusUsage is the usage section of the status command output
+type statusUsage struct {
+	FiveHour  float64 `json:"five_hour"`
+	SevenDay  float64 `json:"seven_day"`
+	Threshold float64 `json:"threshold"`
+	ResetsIn  string  `json:"resets_in"`
+}
+
+func runStatus() error {
+	statusCmd := flag.NewFlagSet("status", flag.ExitOnError)
+	jsonOutput := statusCmd.Bool("json", false, "Output as JSON")
+	_ = statusCmd.Parse(os.Args[2:])
+
+	dataDir, err := getDataDir()
+	if err != nil {
+		return fmt.Errorf("getting home directory: %w", err)
+	}
+
+	dbPath := filepath.Join(dataDir, "tasks.db")
+	pidPath := filepath.Join(dataDir, "daemon.pid")
+
+	database, err := db.New(dbPath)
+	if err != nil {
+		return fmt.Errorf("initializing database: %w", err)
+	}
+	defer database.Close()
+
+	var report statusReport
+	report.Database = dbPath
+	report.Daemon.PID, report.Daemon.Running = isDaemonRunning(pidPath)
+
+	tasks, err := database.ListTasks()
+	if err != nil {
+		return fmt.Errorf("listing tasks: %w", err)
+	}
+	report.TotalTasks = len(tasks)
+	for _, task := range tasks {
+		if task.Enabled {
+			report.EnabledTasks++
+		}
+	}
+
+	statuses, err := database.GetLastRunStatuses()
+	if err != nil {
+		return fmt.Errorf("fetching run statuses: %w", err)
+	}
+	for _, status := range statuses {
+		if status == db.RunStatusRunning {
+			report.RunningTasks++
+		}
+	}
+
+	threshold, _ := database.GetUsageThreshold()
+	if enabled, _ := database.GetUsageCheckEnabled(); !enabled {
+		report.UsageError = "usage checking disabled"
+	} else if client, err := usage.NewClient(); err != nil {
+		report.UsageError = err.Error()
+	} else if data, err := client.Fetch(); err != nil {
+		report.UsageError = err.Error()
+	} else {
+		report.Usage = &statusUsage{
+			FiveHour:  data.FiveHour.Utilization,
+			SevenDay:  data.SevenDay.Utilization,
+			Threshold: threshold,
+			ResetsIn:  data.FormatTimeUntilReset(),
+		}
+	}
+
+	if *jsonOutput {
+		enc := json.NewEncoder(os.Stdout)
+		enc.SetIndent("", "  ")
+		return enc.Encode(report)
+	}
+
+	if report.Daemon.Running {
+		fmt.Printf("Daemon:    running (PID %d)\n", report.Daemon.PID)
+	} else {
+		fmt.Println("Daemon:    not running")
+	}
+	fmt.Printf("Database:  %s\n", report.Database)
+	fmt.Printf("Tasks:     %d total, %d enabled\n", report.TotalTasks, report.EnabledTasks)
+	fmt.Printf("Running:   %d\n", report.RunningTasks)
+	if report.Usage != nil {
+		fmt.Printf("Usage:     5h %.0f%% │ 7d %.0f%% │ threshold %.0f%% │ resets in %s\n",
+			report.Usage.FiveHour, report.Usage.SevenDay, report.Usage.Threshold, report.Usage.ResetsIn)
+	} else {
+		fmt.Printf("Usage:     unavailable (%s)\n", report.UsageError)
+	}
+
+	return nil
+}
+
+// runAdd creates a task from command-line flags. A running daemon picks it up on its next sync;
+// otherwise it is scheduled the next time the daemon, TUI, or server starts.
+func runAdd() error {
+	addCmd := flag.NewFlagSet("add", flag.ExitOnError)
+	name := addCmd.String("name", "", "Task name")
+	prompt := addCmd.String("prompt", "", "Prompt to send to Claude")
+	promptFile := addCmd.String("prompt-file", "", "Read the prompt from this file at run time")
+	cronExpr := addCmd.String("cron", "", "cron expression, 6-field (second minute hour dom month dow) or 5-field")
+	dir := addCmd.String("dir", ".", "Working directory")
+	discord := addCmd.String("discord", "", "Discord webhook URL (default: the default_discord_webhook setting; - for none)")
+	slack := addCmd.String("slack", "", "Slack webhook URL (default: the default_slack_webhook setting; - for none)")
+	enabled := addCmd.Bool("enabled", false, "Enable the task immediately")
+	_ = addCmd.Parse(os.Args[2:])
+
+	if *name == "" {
+		return fmt.Errorf("--name is required")
+	}
+	if *prompt == "" && *promptFile == "" {
+		return fmt.Errorf("one of --prompt or --prompt-file is required")
+	}
+	if *prompt != "" && *promptFile != "" {
+		return fmt.Errorf("--prompt and --prompt-file are mutually exclusive")
+	}
+	if *cronExpr == "" {
+		return fmt.Errorf("--cron is required")
+	}
+	if _, err := cronexpr.Parser.Parse(*cronExpr); err != nil {
+		return fmt.Errorf("invalid cron expression: %w", err)
+	}
+
+	workingDir, err := filepath.Abs(*dir)
+	if err != nil {
+		return fmt.Errorf("resolving working directory: %w", err)
+	}
+	if info, err := os.Stat(workingDir); err != nil || !info.IsDir() {
+		return fmt.Errorf("working directory not found: %s", workingDir)
+	}
+	if *promptFile != "" {
+		path, err := filepath.Abs(*promptFile)
+		if err != nil {
+			return fmt.Errorf("resolving prompt file: %w", err)
+		}
+		if info, err := os.Stat(path); err != nil || info.IsDir() {
+			return fmt.Errorf("prompt file not found: %s", path)
+		}
+		*promptFile = path
+	}
+
+	dataDir, err := getDataDir()
+	if err != nil {
+		return fmt.Errorf("getting home directory: %w", err)
+	}
+
+	database, err := db.New(filepath.Join(dataDir, "tasks.db"))
+	if err != nil {
+		return fmt.Errorf("initializing database: %w", err)
+	}
+	defer database.Close()
+
+	if limit, _ := database.GetMaxPromptLength(); utf8.RuneCountInString(*prompt) > limit {
+		return fmt.Errorf("--prompt must be at most %d characters (max_prompt_length)", limit)
+	}
+	relaxed, _ := database.GetRelaxedWebhookURLs()
+	if *discord != "" && *discord != db.WebhookOptOut {
+		if err := webhook.ValidateDiscordURL(*discord, relaxed); err != nil {
+			return fmt.Errorf("discord webhook URL %w", err)
+		}
+	}
+	if *slack != "" && *slack != db.WebhookOptOut {
+		if err := webhook.ValidateSlackURL(*slack, relaxed); err != nil {
+			return fmt.Errorf("slack webhook URL %w", err)
+		}
+	}
+
+	task := &db.Task{
+		Name:            *name,
+		Prompt:          *prompt,
+		PromptFile:      *promptFile,
+		OutputFormat:    db.OutputFormatText,
+		CronExpr:        *cronExpr,
+		WorkingDir:      workingDir,
+		DiscordWebhook:  *discord,
+		SlackWebhook:    *slack,
+		StripAnsi:       true,
+		SkipPermissions: true,
+		Enabled:         *enabled,
+	}
+	if err := database.CreateTask(task); err != nil {
+		return fmt.Errorf("creating task: %w", err)
+	}
+
+	fmt.Println(task.ID)
+	if !task.Enabled {
+		fmt.Fprintln(os.Stderr, "Task created disabled; pass --enabled or toggle it in the TUI to schedule it")
+	} else if _, running := isDaemonRunning(filepath.Join(dataDir, "daemon.pid")); !running {
+		fmt.Fprintln(os.Stderr, "No daemon running; the task will be scheduled when the daemon, TUI, or server starts")
+	}
+	return nil
+}
+
+// globalFlags maps each global flag that precedes the subcommand to its variable and value name
+var globalFlags = map[string]struct {
+	value *string
+	arg   string
+}{
+	"data":   {&dataDirFlag, "a directory"},
+	"config": {&configFlag, "a file"},
+}
+
+// parseGlobalFlags consumes global flags that precede the subcommand and returns the remaining args
+func parseGlobalFlags(args []string) ([]string, error) {
+	rest := []string{args[0]}
+	i := 1
+	for ; i < len(args); i++ {
+		name, value, hasValue := strings.Cut(strings.TrimLeft(args[i], "-"), "=")
+		global, ok := globalFlags[name]
+		if !ok || !strings.HasPrefix(args[i], "-") {
+			return append(rest, args[i:]...), nil
+		}
+		if !hasValue {
+			if i+1 >= len(args) {
+				return nil, fmt.Errorf("--%s requires %s", name, global.arg)
+			}
+			i++
+			value = args[i]
+		}
+		if value == "" {
+			return nil, fmt.Errorf("--%s requires %s", name, global.arg)
+		}
+		*global.value = value
+	}
+	return rest, nil
+}
+
+// getDataDir returns the data directory from --data, CLAUDE_TASKS_DATA, the config file, or the home default
+func getDataDir() (string, error) {
+	if dataDirFlag != "" {
+		return dataDirFlag, nil
+	}
+	if dataDir := os.Getenv("CLAUDE_TASKS_DATA"); dataDir != "" {
+		return dataDir, nil
+	}
+	if config.DataDir != "" {
+		return config.DataDir, nil
+	}
+	homeDir, err := os.UserHomeDir()
+	if err != nil {
+		return "", err
+	}
+	return filepath.Join(homeDir, ".claude-tasks"), nil
+}
+
+// detachDaemon re-executes the binary as a background foreground-mode daemon
+// logging to daemon.log, then waits for the child to write its PID file
+func detachDaemon(dataDir, pidPath string, daemonArgs []string) error {
+	if err := os.MkdirAll(dataDir, 0755); err != nil {
+		return fmt.Errorf("creating data directory: %w", err)
+	}
+
+	exe, err := os.Executable()
+	if err != nil {
+		return fmt.Errorf("locating executable: %w", err)
+	}
+
+	logPath := filepath.Join(dataDir, "daemon.log")
+	logFile, err := os.OpenFile(logPath, os.O_CREATE|os.O_WRONLY|os.O_APPEND, 0644)
+	if err != nil {
+		return fmt.Errorf("opening log file: %w", err)
+	}
+	defer logFile.Close()
+
+	globalArgs := []string{"--data", dataDir}
+	if configFlag != "" {
+		globalArgs = append(globalArgs, "--config", configFlag)
+	}
+	cmd := exec.Command(exe, append(append(globalArgs, "daemon"), daemonArgs...)...)
+	cmd.Stdout = logFile
+	cmd.Stderr = logFile
+	cmd.SysProcAttr = detachAttr()
+	if err := cmd.Start(); err != nil {
+		return fmt.Errorf("starting daemon: %w", err)
+	}
+
+	// Reap the child if it exits early so the wait below can report it
+	exited := make(chan error, 1)
+	go func() { exited <- cmd.Wait() }()
+
+	deadline := time.After(5 * time.Second)
+	for {
+		if pid, running := isDaemonRunning(pidPath); running && pid == cmd.Process.Pid {
+			fmt.Printf("claude-tasks daemon started in background (PID %d)\n", pid)
+			fmt.Printf("Logs: %s\n", logPath)
+			return nil
+		}
+		select {
+		case err := <-exited:
+			return fmt.Errorf("daemon exited during startup (%v); see %s", err, logPath)
+		case <-deadline:
+			return fmt.Errorf("daemon did not start within 5s; see %s", logPath)
+		case <-time.After(100 * time.Millisecond):
+		}
+	}
+}
+
+// runStop sends SIGTERM to the running daemon and waits for it to exit
+func runStop() error {
+	stopCmd := flag.NewFlagSet("stop", flag.ExitOnError)
+	timeout := stopCmd.Duration("timeout", 30*time.Second, "How long to wait for the daemon to exit")
+	_ = stopCmd.Parse(os.Args[2:])
+
+	dataDir, err := getDataDir()
+	if err != nil {
+		return fmt.Errorf("getting home directory: %w", err)
+	}
+	pidPath := filepath.Join(dataDir, "daemon.pid")
+
+	pid, running := isDaemonRunning(pidPath)
+	if !running {
+		if _, err := os.Stat(pidPath); err == nil {
+			_ = os.Remove(pidPath)
+			fmt.Println("No daemon running (removed stale PID file)")
+			return nil
+		}
+		fmt.Println("No daemon running")
+		return nil
+	}
+
+	process, err := os.FindProcess(pid)
+	if err != nil {
+		return fmt.Errorf("finding daemon process: %w", err)
+	}
+	// SIGTERM lets the daemon stop its scheduler and remove its PID file;
+	// platforms without it (Windows) fall back to killing the process
+	if err := process.Signal(syscall.SIGTERM); err != nil {
+		if err := process.Kill(); err != nil {
+			return fmt.Errorf("signaling daemon (PID %d): %w", pid, err)
+		}
+	}
+
+	fmt.Printf("Stopping daemon (PID %d)...\n", pid)
+	deadline := time.Now().Add(*timeout)
+	for time.Now().Before(deadline) {
+		if _, running := isDaemonRunning(pidPath); !running {
+			_ = os.Remove(pidPath) // No-op if the daemon cleaned up after itself
+			fmt.Println("Daemon stopped")
+			return nil
+		}
+		time.Sleep(100 * time.Millisecond)
+	}
+
+	return fmt.Errorf("daemon (PID %d) did not exit within %s; it may be waiting for running tasks to finish", pid, *timeout)
+}
+
 // isDaemonRunning checks if a daemon is running by reading PID file and checking process
 func isDaemonRunning(pidPath string) (int, bool) {
 	data, err := os.ReadFile(pidPath)
@@ -239,15 +719,63 @@ func printHelp() {
 	fmt.Println(`claude-tasks - Schedule and run Claude CLI tasks via cron
 
 Usage:
+  claude-tasks [--data <dir>] [--config <file>] [command]
+
+Commands:
   claude-tasks              Launch the interactive TUI
   claude-tasks daemon       Run scheduler in foreground (for services)
   claude-tasks serve        Run HTTP API server (for mobile/remote access)
+  claude-tasks status       Show daemon, task, and usage summary
+  claude-tasks add          Create a task from flags and print its ID
+  claude-tasks sync <file>  Create or update tasks from a YAML file, matched by name
+  claude-tasks export-runs <id>  Write a task's run history as CSV or JSON
+  claude-tasks stop         Stop a running daemon
+  claude-tasks doctor       Check the database for corruption and try to repair it
+  claude-tasks encrypt-secrets  Encrypt stored webhook URLs with CLAUDE_TASKS_ENCRYPTION_KEY
   claude-tasks version      Show version information
   claude-tasks upgrade      Upgrade to the latest version
   claude-tasks help         Show this help message
 
+Global Options:
+  --data <dir>              Data directory (overrides CLAUDE_TASKS_DATA)
+  --config <file>           Config file (default: ~/.claude-tasks/config.yaml if present)
+
+Daemon Options:
+  --foreground=false        Detach into the background, logging to daemon.log
+  --no-usage-check          Disable usage fetching and threshold enforcement
+  --verbose                 Print task output live, prefixed with the task name
+
+Upgrade Options:
+  --retries                 How many times to resume a failed download (default: 3)
+
+Doctor Options:
+  --yes                     Rebuild indexes without asking when problems are found
+
+Stop Options:
+  --timeout                 How long to wait for the daemon to exit (default: 30s)
+
 Serve Options:
   --port                    HTTP server port (default: 8080)
+  --no-usage-check          Disable usage fetching and threshold enforcement
+
+Status Options:
+  --json                    Output as JSON
+
+Add Options:
+  --name, --cron            Task name and 5- or 6-field cron expression (required)
+  --prompt                  Prompt text (or use --prompt-file)
+  --prompt-file             File read as the prompt at run time
+  --dir                     Working directory (default: current directory)
+  --discord, --slack        Webhook URLs for notifications (- skips the defaults)
+  --enabled                 Enable the task immediately
+
+Sync Options:
+  --prune                   Delete tasks that are not in the file
+  --dry-run                 Show what would change without writing
+
+Export Runs Options:
+  --format                  csv or json (default: csv)
+  --output                  Write to this file instead of stdout
 
 Environment Variables:
   CLAUDE_TASKS_DATA         Override data directory (default: ~/.claude-tasks)
@@ -0,0 +1,349 @@
+package main
+
+import (
+	"flag"
+	"fmt"
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+	"time"
+	"unicode/utf8"
+
+	"gopkg.in/yaml.v3"
+
+	"github.com/kylemclaren/claude-tasks/internal/cronexpr"
+	"github.com/kylemclaren/claude-tasks/internal/db"
+	"github.com/kylemclaren/claude-tasks/internal/executor"
+	"github.com/kylemclaren/claude-tasks/internal/webhook"
+)
+
+// taskFile is the schema read by `claude-tasks sync`
+type taskFile struct {
+	Tasks []taskDefinition `yaml:"tasks"`
+}
+
+// taskDefinition maps one entry in a task file onto db.Task. Tasks are matched by Name.
+type taskDefinition struct {
+	Name                  string     `yaml:"name"`
+	Prompt                string     `yaml:"prompt"`
+	PromptFile            string     `yaml:"prompt_file"`
+	StdinFile             string     `yaml:"stdin_file"`
+	SystemPrompt          string     `yaml:"system_prompt"`
+	OutputFormat          string     `yaml:"output_format"`
+	Cron                  string     `yaml:"cron"`
+	ScheduledAt           *time.Time `yaml:"scheduled_at"`
+	WorkingDir            string     `yaml:"working_dir"` // Relative paths resolve against the file's directory; ~/ is expanded
+	DiscordWebhook        string     `yaml:"discord_webhook"`
+	SlackWebhook          string     `yaml:"slack_webhook"`
+	UsageThreshold        *float64   `yaml:"usage_threshold"`
+	Tags                  []string   `yaml:"tags"`
+	RunDuringQuietHours   bool       `yaml:"run_during_quiet_hours"`
+	JitterSeconds         int        `yaml:"jitter_seconds"`
+	ActiveFrom            *time.Time `yaml:"active_from"`
+	ActiveUntil           *time.Time `yaml:"active_until"`
+	AlertAfterSeconds     int        `yaml:"alert_after_seconds"`
+	DisableAfterFailures  int        `yaml:"disable_after_failures"`
+	StripAnsi             *bool      `yaml:"strip_ansi"` // Defaults to true
+	IncludePreviousOutput bool       `yaml:"include_previous_output"`
+	TemplatePrompt        bool       `yaml:"template_prompt"`
+	SkipPermissions       *bool      `yaml:"skip_permissions"` // Defaults to true
+	AllowedTools          string     `yaml:"allowed_tools"`
+	Container             string     `yaml:"container"`      // Docker image to run the CLI in
+	WebhookDetail         string     `yaml:"webhook_detail"` // Empty uses the global setting
+	ResumeSession         bool       `yaml:"resume_session"`
+	Enabled               *bool      `yaml:"enabled"` // Defaults to true
+}
+
+// runSync upserts the tasks defined in a YAML file by name, optionally deleting tasks not in the file
+func runSync() error {
+	syncCmd := flag.NewFlagSet("sync", flag.ExitOnError)
+	prune := syncCmd.Bool("prune", false, "Delete tasks that are not in the file")
+	dryRun := syncCmd.Bool("dry-run", false, "Show what would change without writing")
+	_ = syncCmd.Parse(os.Args[2:])
+
+	if syncCmd.NArg() != 1 {
+		return fmt.Errorf("usage: claude-tasks sync [--prune] [--dry-run] <file>")
+	}
+	path := syncCmd.Arg(0)
+
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return fmt.Errorf("reading task file: %w", err)
+	}
+	var file taskFile
+	if err := yaml.Unmarshal(data, &file); err != nil {
+		return fmt.Errorf("parsing task file: %w", err)
+	}
+
+	dataDir, err := getDataDir()
+	if err != nil {
+		return fmt.Errorf("getting home directory: %w", err)
+	}
+	database, err := db.New(filepath.Join(dataDir, "tasks.db"))
+	if err != nil {
+		return fmt.Errorf("initializing database: %w", err)
+	}
+	defer database.Close()
+
+	relaxed, _ := database.GetRelaxedWebhookURLs()
+	maxPrompt, _ := database.GetMaxPromptLength()
+	baseDir := filepath.Dir(path)
+	wanted := make(map[string]*db.Task)
+	var order []string
+	for i, def := range file.Tasks {
+		task, err := def.toTask(baseDir, relaxed, maxPrompt)
+		if err != nil {
+			return fmt.Errorf("task %d (%q): %w", i+1, def.Name, err)
+		}
+		if _, dup := wanted[task.Name]; dup {
+			return fmt.Errorf("task %q is defined more than once", task.Name)
+		}
+		wanted[task.Name] = task
+		order = append(order, task.Name)
+	}
+
+	existing, err := database.ListTasks()
+	if err != nil {
+		return fmt.Errorf("listing tasks: %w", err)
+	}
+	byName := make(map[string]*db.Task)
+	for _, task := range existing {
+		if _, dup := byName[task.Name]; dup {
+			fmt.Fprintf(os.Stderr, "Warning: several tasks are named %q; only task %d is synced\n", task.Name, byName[task.Name].ID)
+			continue
+		}
+		byName[task.Name] = task
+	}
+
+	verb := func(s string) string {
+		if *dryRun {
+			return "would " + s
+		}
+		return s
+	}
+
+	var created, updated, deleted int
+	for _, name := range order {
+		task := wanted[name]
+		current, ok := byName[name]
+		if !ok {
+			if !*dryRun {
+				if err := database.CreateTask(task); err != nil {
+					return fmt.Errorf("creating %q: %w", name, err)
+				}
+			}
+			fmt.Printf("%s %s\n", verb("create"), name)
+			created++
+			continue
+		}
+		if sameDefinition(current, task) {
+			continue
+		}
+		task.ID = current.ID
+		task.CreatedAt = current.CreatedAt
+		if !*dryRun {
+			if err := database.UpdateTask(task); err != nil {
+				return fmt.Errorf("updating %q: %w", name, err)
+			}
+			if !task.ResumeSession && current.SessionID != "" {
+				if err := database.SetTaskSessionID(task.ID, ""); err != nil {
+					return fmt.Errorf("clearing session of %q: %w", name, err)
+				}
+			}
+		}
+		fmt.Printf("%s %s\n", verb("update"), name)
+		updated++
+	}
+
+	if *prune {
+		for _, task := range existing {
+			if _, ok := wanted[task.Name]; ok {
+				continue
+			}
+			if !*dryRun {
+				if err := database.DeleteTask(task.ID); err != nil {
+					return fmt.Errorf("deleting %q: %w", task.Name, err)
+				}
+			}
+			fmt.Printf("%s %s\n", verb("delete"), task.Name)
+			deleted++
+		}
+	}
+
+	fmt.Printf("%d created, %d updated, %d deleted, %d unchanged\n",
+		created, updated, deleted, len(order)-created-updated)
+	return nil
+}
+
+// toTask validates a definition and converts it to a task ready to store
+func (def taskDefinition) toTask(baseDir string, relaxed bool, maxPrompt int) (*db.Task, error) {
+	if def.Name == "" {
+		return nil, fmt.Errorf("name is required")
+	}
+	if def.Prompt == "" && def.PromptFile == "" {
+		return nil, fmt.Errorf("one of prompt or prompt_file is required")
+	}
+	if def.Prompt != "" && def.PromptFile != "" {
+		return nil, fmt.Errorf("prompt and prompt_file are mutually exclusive")
+	}
+	if utf8.RuneCountInString(def.Prompt) > maxPrompt {
+		return nil, fmt.Errorf("prompt must be at most %d characters (max_prompt_length)", maxPrompt)
+	}
+	if def.Cron != "" {
+		if _, err := cronexpr.Parser.Parse(def.Cron); err != nil {
+			return nil, fmt.Errorf("invalid cron expression: %w", err)
+		}
+	} else if def.ScheduledAt == nil {
+		return nil, fmt.Errorf("one of cron or scheduled_at is required")
+	}
+	if def.OutputFormat == "" {
+		def.OutputFormat = db.OutputFormatText
+	}
+	if !db.ValidOutputFormat(def.OutputFormat) {
+		return nil, fmt.Errorf("invalid output_format %q", def.OutputFormat)
+	}
+	tags := db.NormalizeTags(def.Tags)
+	for _, tag := range tags {
+		if !db.ValidTag(tag) {
+			return nil, fmt.Errorf("invalid tag %q", tag)
+		}
+	}
+	if def.UsageThreshold != nil && (*def.UsageThreshold < 0 || *def.UsageThreshold > 100) {
+		return nil, fmt.Errorf("usage_threshold must be between 0 and 100")
+	}
+	if def.JitterSeconds < 0 || def.JitterSeconds > db.MaxJitterSeconds {
+		return nil, fmt.Errorf("jitter_seconds must be between 0 and %d", db.MaxJitterSeconds)
+	}
+	if def.AlertAfterSeconds < 0 || def.AlertAfterSeconds > db.MaxAlertAfterSeconds {
+		return nil, fmt.Errorf("alert_after_seconds must be between 0 and %d", db.MaxAlertAfterSeconds)
+	}
+	if def.DisableAfterFailures < 0 || def.DisableAfterFailures > db.MaxDisableAfterFailures {
+		return nil, fmt.Errorf("disable_after_failures must be between 0 and %d", db.MaxDisableAfterFailures)
+	}
+	if def.ActiveFrom != nil && def.ActiveUntil != nil && !def.ActiveUntil.After(*def.ActiveFrom) {
+		return nil, fmt.Errorf("active_until must be after active_from")
+	}
+	if def.WebhookDetail != "" && !db.ValidWebhookDetail(def.WebhookDetail) {
+		return nil, fmt.Errorf("invalid webhook_detail %q", def.WebhookDetail)
+	}
+	if def.DiscordWebhook != "" && def.DiscordWebhook != db.WebhookOptOut {
+		if err := webhook.ValidateDiscordURL(def.DiscordWebhook, relaxed); err != nil {
+			return nil, fmt.Errorf("discord_webhook %w", err)
+		}
+	}
+	if def.SlackWebhook != "" && def.SlackWebhook != db.WebhookOptOut {
+		if err := webhook.ValidateSlackURL(def.SlackWebhook, relaxed); err != nil {
+			return nil, fmt.Errorf("slack_webhook %w", err)
+		}
+	}
+
+	container := strings.TrimSpace(def.Container)
+	if container != "" {
+		if !db.ValidContainer(container) {
+			return nil, fmt.Errorf("invalid container %q", container)
+		}
+		if _, err := executor.LookupDocker(); err != nil {
+			return nil, err
+		}
+	}
+
+	workingDir := def.WorkingDir
+	if workingDir == "" {
+		workingDir = "."
+	}
+	if rest, ok := strings.CutPrefix(workingDir, "~/"); ok {
+		home, err := os.UserHomeDir()
+		if err != nil {
+			return nil, fmt.Errorf("resolving working_dir: %w", err)
+		}
+		workingDir = filepath.Join(home, rest)
+	}
+	if !filepath.IsAbs(workingDir) {
+		workingDir = filepath.Join(baseDir, workingDir)
+	}
+	workingDir, err := filepath.Abs(workingDir)
+	if err != nil {
+		return nil, fmt.Errorf("resolving working_dir: %w", err)
+	}
+	if info, err := os.Stat(workingDir); err != nil || !info.IsDir() {
+		return nil, fmt.Errorf("working_dir not found: %s", workingDir)
+	}
+
+	enabled := def.Enabled == nil || *def.Enabled
+
+	return &db.Task{
+		Name:                   def.Name,
+		Prompt:                 def.Prompt,
+		PromptFile:             def.PromptFile,
+		StdinFile:              def.StdinFile,
+		SystemPrompt:           def.SystemPrompt,
+		OutputFormat:           def.OutputFormat,
+		CronExpr:               def.Cron,
+		ScheduledAt:            def.ScheduledAt,
+		WorkingDir:             workingDir,
+		DiscordWebhook:         def.DiscordWebhook,
+		SlackWebhook:           def.SlackWebhook,
+		UsageThresholdOverride: def.UsageThreshold,
+		Tags:                   tags,
+		RunDuringQuietHours:    def.RunDuringQuietHours,
+		JitterSeconds:          def.JitterSeconds,
+		ActiveFrom:             def.ActiveFrom,
+		ActiveUntil:            def.ActiveUntil,
+		AlertAfterSeconds:      def.AlertAfterSeconds,
+		DisableAfterFailures:   def.DisableAfterFailures,
+		StripAnsi:              def.StripAnsi == nil || *def.StripAnsi,
+		IncludePreviousOutput:  def.IncludePreviousOutput,
+		TemplatePrompt:         def.TemplatePrompt,
+		SkipPermissions:        def.SkipPermissions == nil || *def.SkipPermissions,
+		AllowedTools:           strings.TrimSpace(def.AllowedTools),
+		Container:              container,
+		WebhookDetail:          def.WebhookDetail,
+		ResumeSession:          def.ResumeSession,
+		Enabled:                enabled,
+	}, nil
+}
+
+// sameDefinition reports whether two tasks agree on every field a task file controls
+func sameDefinition(a, b *db.Task) bool {
+	sameTime := func(x, y *time.Time) bool {
+		if x == nil || y == nil {
+			return x == y
+		}
+		return x.Equal(*y)
+	}
+	sameFloat := func(x, y *float64) bool {
+		if x == nil || y == nil {
+			return x == y
+		}
+		return *x == *y
+	}
+	return a.Name == b.Name &&
+		a.Prompt == b.Prompt &&
+		a.PromptFile == b.PromptFile &&
+		a.StdinFile == b.StdinFile &&
+		a.SystemPrompt == b.SystemPrompt &&
+		a.OutputFormat == b.OutputFormat &&
+		a.CronExpr == b.CronExpr &&
+		sameTime(a.ScheduledAt, b.ScheduledAt) &&
+		a.WorkingDir == b.WorkingDir &&
+		a.DiscordWebhook == b.DiscordWebhook &&
+		a.SlackWebhook == b.SlackWebhook &&
+		sameFloat(a.UsageThresholdOverride, b.UsageThresholdOverride) &&
+		slices.Equal(a.Tags, b.Tags) &&
+		a.RunDuringQuietHours == b.RunDuringQuietHours &&
+		a.JitterSeconds == b.JitterSeconds &&
+		sameTime(a.ActiveFrom, b.ActiveFrom) &&
+		sameTime(a.ActiveUntil, b.ActiveUntil) &&
+		a.AlertAfterSeconds == b.AlertAfterSeconds &&
+		a.DisableAfterFailures == b.DisableAfterFailures &&
+		a.StripAnsi == b.StripAnsi &&
+		a.IncludePreviousOutput == b.IncludePreviousOutput &&
+		a.TemplatePrompt == b.TemplatePrompt &&
+		a.SkipPermissions == b.SkipPermissions &&
+		a.AllowedTools == b.AllowedTools &&
+		a.Container == b.Container &&
+		a.WebhookDetail == b.WebhookDetail &&
+		a.ResumeSession == b.ResumeSession &&
+		a.Enabled == b.Enabled
+}
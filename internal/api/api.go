@@ -16,55 +16,125 @@ type Server struct {
 	scheduler *scheduler.Scheduler
 	executor  *executor.Executor
 	router    chi.Router
+	noUsage   bool // Set by DisableUsageCheck
 }
 
-// NewServer creates a new API server
+// NewServer creates a new API server. API runs share the scheduler's executor,
+// so they wait in the same queue as cron runs.
 func NewServer(database *db.DB, sched *scheduler.Scheduler) *Server {
 	s := &Server{
 		db:        database,
 		scheduler: sched,
-		executor:  executor.New(database),
 		router:    chi.NewRouter(),
 	}
+	if sched != nil {
+		s.executor = sched.Executor()
+	} else {
+		s.executor = executor.New(database)
+	}
 	s.setupRoutes()
 	return s
 }
 
+// DisableUsageCheck turns off the usage endpoint and threshold enforcement for
+// API-triggered runs regardless of the usage_check_enabled setting
+func (s *Server) DisableUsageCheck() {
+	s.noUsage = true
+	s.executor.DisableUsageCheck()
+}
+
+// Reload re-reads usage credentials and re-syncs the scheduler's tasks
+func (s *Server) Reload() {
+	s.executor.ReloadUsageClient()
+	if s.scheduler != nil {
+		s.scheduler.Reload()
+	}
+}
+
+// usageCheckEnabled reports whether usage is fetched for this server
+func (s *Server) usageCheckEnabled() bool {
+	if s.noUsage {
+		return false
+	}
+	enabled, _ := s.db.GetUsageCheckEnabled()
+	return enabled
+}
+
+// requestLoggingEnabled reports whether requests should be logged
+func (s *Server) requestLoggingEnabled() bool {
+	enabled, _ := s.db.GetAPIRequestLogging()
+	return enabled
+}
+
+// allowedOrigins returns the configured CORS origins
+func (s *Server) allowedOrigins() []string {
+	val, _ := s.db.GetAPIAllowedOrigins()
+	return parseOrigins(val)
+}
+
 func (s *Server) setupRoutes() {
 	r := s.router
 
 	// Global middleware
 	r.Use(middleware.RequestID)
 	r.Use(middleware.RealIP)
-	r.Use(middleware.Logger)
+	r.Use(RequestLogger(s.requestLoggingEnabled))
 	r.Use(middleware.Recoverer)
-	r.Use(CORS)
+	r.Use(CORS(s.allowedOrigins))
 
 	// API routes
 	r.Route("/api/v1", func(r chi.Router) {
 		// Health check
 		r.Get("/health", s.HealthCheck)
 
+		// Build information
+		r.Get("/version", s.GetVersion)
+
+		// API specification
+		r.Get("/openapi.json", s.GetOpenAPISpec)
+
 		// Tasks
 		r.Route("/tasks", func(r chi.Router) {
 			r.Get("/", s.ListTasks)
 			r.Post("/", s.CreateTask)
 			r.Get("/{id}", s.GetTask)
 			r.Put("/{id}", s.UpdateTask)
+			r.Patch("/{id}", s.PatchTask)
 			r.Delete("/{id}", s.DeleteTask)
 			r.Post("/{id}/toggle", s.ToggleTask)
 			r.Post("/{id}/run", s.RunTask)
 			r.Get("/{id}/runs", s.GetTaskRuns)
+			r.Get("/{id}/stats", s.GetTaskStats)
+			r.Get("/{id}/cost-estimate", s.GetTaskCostEstimate)
+			r.Post("/{id}/report", s.SendTaskReport)
 			r.Get("/{id}/runs/latest", s.GetLatestTaskRun)
+			r.Get("/{id}/runs/{runId}", s.GetTaskRun)
+			r.Patch("/{id}/runs/{runId}", s.PatchTaskRun)
+			r.Get("/{id}/runs/{runId}/diff", s.GetTaskRunDiff)
+			r.Get("/{id}/runs/{runId}/output/tail", s.GetTaskRunOutputTail)
 		})
 
+		// Runs across all tasks
+		r.Get("/runs", s.GetRecentRuns)
+
+		// Cron
+		r.Post("/cron/preview", s.PreviewCron)
+
+		// Scheduler
+		r.Get("/scheduler/status", s.GetSchedulerStatus)
+
 		// Settings
 		r.Get("/settings", s.GetSettings)
 		r.Put("/settings", s.UpdateSettings)
 
 		// Usage
 		r.Get("/usage", s.GetUsage)
+		r.Get("/usage/history", s.GetUsageHistory)
+		r.Get("/usage/status", s.GetUsageStatus)
 	})
+
+	// Web dashboard
+	r.Handle("/*", webHandler())
 }
 
 // Router returns the chi router for use with http.Server
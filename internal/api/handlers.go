@@ -1,27 +1,79 @@
 package api
 
 import (
+	"crypto/sha256"
+	"database/sql"
+	"encoding/hex"
 	"encoding/json"
+	"errors"
+	"fmt"
+	"math"
 	"net/http"
+	"net/url"
+	"os"
+	"path/filepath"
+	"runtime"
 	"strconv"
+	"strings"
 	"time"
+	"unicode/utf8"
 
 	"github.com/go-chi/chi/v5"
+	"github.com/kylemclaren/claude-tasks/internal/cronexpr"
 	"github.com/kylemclaren/claude-tasks/internal/db"
+	"github.com/kylemclaren/claude-tasks/internal/diff"
+	"github.com/kylemclaren/claude-tasks/internal/executor"
 	"github.com/kylemclaren/claude-tasks/internal/usage"
 	"github.com/kylemclaren/claude-tasks/internal/version"
-	"github.com/robfig/cron/v3"
+	"github.com/kylemclaren/claude-tasks/internal/webhook"
 )
 
 // HealthCheck handles GET /api/v1/health
+// Returns 503 if the database is unreachable or the scheduler isn't running.
 func (s *Server) HealthCheck(w http.ResponseWriter, r *http.Request) {
-	s.jsonResponse(w, http.StatusOK, HealthResponse{
-		Status:  "ok",
-		Version: version.Version,
-	})
+	resp := HealthResponse{
+		Status:    "ok",
+		Version:   version.Version,
+		Database:  ComponentHealth{Status: "ok"},
+		Scheduler: SchedulerHealthResponse{Status: "ok"},
+		Claude:    ComponentHealth{Status: "ok"},
+	}
+	healthy := true
+
+	if _, err := executor.LookupClaude(s.db); err != nil {
+		resp.Claude = ComponentHealth{Status: "error", Error: err.Error()}
+	}
+
+	if err := s.db.Ping(); err != nil {
+		resp.Database = ComponentHealth{Status: "error", Error: err.Error()}
+		healthy = false
+	}
+
+	if s.scheduler == nil {
+		resp.Scheduler.Status = "error"
+		resp.Scheduler.Error = "scheduler not configured"
+		healthy = false
+	} else {
+		status := s.scheduler.Status()
+		resp.Scheduler.Running = status.Running
+		resp.Scheduler.Jobs = len(status.Jobs)
+		if !status.Running {
+			resp.Scheduler.Status = "error"
+			resp.Scheduler.Error = "scheduler not running"
+			healthy = false
+		}
+	}
+
+	if !healthy {
+		resp.Status = "unhealthy"
+		s.jsonResponse(w, http.StatusServiceUnavailable, resp)
+		return
+	}
+
+	s.jsonResponse(w, http.StatusOK, resp)
 }
 
-// ListTasks handles GET /api/v1/tasks
+// ListTasks handles GET /api/v1/tasks, optionally filtered with ?tag=
 func (s *Server) ListTasks(w http.ResponseWriter, r *http.Request) {
 	tasks, err := s.db.ListTasks()
 	if err != nil {
@@ -29,8 +81,19 @@ func (s *Server) ListTasks(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
+	if tag := strings.TrimSpace(r.URL.Query().Get("tag")); tag != "" {
+		filtered := tasks[:0]
+		for _, task := range tasks {
+			if task.HasTag(tag) {
+				filtered = append(filtered, task)
+			}
+		}
+		tasks = filtered
+	}
+
 	// Get last run statuses for all tasks
 	statuses, _ := s.db.GetLastRunStatuses()
+	counts, _ := s.db.GetRunCounts()
 
 	response := TaskListResponse{
 		Tasks: make([]TaskResponse, len(tasks)),
@@ -38,17 +101,56 @@ func (s *Server) ListTasks(w http.ResponseWriter, r *http.Request) {
 	}
 
 	for i, task := range tasks {
-		response.Tasks[i] = s.taskToResponse(task, statuses[task.ID])
+		response.Tasks[i] = s.taskToResponse(task, statuses[task.ID], counts[task.ID])
 	}
 
-	s.jsonResponse(w, http.StatusOK, response)
+	// Let polling clients skip the body when nothing changed
+	data, err := json.Marshal(response)
+	if err != nil {
+		s.errorResponse(w, http.StatusInternalServerError, "Failed to encode tasks", err)
+		return
+	}
+	sum := sha256.Sum256(data)
+	etag := `"` + hex.EncodeToString(sum[:16]) + `"`
+	w.Header().Set("ETag", etag)
+	if etagMatches(r.Header.Get("If-None-Match"), etag) {
+		w.WriteHeader(http.StatusNotModified)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusOK)
+	_, _ = w.Write(append(data, '\n'))
+}
+
+// etagMatches reports whether an If-None-Match header value matches etag.
+// Weak validators compare equal to their strong form, as GET allows.
+func etagMatches(header, etag string) bool {
+	for _, candidate := range strings.Split(header, ",") {
+		candidate = strings.TrimPrefix(strings.TrimSpace(candidate), "W/")
+		if candidate == "*" || candidate == etag {
+			return true
+		}
+	}
+	return false
+}
+
+// GetVersion handles GET /api/v1/version
+func (s *Server) GetVersion(w http.ResponseWriter, r *http.Request) {
+	s.jsonResponse(w, http.StatusOK, VersionResponse{
+		Version:   version.Version,
+		Commit:    version.Commit,
+		BuildDate: version.BuildDate,
+		GoVersion: runtime.Version(),
+		OS:        runtime.GOOS,
+		Arch:      runtime.GOARCH,
+	})
 }
 
 // CreateTask handles POST /api/v1/tasks
 func (s *Server) CreateTask(w http.ResponseWriter, r *http.Request) {
 	var req TaskRequest
-	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
-		s.errorResponse(w, http.StatusBadRequest, "Invalid request body", err)
+	if !s.decodeJSON(w, r, &req) {
 		return
 	}
 
@@ -58,14 +160,39 @@ func (s *Server) CreateTask(w http.ResponseWriter, r *http.Request) {
 	}
 
 	task := &db.Task{
-		Name:           req.Name,
-		Prompt:         req.Prompt,
-		CronExpr:       req.CronExpr,
-		WorkingDir:     req.WorkingDir,
-		DiscordWebhook: req.DiscordWebhook,
-		SlackWebhook:   req.SlackWebhook,
-		Enabled:        req.Enabled,
+		Name:                   req.Name,
+		Prompt:                 req.Prompt,
+		PromptFile:             req.PromptFile,
+		StdinFile:              req.StdinFile,
+		SystemPrompt:           req.SystemPrompt,
+		OutputFormat:           req.OutputFormat,
+		CronExpr:               req.CronExpr,
+		WorkingDir:             req.WorkingDir,
+		DiscordWebhook:         req.DiscordWebhook,
+		SlackWebhook:           req.SlackWebhook,
+		UsageThresholdOverride: req.UsageThresholdOverride,
+		Tags:                   req.Tags,
+		RunDuringQuietHours:    req.RunDuringQuietHours,
+		JitterSeconds:          req.JitterSeconds,
+		AlertAfterSeconds:      req.AlertAfterSeconds,
+		DisableAfterFailures:   req.DisableAfterFailures,
+		StripAnsi:              req.StripAnsi == nil || *req.StripAnsi,
+		IncludePreviousOutput:  req.IncludePreviousOutput,
+		TemplatePrompt:         req.TemplatePrompt,
+		SkipPermissions:        req.SkipPermissions == nil || *req.SkipPermissions,
+		AllowedTools:           strings.TrimSpace(req.AllowedTools),
+		Container:              req.Container,
+		WebhookDetail:          req.WebhookDetail,
+		ResumeSession:          req.ResumeSession,
+		Enabled:                req.Enabled,
+	}
+
+	activeFrom, activeUntil, err := parseActiveWindow(&req)
+	if err != nil {
+		s.errorResponse(w, http.StatusBadRequest, err.Error(), nil)
+		return
 	}
+	task.ActiveFrom, task.ActiveUntil = activeFrom, activeUntil
 
 	// Parse scheduled_at for one-off tasks
 	if req.ScheduledAt != nil && *req.ScheduledAt != "" {
@@ -86,8 +213,23 @@ func (s *Server) CreateTask(w http.ResponseWriter, r *http.Request) {
 	if task.Enabled && s.scheduler != nil {
 		_ = s.scheduler.AddTask(task)
 	}
+	s.triggerSync()
+
+	resp := s.taskToResponse(task, "", db.RunCounts{})
+	resp.Warning = runWarning(s.db, task)
+	s.jsonResponse(w, http.StatusCreated, resp)
+}
 
-	s.jsonResponse(w, http.StatusCreated, s.taskToResponse(task, ""))
+// runWarning explains why a just-saved task would fail to start, matching the
+// TUI's notice on save; the task is saved either way
+func runWarning(database *db.DB, task *db.Task) string {
+	if task.Container != "" {
+		return "" // The CLI comes from the image
+	}
+	if _, err := executor.LookupClaude(database); err != nil {
+		return err.Error()
+	}
+	return ""
 }
 
 // GetTask handles GET /api/v1/tasks/{id}
@@ -110,8 +252,9 @@ func (s *Server) GetTask(w http.ResponseWriter, r *http.Request) {
 	if lastRun != nil {
 		status = lastRun.Status
 	}
+	counts, _ := s.db.GetTaskRunCounts(id)
 
-	s.jsonResponse(w, http.StatusOK, s.taskToResponse(task, status))
+	s.jsonResponse(w, http.StatusOK, s.taskToResponse(task, status, counts))
 }
 
 // UpdateTask handles PUT /api/v1/tasks/{id}
@@ -129,12 +272,44 @@ func (s *Server) UpdateTask(w http.ResponseWriter, r *http.Request) {
 	}
 
 	var req TaskRequest
-	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
-		s.errorResponse(w, http.StatusBadRequest, "Invalid request body", err)
+	if !s.decodeJSON(w, r, &req) {
 		return
 	}
 
-	if err := s.validateTaskRequest(&req); err != nil {
+	s.saveTask(w, task, &req, false)
+}
+
+// PatchTask handles PATCH /api/v1/tasks/{id}
+// Only fields present in the body change; the rest keep their stored values.
+func (s *Server) PatchTask(w http.ResponseWriter, r *http.Request) {
+	id, err := strconv.ParseInt(chi.URLParam(r, "id"), 10, 64)
+	if err != nil {
+		s.errorResponse(w, http.StatusBadRequest, "Invalid task ID", err)
+		return
+	}
+
+	task, err := s.db.GetTask(id)
+	if err != nil {
+		s.errorResponse(w, http.StatusNotFound, "Task not found", err)
+		return
+	}
+
+	var patch TaskPatchRequest
+	if !s.decodeJSON(w, r, &patch) {
+		return
+	}
+
+	req := TaskToRequest(task)
+	applyTaskPatch(&req, &patch)
+	s.saveTask(w, task, &req, true)
+}
+
+// saveTask validates req, replaces task's fields with it, and reschedules the task.
+// With ifUnchanged it answers 409 instead of saving when the task was changed
+// since it was read, so a merged PATCH never overwrites someone else's edit.
+func (s *Server) saveTask(w http.ResponseWriter, task *db.Task, req *TaskRequest, ifUnchanged bool) {
+	readUpdatedAt := task.UpdatedAt
+	if err := s.validateTaskRequest(req); err != nil {
 		s.errorResponse(w, http.StatusBadRequest, err.Error(), nil)
 		return
 	}
@@ -142,12 +317,40 @@ func (s *Server) UpdateTask(w http.ResponseWriter, r *http.Request) {
 	// Update task fields
 	task.Name = req.Name
 	task.Prompt = req.Prompt
+	task.PromptFile = req.PromptFile
+	task.StdinFile = req.StdinFile
+	task.SystemPrompt = req.SystemPrompt
+	task.OutputFormat = req.OutputFormat
 	task.CronExpr = req.CronExpr
 	task.WorkingDir = req.WorkingDir
 	task.DiscordWebhook = req.DiscordWebhook
 	task.SlackWebhook = req.SlackWebhook
+	task.UsageThresholdOverride = req.UsageThresholdOverride
+	task.Tags = req.Tags
+	task.RunDuringQuietHours = req.RunDuringQuietHours
+	task.JitterSeconds = req.JitterSeconds
+	task.AlertAfterSeconds = req.AlertAfterSeconds
+	task.DisableAfterFailures = req.DisableAfterFailures
+	task.StripAnsi = req.StripAnsi == nil || *req.StripAnsi
+	task.IncludePreviousOutput = req.IncludePreviousOutput
+	task.TemplatePrompt = req.TemplatePrompt
+	task.SkipPermissions = req.SkipPermissions == nil || *req.SkipPermissions
+	task.AllowedTools = strings.TrimSpace(req.AllowedTools)
+	task.Container = req.Container
+	task.WebhookDetail = req.WebhookDetail
+	task.ResumeSession = req.ResumeSession
+	if !task.ResumeSession {
+		task.SessionID = "" // Turning resume off forgets the session
+	}
 	task.Enabled = req.Enabled
 
+	activeFrom, activeUntil, err := parseActiveWindow(req)
+	if err != nil {
+		s.errorResponse(w, http.StatusBadRequest, err.Error(), nil)
+		return
+	}
+	task.ActiveFrom, task.ActiveUntil = activeFrom, activeUntil
+
 	// Parse scheduled_at for one-off tasks
 	if req.ScheduledAt != nil && *req.ScheduledAt != "" {
 		scheduledAt, err := time.Parse(time.RFC3339, *req.ScheduledAt)
@@ -160,17 +363,36 @@ func (s *Server) UpdateTask(w http.ResponseWriter, r *http.Request) {
 		task.ScheduledAt = nil
 	}
 
-	if err := s.db.UpdateTask(task); err != nil {
+	if ifUnchanged {
+		err = s.db.UpdateTaskIfUnchanged(task, readUpdatedAt)
+	} else {
+		err = s.db.UpdateTask(task)
+	}
+	if errors.Is(err, db.ErrTaskModified) {
+		s.errorResponse(w, http.StatusConflict, "Task was modified by another request; fetch it and retry", nil)
+		return
+	}
+	if err != nil {
 		s.errorResponse(w, http.StatusInternalServerError, "Failed to update task", err)
 		return
 	}
+	if !task.ResumeSession {
+		if err := s.db.SetTaskSessionID(task.ID, ""); err != nil {
+			s.errorResponse(w, http.StatusInternalServerError, "Failed to clear session", err)
+			return
+		}
+	}
 
 	// Update scheduler
 	if s.scheduler != nil {
 		_ = s.scheduler.UpdateTask(task)
 	}
+	s.triggerSync()
 
-	s.jsonResponse(w, http.StatusOK, s.taskToResponse(task, ""))
+	counts, _ := s.db.GetTaskRunCounts(task.ID)
+	resp := s.taskToResponse(task, "", counts)
+	resp.Warning = runWarning(s.db, task)
+	s.jsonResponse(w, http.StatusOK, resp)
 }
 
 // DeleteTask handles DELETE /api/v1/tasks/{id}
@@ -197,6 +419,7 @@ func (s *Server) DeleteTask(w http.ResponseWriter, r *http.Request) {
 		s.errorResponse(w, http.StatusInternalServerError, "Failed to delete task", err)
 		return
 	}
+	s.triggerSync()
 
 	s.jsonResponse(w, http.StatusOK, SuccessResponse{
 		Success: true,
@@ -228,8 +451,18 @@ func (s *Server) ToggleTask(w http.ResponseWriter, r *http.Request) {
 	if s.scheduler != nil {
 		_ = s.scheduler.UpdateTask(task)
 	}
+	s.triggerSync()
+
+	counts, _ := s.db.GetTaskRunCounts(task.ID)
+	s.jsonResponse(w, http.StatusOK, s.taskToResponse(task, "", counts))
+}
 
-	s.jsonResponse(w, http.StatusOK, s.taskToResponse(task, ""))
+// triggerSync asks the scheduler to reconcile with the DB now, so edits made
+// by other processes since the last sync are picked up along with this one
+func (s *Server) triggerSync() {
+	if s.scheduler != nil {
+		s.scheduler.TriggerSync()
+	}
 }
 
 // RunTask handles POST /api/v1/tasks/{id}/run
@@ -246,8 +479,12 @@ func (s *Server) RunTask(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
-	// Execute asynchronously
-	go s.executor.ExecuteAsync(task)
+	// Queued behind any runs waiting for a free slot
+	if s.scheduler != nil {
+		s.scheduler.Execute(task, db.TriggerAPI)
+	} else {
+		s.executor.ExecuteAsync(task, db.TriggerAPI)
+	}
 
 	s.jsonResponse(w, http.StatusAccepted, SuccessResponse{
 		Success: true,
@@ -296,141 +533,1298 @@ func (s *Server) GetTaskRuns(w http.ResponseWriter, r *http.Request) {
 	s.jsonResponse(w, http.StatusOK, response)
 }
 
-// GetLatestTaskRun handles GET /api/v1/tasks/{id}/runs/latest
-func (s *Server) GetLatestTaskRun(w http.ResponseWriter, r *http.Request) {
+// GetRecentRuns handles GET /api/v1/runs?limit=N&status=S, the newest runs of every task
+func (s *Server) GetRecentRuns(w http.ResponseWriter, r *http.Request) {
+	limit := 20
+	if limitStr := r.URL.Query().Get("limit"); limitStr != "" {
+		if l, err := strconv.Atoi(limitStr); err == nil && l > 0 {
+			limit = l
+		}
+	}
+
+	status := r.URL.Query().Get("status")
+	switch db.RunStatus(status) {
+	case "", db.RunStatusPending, db.RunStatusRunning, db.RunStatusCompleted, db.RunStatusFailed, db.RunStatusSkipped:
+	default:
+		s.errorResponse(w, http.StatusBadRequest, "Invalid status (use pending, running, completed, failed, or skipped)", nil)
+		return
+	}
+
+	runs, err := s.db.GetRecentRuns(limit, status)
+	if err != nil {
+		s.errorResponse(w, http.StatusInternalServerError, "Failed to fetch runs", err)
+		return
+	}
+
+	response := RecentRunsResponse{
+		Runs:  make([]RecentRunResponse, len(runs)),
+		Total: len(runs),
+	}
+	for i, run := range runs {
+		response.Runs[i] = RecentRunResponse{
+			TaskRunResponse: s.taskRunToResponse(run.TaskRun),
+			TaskName:        run.TaskName,
+		}
+	}
+
+	s.jsonResponse(w, http.StatusOK, response)
+}
+
+// GetTaskStats handles GET /api/v1/tasks/{id}/stats?limit=N (default 50 most recent runs)
+func (s *Server) GetTaskStats(w http.ResponseWriter, r *http.Request) {
 	id, err := strconv.ParseInt(chi.URLParam(r, "id"), 10, 64)
 	if err != nil {
 		s.errorResponse(w, http.StatusBadRequest, "Invalid task ID", err)
 		return
 	}
 
-	run, err := s.db.GetLatestTaskRun(id)
+	if _, err := s.db.GetTask(id); err != nil {
+		s.errorResponse(w, http.StatusNotFound, "Task not found", err)
+		return
+	}
+
+	limit := 50
+	if limitStr := r.URL.Query().Get("limit"); limitStr != "" {
+		if l, err := strconv.Atoi(limitStr); err == nil && l > 0 {
+			limit = l
+		}
+	}
+
+	stats, err := s.db.GetTaskRunStats(id, limit)
 	if err != nil {
-		s.errorResponse(w, http.StatusNotFound, "No runs found", err)
+		s.errorResponse(w, http.StatusInternalServerError, "Failed to compute task stats", err)
 		return
 	}
 
-	s.jsonResponse(w, http.StatusOK, s.taskRunToResponse(run))
+	resp := TaskStatsResponse{
+		TaskID:      id,
+		TotalRuns:   stats.TotalRuns,
+		SampleSize:  stats.SampleSize,
+		Completed:   stats.Completed,
+		Failed:      stats.Failed,
+		Skipped:     stats.Skipped,
+		SuccessRate: stats.SuccessRate,
+	}
+	// Durations are omitted until at least one run has finished
+	if stats.MaxDuration > 0 {
+		ms := func(d time.Duration) *int64 {
+			v := d.Milliseconds()
+			return &v
+		}
+		resp.MinDurationMs = ms(stats.MinDuration)
+		resp.MaxDurationMs = ms(stats.MaxDuration)
+		resp.AvgDurationMs = ms(stats.AvgDuration)
+		resp.P95DurationMs = ms(stats.P95Duration)
+	}
+
+	s.jsonResponse(w, http.StatusOK, resp)
 }
 
-// GetSettings handles GET /api/v1/settings
-func (s *Server) GetSettings(w http.ResponseWriter, r *http.Request) {
-	threshold, _ := s.db.GetUsageThreshold()
+// Limits for POST /api/v1/tasks/{id}/report
+const (
+	defaultReportRuns = 20
+	maxReportRuns     = 1000
+)
 
-	s.jsonResponse(w, http.StatusOK, SettingsResponse{
-		UsageThreshold: threshold,
-	})
+// SendTaskReport handles POST /api/v1/tasks/{id}/report?runs=N
+// Sends a digest of the task's last N runs to its webhooks.
+func (s *Server) SendTaskReport(w http.ResponseWriter, r *http.Request) {
+	id, err := strconv.ParseInt(chi.URLParam(r, "id"), 10, 64)
+	if err != nil {
+		s.errorResponse(w, http.StatusBadRequest, "Invalid task ID", err)
+		return
+	}
+
+	task, err := s.db.GetTask(id)
+	if err != nil {
+		s.errorResponse(w, http.StatusNotFound, "Task not found", err)
+		return
+	}
+
+	runs := defaultReportRuns
+	if runsStr := r.URL.Query().Get("runs"); runsStr != "" {
+		n, err := strconv.Atoi(runsStr)
+		if err != nil || n < 1 || n > maxReportRuns {
+			s.errorResponse(w, http.StatusBadRequest, fmt.Sprintf("runs must be between 1 and %d", maxReportRuns), err)
+			return
+		}
+		runs = n
+	}
+
+	digest, err := s.executor.Report(task, runs)
+	if errors.Is(err, executor.ErrNoWebhooks) {
+		s.errorResponse(w, http.StatusBadRequest, "Task has no Discord or Slack webhook", err)
+		return
+	}
+	if digest == nil {
+		s.errorResponse(w, http.StatusInternalServerError, "Failed to build report", err)
+		return
+	}
+	if err != nil {
+		s.errorResponse(w, http.StatusBadGateway, "Failed to send report", err)
+		return
+	}
+
+	stats := digest.Stats
+	resp := TaskReportResponse{
+		TaskID:      id,
+		TotalRuns:   stats.TotalRuns,
+		SampleSize:  stats.SampleSize,
+		Completed:   stats.Completed,
+		Failed:      stats.Failed,
+		Skipped:     stats.Skipped,
+		SuccessRate: stats.SuccessRate,
+	}
+	if run := digest.LastFailure; run != nil {
+		resp.LastErrorRunID = &run.ID
+		resp.LastError = run.Error
+	}
+	s.jsonResponse(w, http.StatusOK, resp)
 }
 
-// UpdateSettings handles PUT /api/v1/settings
-func (s *Server) UpdateSettings(w http.ResponseWriter, r *http.Request) {
-	var req SettingsRequest
-	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
-		s.errorResponse(w, http.StatusBadRequest, "Invalid request body", err)
+// GetTaskCostEstimate handles GET /api/v1/tasks/{id}/cost-estimate
+func (s *Server) GetTaskCostEstimate(w http.ResponseWriter, r *http.Request) {
+	id, err := strconv.ParseInt(chi.URLParam(r, "id"), 10, 64)
+	if err != nil {
+		s.errorResponse(w, http.StatusBadRequest, "Invalid task ID", err)
 		return
 	}
 
-	// Validate threshold
-	if req.UsageThreshold < 0 || req.UsageThreshold > 100 {
-		s.errorResponse(w, http.StatusBadRequest, "Usage threshold must be between 0 and 100", nil)
+	task, err := s.db.GetTask(id)
+	if err != nil {
+		s.errorResponse(w, http.StatusNotFound, "Task not found", err)
 		return
 	}
 
-	if err := s.db.SetUsageThreshold(req.UsageThreshold); err != nil {
-		s.errorResponse(w, http.StatusInternalServerError, "Failed to update settings", err)
+	limit := 20
+	if limitStr := r.URL.Query().Get("limit"); limitStr != "" {
+		if l, err := strconv.Atoi(limitStr); err == nil && l > 0 {
+			limit = l
+		}
+	}
+
+	est, err := s.db.GetTaskCostEstimate(id, limit)
+	if err != nil {
+		s.errorResponse(w, http.StatusInternalServerError, "Failed to estimate task cost", err)
 		return
 	}
 
-	s.jsonResponse(w, http.StatusOK, SettingsResponse(req))
+	resp := TaskCostEstimateResponse{
+		TaskID:          id,
+		SampleSize:      est.SampleSize,
+		AvgCostUSD:      est.AvgCostUSD,
+		AvgInputTokens:  int64(math.Round(est.AvgInputTokens)),
+		AvgOutputTokens: int64(math.Round(est.AvgOutputTokens)),
+	}
+	if !task.IsOneOff() {
+		if runs, err := cronexpr.RunsPerDay(task.CronExpr, time.Now()); err == nil {
+			daily := est.AvgCostUSD * float64(runs)
+			resp.RunsPerDay = &runs
+			resp.EstimatedDailyCostUSD = &daily
+		}
+	}
+
+	s.jsonResponse(w, http.StatusOK, resp)
 }
 
-// GetUsage handles GET /api/v1/usage
-func (s *Server) GetUsage(w http.ResponseWriter, r *http.Request) {
-	client, err := usage.NewClient()
+// GetLatestTaskRun handles GET /api/v1/tasks/{id}/runs/latest
+func (s *Server) GetLatestTaskRun(w http.ResponseWriter, r *http.Request) {
+	id, err := strconv.ParseInt(chi.URLParam(r, "id"), 10, 64)
 	if err != nil {
-		s.errorResponse(w, http.StatusInternalServerError, "Usage client not available", err)
+		s.errorResponse(w, http.StatusBadRequest, "Invalid task ID", err)
 		return
 	}
 
-	data, err := client.Fetch()
+	run, err := s.db.GetLatestTaskRun(id)
 	if err != nil {
-		s.errorResponse(w, http.StatusInternalServerError, "Failed to fetch usage", err)
+		s.errorResponse(w, http.StatusNotFound, "No runs found", err)
 		return
 	}
+	_ = s.db.LoadRunOutput(run) // Falls back to the stored preview
 
-	s.jsonResponse(w, http.StatusOK, UsageResponse{
-		FiveHour: UsageBucketResponse{
-			Utilization: data.FiveHour.Utilization,
-			ResetsAt:    data.FiveHour.ResetsAt,
-		},
-		SevenDay: UsageBucketResponse{
-			Utilization: data.SevenDay.Utilization,
-			ResetsAt:    data.SevenDay.ResetsAt,
-		},
-	})
+	s.jsonResponse(w, http.StatusOK, s.taskRunToResponse(run))
 }
 
-// Helper functions
+// GetTaskRun handles GET /api/v1/tasks/{id}/runs/{runId}
+func (s *Server) GetTaskRun(w http.ResponseWriter, r *http.Request) {
+	id, err := strconv.ParseInt(chi.URLParam(r, "id"), 10, 64)
+	if err != nil {
+		s.errorResponse(w, http.StatusBadRequest, "Invalid task ID", err)
+		return
+	}
 
-func (s *Server) taskToResponse(task *db.Task, status db.RunStatus) TaskResponse {
-	resp := TaskResponse{
-		ID:             task.ID,
-		Name:           task.Name,
-		Prompt:         task.Prompt,
-		CronExpr:       task.CronExpr,
-		ScheduledAt:    task.ScheduledAt,
-		IsOneOff:       task.IsOneOff(),
-		WorkingDir:     task.WorkingDir,
-		DiscordWebhook: task.DiscordWebhook,
-		SlackWebhook:   task.SlackWebhook,
-		Enabled:        task.Enabled,
-		CreatedAt:      task.CreatedAt,
-		UpdatedAt:      task.UpdatedAt,
-		LastRunAt:      task.LastRunAt,
-		NextRunAt:      task.NextRunAt,
+	runID, err := strconv.ParseInt(chi.URLParam(r, "runId"), 10, 64)
+	if err != nil {
+		s.errorResponse(w, http.StatusBadRequest, "Invalid run ID", err)
+		return
 	}
-	if status != "" {
-		resp.LastRunStatus = string(status)
+
+	run, err := s.db.GetTaskRun(runID)
+	if err != nil || run.TaskID != id {
+		s.errorResponse(w, http.StatusNotFound, "Run not found", err)
+		return
 	}
-	return resp
+	_ = s.db.LoadRunOutput(run) // Falls back to the stored preview
+
+	s.jsonResponse(w, http.StatusOK, s.taskRunToResponse(run))
 }
 
-func (s *Server) taskRunToResponse(run *db.TaskRun) TaskRunResponse {
-	resp := TaskRunResponse{
-		ID:        run.ID,
-		TaskID:    run.TaskID,
-		StartedAt: run.StartedAt,
-		EndedAt:   run.EndedAt,
-		Status:    string(run.Status),
-		Output:    run.Output,
-		Error:     run.Error,
+// PatchTaskRun handles PATCH /api/v1/tasks/{id}/runs/{runId}
+func (s *Server) PatchTaskRun(w http.ResponseWriter, r *http.Request) {
+	id, err := strconv.ParseInt(chi.URLParam(r, "id"), 10, 64)
+	if err != nil {
+		s.errorResponse(w, http.StatusBadRequest, "Invalid task ID", err)
+		return
 	}
-	if run.EndedAt != nil {
-		durationMs := run.EndedAt.Sub(run.StartedAt).Milliseconds()
-		resp.DurationMs = &durationMs
+
+	runID, err := strconv.ParseInt(chi.URLParam(r, "runId"), 10, 64)
+	if err != nil {
+		s.errorResponse(w, http.StatusBadRequest, "Invalid run ID", err)
+		return
 	}
-	return resp
-}
 
-func (s *Server) validateTaskRequest(req *TaskRequest) error {
-	if req.Name == "" {
-		return errEmptyName
+	run, err := s.db.GetTaskRun(runID)
+	if err != nil || run.TaskID != id {
+		s.errorResponse(w, http.StatusNotFound, "Run not found", err)
+		return
 	}
-	if req.Prompt == "" {
-		return errEmptyPrompt
+
+	var patch RunPatchRequest
+	if !s.decodeJSON(w, r, &patch) {
+		return
 	}
-	// CronExpr is empty for one-off tasks, non-empty for recurring
-	if req.CronExpr != "" {
-		// Validate cron expression if provided
-		parser := cron.NewParser(cron.Second | cron.Minute | cron.Hour | cron.Dom | cron.Month | cron.Dow)
-		if _, err := parser.Parse(req.CronExpr); err != nil {
-			return errInvalidCron
+	if patch.Notes != nil {
+		notes := strings.TrimSpace(*patch.Notes)
+		if len(notes) > db.MaxRunNotesLength {
+			s.errorResponse(w, http.StatusBadRequest, fmt.Sprintf("Notes must be at most %d bytes", db.MaxRunNotesLength), nil)
+			return
 		}
+		if err := s.db.SetTaskRunNotes(runID, notes); err != nil {
+			s.errorResponse(w, http.StatusInternalServerError, "Failed to update run", err)
+			return
+		}
+		run.Notes = notes
 	}
-	if req.WorkingDir == "" {
-		req.WorkingDir = "."
-	}
-	return nil
+	_ = s.db.LoadRunOutput(run) // Falls back to the stored preview
+
+	s.jsonResponse(w, http.StatusOK, s.taskRunToResponse(run))
+}
+
+// Limits for GET /api/v1/tasks/{id}/runs/{runId}/output/tail
+const (
+	defaultTailLines = 50
+	maxTailLines     = 10000
+	maxTailBytes     = 1 << 20
+)
+
+// GetTaskRunOutputTail handles GET /api/v1/tasks/{id}/runs/{runId}/output/tail?lines=N or ?bytes=N
+func (s *Server) GetTaskRunOutputTail(w http.ResponseWriter, r *http.Request) {
+	id, err := strconv.ParseInt(chi.URLParam(r, "id"), 10, 64)
+	if err != nil {
+		s.errorResponse(w, http.StatusBadRequest, "Invalid task ID", err)
+		return
+	}
+
+	runID, err := strconv.ParseInt(chi.URLParam(r, "runId"), 10, 64)
+	if err != nil {
+		s.errorResponse(w, http.StatusBadRequest, "Invalid run ID", err)
+		return
+	}
+
+	query := r.URL.Query()
+	if query.Get("lines") != "" && query.Get("bytes") != "" {
+		s.errorResponse(w, http.StatusBadRequest, "Use either lines or bytes, not both", nil)
+		return
+	}
+	lines, maxBytes := defaultTailLines, 0
+	if bytesStr := query.Get("bytes"); bytesStr != "" {
+		n, err := strconv.Atoi(bytesStr)
+		if err != nil || n < 1 || n > maxTailBytes {
+			s.errorResponse(w, http.StatusBadRequest, fmt.Sprintf("bytes must be between 1 and %d", maxTailBytes), err)
+			return
+		}
+		lines, maxBytes = 0, n
+	}
+	if linesStr := query.Get("lines"); linesStr != "" {
+		n, err := strconv.Atoi(linesStr)
+		if err != nil || n < 1 || n > maxTailLines {
+			s.errorResponse(w, http.StatusBadRequest, fmt.Sprintf("lines must be between 1 and %d", maxTailLines), err)
+			return
+		}
+		lines = n
+	}
+
+	tail, truncated, err := s.db.GetTaskRunOutputTail(id, runID, lines, maxBytes)
+	if errors.Is(err, sql.ErrNoRows) {
+		s.errorResponse(w, http.StatusNotFound, "Run not found", err)
+		return
+	}
+	if err != nil {
+		s.errorResponse(w, http.StatusInternalServerError, "Failed to read run output", err)
+		return
+	}
+
+	s.jsonResponse(w, http.StatusOK, RunOutputTailResponse{
+		RunID:     runID,
+		Output:    tail,
+		Truncated: truncated,
+	})
+}
+
+// GetTaskRunDiff handles GET /api/v1/tasks/{id}/runs/{runId}/diff?against={otherRunId}
+// Without ?against, the run is compared with the task's previous run.
+func (s *Server) GetTaskRunDiff(w http.ResponseWriter, r *http.Request) {
+	id, err := strconv.ParseInt(chi.URLParam(r, "id"), 10, 64)
+	if err != nil {
+		s.errorResponse(w, http.StatusBadRequest, "Invalid task ID", err)
+		return
+	}
+
+	runID, err := strconv.ParseInt(chi.URLParam(r, "runId"), 10, 64)
+	if err != nil {
+		s.errorResponse(w, http.StatusBadRequest, "Invalid run ID", err)
+		return
+	}
+
+	run, err := s.db.GetTaskRun(runID)
+	if err != nil || run.TaskID != id {
+		s.errorResponse(w, http.StatusNotFound, "Run not found", err)
+		return
+	}
+
+	var against *db.TaskRun
+	if againstStr := r.URL.Query().Get("against"); againstStr != "" {
+		againstID, err := strconv.ParseInt(againstStr, 10, 64)
+		if err != nil {
+			s.errorResponse(w, http.StatusBadRequest, "Invalid against run ID", err)
+			return
+		}
+		against, err = s.db.GetTaskRun(againstID)
+		if err != nil || against.TaskID != id {
+			s.errorResponse(w, http.StatusNotFound, "Against run not found", err)
+			return
+		}
+	} else {
+		against, err = s.db.GetPreviousTaskRun(id, runID)
+		if err != nil {
+			s.errorResponse(w, http.StatusNotFound, "No previous run to compare against", err)
+			return
+		}
+	}
+
+	_ = s.db.LoadRunOutput(run)
+	_ = s.db.LoadRunOutput(against)
+
+	unified, err := diff.Runs(against, run)
+	if err != nil {
+		s.errorResponse(w, http.StatusInternalServerError, "Failed to compute diff", err)
+		return
+	}
+
+	s.jsonResponse(w, http.StatusOK, RunDiffResponse{
+		TaskID:       id,
+		RunID:        run.ID,
+		AgainstRunID: against.ID,
+		Diff:         unified,
+		Identical:    unified == "",
+	})
+}
+
+// Bounds for POST /api/v1/cron/preview
+const (
+	defaultCronPreviewCount = 5
+	maxCronPreviewCount     = 100
+)
+
+// PreviewCron handles POST /api/v1/cron/preview
+func (s *Server) PreviewCron(w http.ResponseWriter, r *http.Request) {
+	var req CronPreviewRequest
+	if !s.decodeJSON(w, r, &req) {
+		return
+	}
+
+	if req.Count == 0 {
+		req.Count = defaultCronPreviewCount
+	}
+	if req.Count < 1 || req.Count > maxCronPreviewCount {
+		s.errorResponse(w, http.StatusBadRequest, fmt.Sprintf("count must be between 1 and %d", maxCronPreviewCount), nil)
+		return
+	}
+	loc := time.Local
+	if req.Timezone != "" {
+		var err error
+		if loc, err = time.LoadLocation(req.Timezone); err != nil {
+			s.errorResponse(w, http.StatusBadRequest, "Invalid timezone", err)
+			return
+		}
+	}
+
+	times, err := cronexpr.NextTimes(req.CronExpr, time.Now().In(loc), req.Count)
+	if err != nil {
+		s.errorResponse(w, http.StatusBadRequest, errInvalidCron.Error(), err)
+		return
+	}
+	next := make([]string, len(times))
+	for i, t := range times {
+		next[i] = t.Format(time.RFC3339)
+	}
+	s.jsonResponse(w, http.StatusOK, CronPreviewResponse{
+		CronExpr:    req.CronExpr,
+		Timezone:    loc.String(),
+		Normalized:  cronexpr.Normalize(req.CronExpr),
+		Description: cronexpr.Describe(req.CronExpr),
+		NextRuns:    next,
+	})
+}
+
+// GetSchedulerStatus handles GET /api/v1/scheduler/status
+func (s *Server) GetSchedulerStatus(w http.ResponseWriter, r *http.Request) {
+	if s.scheduler == nil {
+		s.errorResponse(w, http.StatusServiceUnavailable, "Scheduler not available", nil)
+		return
+	}
+
+	status := s.scheduler.Status()
+
+	response := SchedulerStatusResponse{
+		Running: status.Running,
+		Jobs:    make([]SchedulerJobResponse, len(status.Jobs)),
+		Total:   len(status.Jobs),
+		Queued:  status.Queued,
+	}
+
+	for i, job := range status.Jobs {
+		response.Jobs[i] = SchedulerJobResponse{
+			TaskID:    job.TaskID,
+			EntryID:   int(job.EntryID),
+			Kind:      job.Kind,
+			CronExpr:  job.CronExpr,
+			NextRunAt: job.NextRunAt,
+			Running:   job.Running,
+		}
+	}
+
+	s.jsonResponse(w, http.StatusOK, response)
+}
+
+// GetSettings handles GET /api/v1/settings
+func (s *Server) GetSettings(w http.ResponseWriter, r *http.Request) {
+	s.jsonResponse(w, http.StatusOK, s.settingsResponse())
+}
+
+// UpdateSettings handles PUT /api/v1/settings
+func (s *Server) UpdateSettings(w http.ResponseWriter, r *http.Request) {
+	var req SettingsRequest
+	if !s.decodeJSON(w, r, &req) {
+		return
+	}
+
+	// Validate before applying anything so a bad field doesn't leave a partial update
+	if req.UsageThreshold != nil && (*req.UsageThreshold < 0 || *req.UsageThreshold > 100) {
+		s.errorResponse(w, http.StatusBadRequest, "Usage threshold must be between 0 and 100", nil)
+		return
+	}
+	if req.WebhookRetryAttempts != nil && (*req.WebhookRetryAttempts < 1 || *req.WebhookRetryAttempts > 10) {
+		s.errorResponse(w, http.StatusBadRequest, "Webhook retry attempts must be between 1 and 10", nil)
+		return
+	}
+	if req.WebhookTimeoutSeconds != nil && (*req.WebhookTimeoutSeconds < 1 || *req.WebhookTimeoutSeconds > 300) {
+		s.errorResponse(w, http.StatusBadRequest, "Webhook timeout must be between 1 and 300 seconds", nil)
+		return
+	}
+	if req.SyncIntervalSeconds != nil && (*req.SyncIntervalSeconds < 1 || *req.SyncIntervalSeconds > 3600) {
+		s.errorResponse(w, http.StatusBadRequest, "Sync interval must be between 1 and 3600 seconds", nil)
+		return
+	}
+	if req.MaxConcurrentRuns != nil && (*req.MaxConcurrentRuns < 0 || *req.MaxConcurrentRuns > 64) {
+		s.errorResponse(w, http.StatusBadRequest, "Max concurrent runs must be between 0 and 64", nil)
+		return
+	}
+	if req.MaxRunsPerTask != nil && (*req.MaxRunsPerTask < 0 || *req.MaxRunsPerTask > 100000) {
+		s.errorResponse(w, http.StatusBadRequest, "Max runs per task must be between 0 and 100000", nil)
+		return
+	}
+	if req.LogStorage != nil && *req.LogStorage != db.LogStorageDB && *req.LogStorage != db.LogStorageFile {
+		s.errorResponse(w, http.StatusBadRequest, "Log storage must be db or file", nil)
+		return
+	}
+	if req.DiscordOutputLimit != nil && (*req.DiscordOutputLimit < 1 || *req.DiscordOutputLimit > db.MaxDiscordOutputLimit) {
+		s.errorResponse(w, http.StatusBadRequest, fmt.Sprintf("Discord output limit must be between 1 and %d", db.MaxDiscordOutputLimit), nil)
+		return
+	}
+	if req.SlackOutputLimit != nil && (*req.SlackOutputLimit < 1 || *req.SlackOutputLimit > db.MaxSlackOutputLimit) {
+		s.errorResponse(w, http.StatusBadRequest, fmt.Sprintf("Slack output limit must be between 1 and %d", db.MaxSlackOutputLimit), nil)
+		return
+	}
+	if req.ListPreviewLength != nil && (*req.ListPreviewLength < 1 || *req.ListPreviewLength > db.MaxListPreviewLength) {
+		s.errorResponse(w, http.StatusBadRequest, fmt.Sprintf("List preview length must be between 1 and %d", db.MaxListPreviewLength), nil)
+		return
+	}
+	if req.MaxPromptLength != nil && (*req.MaxPromptLength < 1 || *req.MaxPromptLength > db.MaxPromptLengthLimit) {
+		s.errorResponse(w, http.StatusBadRequest, fmt.Sprintf("Max prompt length must be between 1 and %d", db.MaxPromptLengthLimit), nil)
+		return
+	}
+	if req.WebhookDetail != nil && !db.ValidWebhookDetail(*req.WebhookDetail) {
+		s.errorResponse(w, http.StatusBadRequest, errInvalidWebhookDetail.Error(), nil)
+		return
+	}
+	if req.DefaultDiscordWebhook != nil || req.DefaultSlackWebhook != nil {
+		relaxed, _ := s.db.GetRelaxedWebhookURLs()
+		if req.RelaxedWebhookURLs != nil {
+			relaxed = *req.RelaxedWebhookURLs
+		}
+		if req.DefaultDiscordWebhook != nil && *req.DefaultDiscordWebhook != "" {
+			if err := webhook.ValidateDiscordURL(*req.DefaultDiscordWebhook, relaxed); err != nil {
+				s.errorResponse(w, http.StatusBadRequest, "Default Discord webhook URL "+err.Error(), nil)
+				return
+			}
+		}
+		if req.DefaultSlackWebhook != nil && *req.DefaultSlackWebhook != "" {
+			if err := webhook.ValidateSlackURL(*req.DefaultSlackWebhook, relaxed); err != nil {
+				s.errorResponse(w, http.StatusBadRequest, "Default Slack webhook URL "+err.Error(), nil)
+				return
+			}
+		}
+	}
+	if req.AllowedWorkingDirs != nil {
+		// The allowlist constrains API callers, so only the config file may change it
+		s.errorResponse(w, http.StatusForbidden, "allowed_working_dirs can only be set in the config file", nil)
+		return
+	}
+	if req.ClaudeBinary != nil {
+		// Every run executes this path, so API callers must not be able to repoint it
+		s.errorResponse(w, http.StatusForbidden, "claude_binary can only be set in the config file", nil)
+		return
+	}
+	if req.DefaultCron != nil && strings.TrimSpace(*req.DefaultCron) != "" {
+		if _, err := cronexpr.Parser.Parse(strings.TrimSpace(*req.DefaultCron)); err != nil {
+			s.errorResponse(w, http.StatusBadRequest, "Invalid default cron expression", err)
+			return
+		}
+	}
+	if req.DefaultWorkingDir != nil && *req.DefaultWorkingDir != "" {
+		dir := *req.DefaultWorkingDir
+		if !filepath.IsAbs(dir) {
+			s.errorResponse(w, http.StatusBadRequest, "Default working directory must be an absolute path", nil)
+			return
+		}
+		if info, err := os.Stat(dir); err != nil || !info.IsDir() {
+			s.errorResponse(w, http.StatusBadRequest, "Default working directory not found: "+dir, nil)
+			return
+		}
+	}
+	if req.PublicBaseURL != nil && *req.PublicBaseURL != "" {
+		u, err := url.Parse(*req.PublicBaseURL)
+		if err != nil || (u.Scheme != "http" && u.Scheme != "https") || u.Host == "" {
+			s.errorResponse(w, http.StatusBadRequest, "Public base URL must be an absolute http(s) URL", err)
+			return
+		}
+	}
+	if req.APIAllowedOrigins != nil {
+		if err := validateOrigins(*req.APIAllowedOrigins); err != nil {
+			s.errorResponse(w, http.StatusBadRequest, err.Error(), nil)
+			return
+		}
+	}
+	quiet, _ := s.db.GetQuietHours()
+	if req.QuietHoursStart != nil {
+		quiet.Start = strings.TrimSpace(*req.QuietHoursStart)
+	}
+	if req.QuietHoursEnd != nil {
+		quiet.End = strings.TrimSpace(*req.QuietHoursEnd)
+	}
+	if (quiet.Start == "") != (quiet.End == "") ||
+		(quiet.Start != "" && (!db.ValidClock(quiet.Start) || !db.ValidClock(quiet.End))) {
+		s.errorResponse(w, http.StatusBadRequest, "Quiet hours start and end must both be HH:MM (24-hour) or both empty", nil)
+		return
+	}
+
+	if req.UsageThreshold != nil {
+		if err := s.db.SetUsageThreshold(*req.UsageThreshold); err != nil {
+			s.errorResponse(w, http.StatusInternalServerError, "Failed to update settings", err)
+			return
+		}
+	}
+	if req.APIAllowedOrigins != nil {
+		origins := strings.Join(parseOrigins(*req.APIAllowedOrigins), ",")
+		if err := s.db.SetAPIAllowedOrigins(origins); err != nil {
+			s.errorResponse(w, http.StatusInternalServerError, "Failed to update settings", err)
+			return
+		}
+	}
+
+	if req.WebhookRetryAttempts != nil {
+		if err := s.db.SetWebhookRetryAttempts(*req.WebhookRetryAttempts); err != nil {
+			s.errorResponse(w, http.StatusInternalServerError, "Failed to update settings", err)
+			return
+		}
+	}
+	if req.WebhookTimeoutSeconds != nil {
+		if err := s.db.SetWebhookTimeoutSeconds(*req.WebhookTimeoutSeconds); err != nil {
+			s.errorResponse(w, http.StatusInternalServerError, "Failed to update settings", err)
+			return
+		}
+	}
+
+	if req.PublicBaseURL != nil {
+		if err := s.db.SetPublicBaseURL(strings.TrimRight(*req.PublicBaseURL, "/")); err != nil {
+			s.errorResponse(w, http.StatusInternalServerError, "Failed to update settings", err)
+			return
+		}
+	}
+	if req.QuietHoursStart != nil || req.QuietHoursEnd != nil {
+		if err := s.db.SetQuietHours(quiet); err != nil {
+			s.errorResponse(w, http.StatusInternalServerError, "Failed to update settings", err)
+			return
+		}
+	}
+	if req.UsageCheckEnabled != nil {
+		if err := s.db.SetUsageCheckEnabled(*req.UsageCheckEnabled); err != nil {
+			s.errorResponse(w, http.StatusInternalServerError, "Failed to update settings", err)
+			return
+		}
+	}
+	if req.ConfirmBeforeRun != nil {
+		if err := s.db.SetConfirmBeforeRun(*req.ConfirmBeforeRun); err != nil {
+			s.errorResponse(w, http.StatusInternalServerError, "Failed to update settings", err)
+			return
+		}
+	}
+	if req.RenderMarkdown != nil {
+		if err := s.db.SetRenderMarkdown(*req.RenderMarkdown); err != nil {
+			s.errorResponse(w, http.StatusInternalServerError, "Failed to update settings", err)
+			return
+		}
+	}
+	if req.SyncIntervalSeconds != nil {
+		if err := s.db.SetSyncIntervalSeconds(*req.SyncIntervalSeconds); err != nil {
+			s.errorResponse(w, http.StatusInternalServerError, "Failed to update settings", err)
+			return
+		}
+		s.triggerSync() // Restart the sync timer with the new interval
+	}
+	if req.MaxConcurrentRuns != nil {
+		if err := s.db.SetMaxConcurrentRuns(*req.MaxConcurrentRuns); err != nil {
+			s.errorResponse(w, http.StatusInternalServerError, "Failed to update settings", err)
+			return
+		}
+		s.executor.LimitChanged()
+	}
+	if req.MaxRunsPerTask != nil {
+		if err := s.db.SetMaxRunsPerTask(*req.MaxRunsPerTask); err != nil {
+			s.errorResponse(w, http.StatusInternalServerError, "Failed to update settings", err)
+			return
+		}
+	}
+	if req.LogStorage != nil {
+		if err := s.db.SetLogStorage(*req.LogStorage); err != nil {
+			s.errorResponse(w, http.StatusInternalServerError, "Failed to update settings", err)
+			return
+		}
+	}
+	if req.WebhookDetail != nil {
+		if err := s.db.SetWebhookDetail(*req.WebhookDetail); err != nil {
+			s.errorResponse(w, http.StatusInternalServerError, "Failed to update settings", err)
+			return
+		}
+	}
+	if req.DiscordOutputLimit != nil {
+		if err := s.db.SetDiscordOutputLimit(*req.DiscordOutputLimit); err != nil {
+			s.errorResponse(w, http.StatusInternalServerError, "Failed to update settings", err)
+			return
+		}
+	}
+	if req.SlackOutputLimit != nil {
+		if err := s.db.SetSlackOutputLimit(*req.SlackOutputLimit); err != nil {
+			s.errorResponse(w, http.StatusInternalServerError, "Failed to update settings", err)
+			return
+		}
+	}
+	if req.ListPreviewLength != nil {
+		if err := s.db.SetListPreviewLength(*req.ListPreviewLength); err != nil {
+			s.errorResponse(w, http.StatusInternalServerError, "Failed to update settings", err)
+			return
+		}
+	}
+	if req.MaxPromptLength != nil {
+		if err := s.db.SetMaxPromptLength(*req.MaxPromptLength); err != nil {
+			s.errorResponse(w, http.StatusInternalServerError, "Failed to update settings", err)
+			return
+		}
+	}
+	if req.RelaxedWebhookURLs != nil {
+		if err := s.db.SetRelaxedWebhookURLs(*req.RelaxedWebhookURLs); err != nil {
+			s.errorResponse(w, http.StatusInternalServerError, "Failed to update settings", err)
+			return
+		}
+	}
+	if req.DefaultDiscordWebhook != nil {
+		if err := s.db.SetDefaultDiscordWebhook(*req.DefaultDiscordWebhook); err != nil {
+			s.errorResponse(w, http.StatusInternalServerError, "Failed to update settings", err)
+			return
+		}
+	}
+	if req.DefaultSlackWebhook != nil {
+		if err := s.db.SetDefaultSlackWebhook(*req.DefaultSlackWebhook); err != nil {
+			s.errorResponse(w, http.StatusInternalServerError, "Failed to update settings", err)
+			return
+		}
+	}
+	if req.APIRequestLogging != nil {
+		if err := s.db.SetAPIRequestLogging(*req.APIRequestLogging); err != nil {
+			s.errorResponse(w, http.StatusInternalServerError, "Failed to update settings", err)
+			return
+		}
+	}
+	if req.DefaultWorkingDir != nil {
+		dir := *req.DefaultWorkingDir
+		if dir != "" {
+			dir = filepath.Clean(dir)
+		}
+		if err := s.db.SetDefaultWorkingDir(dir); err != nil {
+			s.errorResponse(w, http.StatusInternalServerError, "Failed to update settings", err)
+			return
+		}
+	}
+	if req.DefaultCron != nil {
+		if err := s.db.SetDefaultCron(strings.TrimSpace(*req.DefaultCron)); err != nil {
+			s.errorResponse(w, http.StatusInternalServerError, "Failed to update settings", err)
+			return
+		}
+	}
+
+	s.jsonResponse(w, http.StatusOK, s.settingsResponse())
+}
+
+// settingsResponse reads the current settings from the database
+func (s *Server) settingsResponse() SettingsResponse {
+	threshold, _ := s.db.GetUsageThreshold()
+	origins, _ := s.db.GetAPIAllowedOrigins()
+	attempts, _ := s.db.GetWebhookRetryAttempts()
+	webhookTimeout, _ := s.db.GetWebhookTimeoutSeconds()
+	baseURL, _ := s.db.GetPublicBaseURL()
+	allowedDirs, _ := s.db.GetAllowedWorkingDirs()
+	confirmBeforeRun, _ := s.db.GetConfirmBeforeRun()
+	renderMarkdown, _ := s.db.GetRenderMarkdown()
+	usageCheck, _ := s.db.GetUsageCheckEnabled()
+	quiet, _ := s.db.GetQuietHours()
+	syncInterval, _ := s.db.GetSyncIntervalSeconds()
+	logStorage, _ := s.db.GetLogStorage()
+	claudeBinary, _ := s.db.GetClaudeBinary()
+	relaxedWebhooks, _ := s.db.GetRelaxedWebhookURLs()
+	maxRuns, _ := s.db.GetMaxConcurrentRuns()
+	runsPerTask, _ := s.db.GetMaxRunsPerTask()
+	requestLogging, _ := s.db.GetAPIRequestLogging()
+	defaultDir, _ := s.db.GetDefaultWorkingDir()
+	defaultCron, _ := s.db.GetDefaultCron()
+	webhookDetail, _ := s.db.GetWebhookDetail()
+	discordLimit, _ := s.db.GetDiscordOutputLimit()
+	slackLimit, _ := s.db.GetSlackOutputLimit()
+	previewLength, _ := s.db.GetListPreviewLength()
+	maxPrompt, _ := s.db.GetMaxPromptLength()
+	defaultDiscord, _ := s.db.GetDefaultDiscordWebhook()
+	defaultSlack, _ := s.db.GetDefaultSlackWebhook()
+	if allowedDirs == nil {
+		allowedDirs = []string{}
+	}
+	return SettingsResponse{
+		UsageThreshold:        threshold,
+		UsageCheckEnabled:     usageCheck,
+		APIAllowedOrigins:     origins,
+		WebhookRetryAttempts:  attempts,
+		WebhookTimeoutSeconds: webhookTimeout,
+		PublicBaseURL:         baseURL,
+		AllowedWorkingDirs:    allowedDirs,
+		ConfirmBeforeRun:      confirmBeforeRun,
+		RenderMarkdown:        renderMarkdown,
+		QuietHoursStart:       quiet.Start,
+		QuietHoursEnd:         quiet.End,
+		SyncIntervalSeconds:   syncInterval,
+		LogStorage:            logStorage,
+		ClaudeBinary:          claudeBinary,
+		RelaxedWebhookURLs:    relaxedWebhooks,
+		MaxConcurrentRuns:     maxRuns,
+		MaxRunsPerTask:        runsPerTask,
+		APIRequestLogging:     requestLogging,
+		DefaultWorkingDir:     defaultDir,
+		DefaultCron:           defaultCron,
+		WebhookDetail:         webhookDetail,
+		DiscordOutputLimit:    discordLimit,
+		SlackOutputLimit:      slackLimit,
+		ListPreviewLength:     previewLength,
+		MaxPromptLength:       maxPrompt,
+		DefaultDiscordWebhook: defaultDiscord,
+		DefaultSlackWebhook:   defaultSlack,
+	}
+}
+
+// ValidateOrigins applies the api_allowed_origins rules to a list of origins,
+// so the config file rejects what PUT /settings would
+func ValidateOrigins(origins []string) error {
+	return validateOrigins(strings.Join(origins, ","))
+}
+
+// validateOrigins checks that each entry is "*" or a scheme://host[:port] origin
+func validateOrigins(val string) error {
+	origins := parseOrigins(val)
+	if len(origins) == 0 {
+		return validationError("At least one allowed origin is required (use * to allow any)")
+	}
+	for _, o := range origins {
+		if o == "*" {
+			continue
+		}
+		u, err := url.Parse(o)
+		if err != nil || u.Scheme == "" || u.Host == "" || u.Path != "" {
+			return validationError("Invalid origin: " + o)
+		}
+	}
+	return nil
+}
+
+// GetUsage handles GET /api/v1/usage
+func (s *Server) GetUsage(w http.ResponseWriter, r *http.Request) {
+	if !s.usageCheckEnabled() {
+		s.errorResponse(w, http.StatusServiceUnavailable, "Usage checking is disabled", nil)
+		return
+	}
+
+	client, err := usage.NewClient()
+	if err != nil {
+		s.errorResponse(w, http.StatusInternalServerError, "Usage client not available", err)
+		return
+	}
+
+	data, err := client.Fetch()
+	if err != nil {
+		s.errorResponse(w, http.StatusInternalServerError, "Failed to fetch usage", err)
+		return
+	}
+
+	s.jsonResponse(w, http.StatusOK, UsageResponse{
+		FiveHour: UsageBucketResponse{
+			Utilization: data.FiveHour.Utilization,
+			ResetsAt:    data.FiveHour.ResetsAt,
+		},
+		SevenDay: UsageBucketResponse{
+			Utilization: data.SevenDay.Utilization,
+			ResetsAt:    data.SevenDay.ResetsAt,
+		},
+	})
+}
+
+// GetUsageStatus handles GET /api/v1/usage/status. It reports what this
+// server last saw rather than calling the usage API itself.
+func (s *Server) GetUsageStatus(w http.ResponseWriter, r *http.Request) {
+	resp := UsageStatusResponse{Enabled: s.usageCheckEnabled()}
+	if path, err := usage.CredentialsPath(); err == nil {
+		resp.CredentialsPath = path
+	}
+	if _, err := usage.NewClient(); err != nil {
+		resp.CredentialsError = err.Error()
+	} else {
+		resp.Configured = true
+	}
+	if at, err := usage.LastFetch(); !at.IsZero() {
+		resp.LastFetchAt = &at
+		if err != nil {
+			resp.LastFetchError = err.Error()
+		}
+	}
+	s.jsonResponse(w, http.StatusOK, resp)
+}
+
+// GetUsageHistory handles GET /api/v1/usage/history?since=RFC3339
+// Defaults to the last 24 hours.
+func (s *Server) GetUsageHistory(w http.ResponseWriter, r *http.Request) {
+	since := time.Now().Add(-24 * time.Hour)
+	if sinceStr := r.URL.Query().Get("since"); sinceStr != "" {
+		parsed, err := time.Parse(time.RFC3339, sinceStr)
+		if err != nil {
+			s.errorResponse(w, http.StatusBadRequest, "Invalid since format (use RFC3339)", err)
+			return
+		}
+		since = parsed
+	}
+
+	samples, err := s.db.GetUsageHistory(since)
+	if err != nil {
+		s.errorResponse(w, http.StatusInternalServerError, "Failed to fetch usage history", err)
+		return
+	}
+
+	response := UsageHistoryResponse{
+		Samples: make([]UsageSampleResponse, len(samples)),
+		Total:   len(samples),
+	}
+	for i, sample := range samples {
+		response.Samples[i] = UsageSampleResponse{
+			SampledAt: sample.SampledAt,
+			FiveHour:  sample.FiveHour,
+			SevenDay:  sample.SevenDay,
+		}
+	}
+
+	s.jsonResponse(w, http.StatusOK, response)
+}
+
+// Helper functions
+
+func (s *Server) taskToResponse(task *db.Task, status db.RunStatus, counts db.RunCounts) TaskResponse {
+	resp := TaskResponse{
+		ID:                     task.ID,
+		Name:                   task.Name,
+		Prompt:                 task.Prompt,
+		PromptFile:             task.PromptFile,
+		StdinFile:              task.StdinFile,
+		PromptSource:           "inline",
+		SystemPrompt:           task.SystemPrompt,
+		OutputFormat:           task.OutputFormat,
+		CronExpr:               task.CronExpr,
+		ScheduledAt:            task.ScheduledAt,
+		IsOneOff:               task.IsOneOff(),
+		WorkingDir:             task.WorkingDir,
+		DiscordWebhook:         task.DiscordWebhook,
+		SlackWebhook:           task.SlackWebhook,
+		UsageThresholdOverride: task.UsageThresholdOverride,
+		Tags:                   task.Tags,
+		RunDuringQuietHours:    task.RunDuringQuietHours,
+		JitterSeconds:          task.JitterSeconds,
+		ActiveFrom:             task.ActiveFrom,
+		ActiveUntil:            task.ActiveUntil,
+		AlertAfterSeconds:      task.AlertAfterSeconds,
+		DisableAfterFailures:   task.DisableAfterFailures,
+		StripAnsi:              task.StripAnsi,
+		IncludePreviousOutput:  task.IncludePreviousOutput,
+		TemplatePrompt:         task.TemplatePrompt,
+		SkipPermissions:        task.SkipPermissions,
+		AllowedTools:           task.AllowedTools,
+		Container:              task.Container,
+		WebhookDetail:          task.WebhookDetail,
+		ResumeSession:          task.ResumeSession,
+		SessionID:              task.SessionID,
+		Enabled:                task.Enabled,
+		CreatedAt:              task.CreatedAt,
+		UpdatedAt:              task.UpdatedAt,
+		LastRunAt:              task.LastRunAt,
+		NextRunAt:              task.NextRunAt,
+		RunCount:               counts.RunCount,
+		FailureStreak:          counts.FailureStreak,
+	}
+	if resp.Tags == nil {
+		resp.Tags = []string{}
+	}
+	if resp.OutputFormat == "" {
+		resp.OutputFormat = db.OutputFormatText
+	}
+	if task.PromptFile != "" {
+		resp.PromptSource = "file"
+	}
+	if task.IsOneOff() {
+		resp.ScheduleDescription = cronexpr.DescribeOnce(task.ScheduledAt)
+	} else {
+		resp.ScheduleDescription = cronexpr.Describe(task.CronExpr)
+	}
+	if status != "" {
+		resp.LastRunStatus = string(status)
+	}
+	return resp
+}
+
+func (s *Server) taskRunToResponse(run *db.TaskRun) TaskRunResponse {
+	resp := TaskRunResponse{
+		ID:           run.ID,
+		TaskID:       run.TaskID,
+		Seq:          run.Seq,
+		StartedAt:    run.StartedAt,
+		EndedAt:      run.EndedAt,
+		Status:       string(run.Status),
+		Output:       run.Output,
+		Error:        run.Error,
+		WebhookError: run.WebhookError,
+		Trigger:      string(run.Trigger),
+		CostUSD:      run.CostUSD,
+		InputTokens:  run.InputTokens,
+		OutputTokens: run.OutputTokens,
+		Notes:        run.Notes,
+		Warning:      run.Warning,
+	}
+	if run.EndedAt != nil {
+		durationMs := run.EndedAt.Sub(run.StartedAt).Milliseconds()
+		resp.DurationMs = &durationMs
+	}
+	return resp
+}
+
+func (s *Server) validateTaskRequest(req *TaskRequest) error {
+	if req.Name == "" {
+		return errEmptyName
+	}
+	if req.Prompt == "" && req.PromptFile == "" {
+		return errEmptyPrompt
+	}
+	if req.Prompt != "" && req.PromptFile != "" {
+		return errPromptConflict
+	}
+	if limit, _ := s.db.GetMaxPromptLength(); utf8.RuneCountInString(req.Prompt) > limit {
+		return validationError(fmt.Sprintf("Prompt must be at most %d characters (max_prompt_length)", limit))
+	}
+	// CronExpr is empty for one-off tasks, non-empty for recurring
+	if req.CronExpr != "" {
+		// Validate cron expression if provided
+		if _, err := cronexpr.Parser.Parse(req.CronExpr); err != nil {
+			return errInvalidCron
+		}
+	}
+	if req.OutputFormat == "" {
+		req.OutputFormat = db.OutputFormatText
+	}
+	if !db.ValidOutputFormat(req.OutputFormat) {
+		return errInvalidOutputFormat
+	}
+	req.Tags = db.NormalizeTags(req.Tags)
+	for _, tag := range req.Tags {
+		if !db.ValidTag(tag) {
+			return errInvalidTag
+		}
+	}
+	if req.UsageThresholdOverride != nil {
+		if *req.UsageThresholdOverride < 0 || *req.UsageThresholdOverride > 100 {
+			return errInvalidThreshold
+		}
+	}
+	if req.JitterSeconds < 0 || req.JitterSeconds > db.MaxJitterSeconds {
+		return errInvalidJitter
+	}
+	if req.AlertAfterSeconds < 0 || req.AlertAfterSeconds > db.MaxAlertAfterSeconds {
+		return errInvalidAlertAfter
+	}
+	if req.DisableAfterFailures < 0 || req.DisableAfterFailures > db.MaxDisableAfterFailures {
+		return errInvalidDisableAfter
+	}
+	if req.WebhookDetail != "" && !db.ValidWebhookDetail(req.WebhookDetail) {
+		return errInvalidWebhookDetail
+	}
+	relaxed, _ := s.db.GetRelaxedWebhookURLs()
+	if req.DiscordWebhook != "" && req.DiscordWebhook != db.WebhookOptOut {
+		if err := webhook.ValidateDiscordURL(req.DiscordWebhook, relaxed); err != nil {
+			return validationError("Discord webhook URL " + err.Error())
+		}
+	}
+	if req.SlackWebhook != "" && req.SlackWebhook != db.WebhookOptOut {
+		if err := webhook.ValidateSlackURL(req.SlackWebhook, relaxed); err != nil {
+			return validationError("Slack webhook URL " + err.Error())
+		}
+	}
+	if req.WorkingDir == "" {
+		dir, err := s.db.GetDefaultWorkingDir()
+		if err != nil {
+			return validationError(err.Error())
+		}
+		req.WorkingDir = dir
+	}
+	// Relative paths would resolve against the server's CWD, not the client's
+	if !filepath.IsAbs(req.WorkingDir) {
+		return errRelativeWorkingDir
+	}
+	req.WorkingDir = filepath.Clean(req.WorkingDir)
+	if info, err := os.Stat(req.WorkingDir); err != nil || !info.IsDir() {
+		return validationError("Working directory not found: " + req.WorkingDir)
+	}
+	if err := s.checkAllowedPath(req.WorkingDir); err != nil {
+		return err
+	}
+	if req.PromptFile != "" {
+		path := executor.ResolvePath(req.WorkingDir, req.PromptFile)
+		if err := s.checkAllowedPath(path); err != nil {
+			return err
+		}
+		if info, err := os.Stat(path); err != nil || info.IsDir() {
+			return validationError("Prompt file not found: " + path)
+		}
+	}
+	if req.StdinFile != "" {
+		path := executor.ResolvePath(req.WorkingDir, req.StdinFile)
+		if err := s.checkAllowedPath(path); err != nil {
+			return err
+		}
+		if info, err := os.Stat(path); err != nil || info.IsDir() {
+			return validationError("Stdin file not found: " + path)
+		}
+	}
+	req.Container = strings.TrimSpace(req.Container)
+	if req.Container != "" {
+		if !db.ValidContainer(req.Container) {
+			return validationError("Invalid container image: " + req.Container)
+		}
+		if _, err := executor.LookupDocker(); err != nil {
+			return validationError(err.Error())
+		}
+	}
+	return nil
+}
+
+// TaskToRequest returns the request that would recreate task as stored. The
+// TUI uses it to show a task's API equivalent.
+func TaskToRequest(task *db.Task) TaskRequest {
+	formatTime := func(t *time.Time) *string {
+		if t == nil {
+			return nil
+		}
+		s := t.Format(time.RFC3339)
+		return &s
+	}
+	stripAnsi := task.StripAnsi
+	skipPermissions := task.SkipPermissions
+	return TaskRequest{
+		Name:                   task.Name,
+		Prompt:                 task.Prompt,
+		PromptFile:             task.PromptFile,
+		StdinFile:              task.StdinFile,
+		SystemPrompt:           task.SystemPrompt,
+		OutputFormat:           task.OutputFormat,
+		CronExpr:               task.CronExpr,
+		ScheduledAt:            formatTime(task.ScheduledAt),
+		WorkingDir:             task.WorkingDir,
+		DiscordWebhook:         task.DiscordWebhook,
+		SlackWebhook:           task.SlackWebhook,
+		UsageThresholdOverride: task.UsageThresholdOverride,
+		Tags:                   task.Tags,
+		RunDuringQuietHours:    task.RunDuringQuietHours,
+		JitterSeconds:          task.JitterSeconds,
+		ActiveFrom:             formatTime(task.ActiveFrom),
+		ActiveUntil:            formatTime(task.ActiveUntil),
+		AlertAfterSeconds:      task.AlertAfterSeconds,
+		DisableAfterFailures:   task.DisableAfterFailures,
+		StripAnsi:              &stripAnsi,
+		IncludePreviousOutput:  task.IncludePreviousOutput,
+		TemplatePrompt:         task.TemplatePrompt,
+		SkipPermissions:        &skipPermissions,
+		AllowedTools:           task.AllowedTools,
+		Container:              task.Container,
+		WebhookDetail:          task.WebhookDetail,
+		ResumeSession:          task.ResumeSession,
+		Enabled:                task.Enabled,
+	}
+}
+
+// applyTaskPatch overwrites the fields of req that are set in patch
+func applyTaskPatch(req *TaskRequest, patch *TaskPatchRequest) {
+	setString := func(dst *string, src *string) {
+		if src != nil {
+			*dst = *src
+		}
+	}
+	setString(&req.Name, patch.Name)
+	setString(&req.Prompt, patch.Prompt)
+	setString(&req.PromptFile, patch.PromptFile)
+	setString(&req.StdinFile, patch.StdinFile)
+	setString(&req.SystemPrompt, patch.SystemPrompt)
+	setString(&req.OutputFormat, patch.OutputFormat)
+	setString(&req.CronExpr, patch.CronExpr)
+	setString(&req.WorkingDir, patch.WorkingDir)
+	setString(&req.DiscordWebhook, patch.DiscordWebhook)
+	setString(&req.SlackWebhook, patch.SlackWebhook)
+	setString(&req.Container, patch.Container)
+	setString(&req.WebhookDetail, patch.WebhookDetail)
+	if patch.ScheduledAt != nil {
+		req.ScheduledAt = patch.ScheduledAt
+	}
+	if patch.ActiveFrom != nil {
+		req.ActiveFrom = patch.ActiveFrom
+	}
+	if patch.ActiveUntil != nil {
+		req.ActiveUntil = patch.ActiveUntil
+	}
+	if patch.UsageThresholdOverride != nil {
+		req.UsageThresholdOverride = patch.UsageThresholdOverride
+	}
+	if patch.Tags != nil {
+		req.Tags = *patch.Tags
+	}
+	if patch.RunDuringQuietHours != nil {
+		req.RunDuringQuietHours = *patch.RunDuringQuietHours
+	}
+	if patch.JitterSeconds != nil {
+		req.JitterSeconds = *patch.JitterSeconds
+	}
+	if patch.AlertAfterSeconds != nil {
+		req.AlertAfterSeconds = *patch.AlertAfterSeconds
+	}
+	if patch.DisableAfterFailures != nil {
+		req.DisableAfterFailures = *patch.DisableAfterFailures
+	}
+	if patch.StripAnsi != nil {
+		req.StripAnsi = patch.StripAnsi
+	}
+	if patch.IncludePreviousOutput != nil {
+		req.IncludePreviousOutput = *patch.IncludePreviousOutput
+	}
+	if patch.TemplatePrompt != nil {
+		req.TemplatePrompt = *patch.TemplatePrompt
+	}
+	if patch.SkipPermissions != nil {
+		req.SkipPermissions = patch.SkipPermissions
+	}
+	if patch.AllowedTools != nil {
+		req.AllowedTools = *patch.AllowedTools
+	}
+	if patch.ResumeSession != nil {
+		req.ResumeSession = *patch.ResumeSession
+	}
+	if patch.Enabled != nil {
+		req.Enabled = *patch.Enabled
+	}
+}
+
+// parseActiveWindow parses the optional active_from/active_until bounds
+func parseActiveWindow(req *TaskRequest) (from, until *time.Time, err error) {
+	parse := func(val *string, field string) (*time.Time, error) {
+		if val == nil || *val == "" {
+			return nil, nil
+		}
+		t, err := time.Parse(time.RFC3339, *val)
+		if err != nil {
+			return nil, validationError("Invalid " + field + " format (use RFC3339)")
+		}
+		return &t, nil
+	}
+	if from, err = parse(req.ActiveFrom, "active_from"); err != nil {
+		return nil, nil, err
+	}
+	if until, err = parse(req.ActiveUntil, "active_until"); err != nil {
+		return nil, nil, err
+	}
+	if from != nil && until != nil && !until.After(*from) {
+		return nil, nil, errInvalidActiveWindow
+	}
+	return from, until, nil
+}
+
+// checkAllowedPath rejects paths outside the allowed_working_dirs prefixes, when configured
+func (s *Server) checkAllowedPath(path string) error {
+	if err := executor.CheckAllowedPath(s.db, path); err != nil {
+		return validationError(err.Error())
+	}
+	return nil
+}
+
+// maxRequestBodyBytes caps JSON request bodies
+const maxRequestBodyBytes = 1 << 20 // 1MB
+
+// decodeJSON decodes a size-limited JSON body into v, writing an error response on failure
+func (s *Server) decodeJSON(w http.ResponseWriter, r *http.Request, v interface{}) bool {
+	r.Body = http.MaxBytesReader(w, r.Body, maxRequestBodyBytes)
+	if err := json.NewDecoder(r.Body).Decode(v); err != nil {
+		var maxErr *http.MaxBytesError
+		if errors.As(err, &maxErr) {
+			s.errorResponse(w, http.StatusRequestEntityTooLarge, "Request body too large", nil)
+			return false
+		}
+		s.errorResponse(w, http.StatusBadRequest, "Invalid request body", err)
+		return false
+	}
+	return true
 }
 
 func (s *Server) jsonResponse(w http.ResponseWriter, status int, data interface{}) {
@@ -455,7 +1849,17 @@ type validationError string
 func (e validationError) Error() string { return string(e) }
 
 const (
-	errEmptyName   validationError = "Name is required"
-	errEmptyPrompt validationError = "Prompt is required"
-	errInvalidCron validationError = "Invalid cron expression"
+	errEmptyName            validationError = "Name is required"
+	errEmptyPrompt          validationError = "Prompt or prompt_file is required"
+	errPromptConflict       validationError = "Prompt and prompt_file are mutually exclusive"
+	errInvalidTag           validationError = "Tags must be at most 32 characters with no spaces or commas"
+	errInvalidCron          validationError = "Invalid cron expression"
+	errInvalidThreshold     validationError = "Usage threshold override must be between 0 and 100"
+	errInvalidOutputFormat  validationError = "Output format must be text, json, or stream-json"
+	errInvalidJitter        validationError = "Jitter must be between 0 and 3600 seconds"
+	errInvalidActiveWindow  validationError = "active_until must be after active_from"
+	errInvalidAlertAfter    validationError = "Alert after must be between 0 and 86400 seconds"
+	errInvalidDisableAfter  validationError = "Disable after failures must be between 0 and 1000"
+	errRelativeWorkingDir   validationError = "Working directory must be an absolute path"
+	errInvalidWebhookDetail validationError = "Webhook detail must be full, summary, or status-only"
 )
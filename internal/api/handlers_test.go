@@ -0,0 +1,98 @@
+package api
+
+import (
+	"encoding/json"
+	"reflect"
+	"testing"
+	"time"
+
+	"github.com/kylemclaren/claude-tasks/internal/db"
+)
+
+func TestValidateOrigins(t *testing.T) {
+	tests := []struct {
+		val string
+		ok  bool
+	}{
+		{"*", true},
+		{"https://tasks.example.com", true},
+		{"https://tasks.example.com/", true}, // Trailing slash is trimmed
+		{"http://localhost:3000, https://a.example.com", true},
+		{"*,https://a.example.com", true},
+		{"", false},
+		{" , ", false},
+		{"tasks.example.com", false},
+		{"https://tasks.example.com/app", false},
+		{"https://", false},
+		{"https://a.example.com,ftp", false},
+	}
+	for _, tt := range tests {
+		err := validateOrigins(tt.val)
+		if (err == nil) != tt.ok {
+			t.Errorf("validateOrigins(%q) = %v, want ok=%v", tt.val, err, tt.ok)
+		}
+	}
+	if err := ValidateOrigins([]string{"https://a.example.com", "bad"}); err == nil {
+		t.Error("ValidateOrigins accepted an invalid entry")
+	}
+}
+
+func TestApplyTaskPatch(t *testing.T) {
+	scheduled := time.Date(2026, 3, 2, 9, 0, 0, 0, time.UTC)
+	threshold := 60.0
+	task := &db.Task{
+		Name:                   "nightly",
+		Prompt:                 "summarize",
+		CronExpr:               "0 0 2 * * *",
+		ScheduledAt:            &scheduled,
+		WorkingDir:             "/srv/app",
+		SlackWebhook:           "https://hooks.slack.com/services/x",
+		UsageThresholdOverride: &threshold,
+		Tags:                   []string{"ops"},
+		JitterSeconds:          30,
+		StripAnsi:              true,
+		SkipPermissions:        true,
+		ResumeSession:          true,
+		Enabled:                true,
+	}
+
+	tests := []struct {
+		name  string
+		body  string
+		apply func(*TaskRequest)
+	}{
+		{"empty body changes nothing", `{}`, func(*TaskRequest) {}},
+		{"string field", `{"prompt": "review"}`, func(r *TaskRequest) { r.Prompt = "review" }},
+		{"false booleans are applied", `{"enabled": false, "strip_ansi": false, "resume_session": false}`, func(r *TaskRequest) {
+			r.Enabled = false
+			r.StripAnsi = boolPtr(false)
+			r.ResumeSession = false
+		}},
+		{"zero numbers are applied", `{"jitter_seconds": 0}`, func(r *TaskRequest) { r.JitterSeconds = 0 }},
+		{"empty tags clear them", `{"tags": []}`, func(r *TaskRequest) { r.Tags = []string{} }},
+		{"empty string clears a webhook", `{"slack_webhook": ""}`, func(r *TaskRequest) { r.SlackWebhook = "" }},
+		{"empty scheduled_at clears it", `{"scheduled_at": ""}`, func(r *TaskRequest) { r.ScheduledAt = strPtr("") }},
+		{"several fields", `{"name": "weekly", "cron_expr": "0 0 2 * * 0", "usage_threshold_override": 80}`, func(r *TaskRequest) {
+			r.Name = "weekly"
+			r.CronExpr = "0 0 2 * * 0"
+			r.UsageThresholdOverride = floatPtr(80)
+		}},
+	}
+	for _, tt := range tests {
+		var patch TaskPatchRequest
+		if err := json.Unmarshal([]byte(tt.body), &patch); err != nil {
+			t.Fatalf("%s: decoding patch: %v", tt.name, err)
+		}
+		got := TaskToRequest(task)
+		applyTaskPatch(&got, &patch)
+		want := TaskToRequest(task)
+		tt.apply(&want)
+		if !reflect.DeepEqual(got, want) {
+			t.Errorf("%s:\n got %+v\nwant %+v", tt.name, got, want)
+		}
+	}
+}
+
+func boolPtr(v bool) *bool        { return &v }
+func strPtr(v string) *string     { return &v }
+func floatPtr(v float64) *float64 { return &v }
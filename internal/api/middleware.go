@@ -1,21 +1,141 @@
 package api
 
-import "net/http"
-
-// CORS middleware allows cross-origin requests from mobile apps
-func CORS(next http.Handler) http.Handler {
-	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
-		w.Header().Set("Access-Control-Allow-Origin", "*")
-		w.Header().Set("Access-Control-Allow-Methods", "GET, POST, PUT, DELETE, OPTIONS")
-		w.Header().Set("Access-Control-Allow-Headers", "Accept, Content-Type, Content-Length, Accept-Encoding, Authorization")
-		w.Header().Set("Access-Control-Max-Age", "86400")
-
-		// Handle preflight requests
-		if r.Method == "OPTIONS" {
-			w.WriteHeader(http.StatusNoContent)
-			return
+import (
+	"log"
+	"net/http"
+	"net/url"
+	"os"
+	"runtime"
+	"strings"
+
+	"github.com/go-chi/chi/v5/middleware"
+)
+
+// RequestLogger logs requests like chi's middleware.Logger, with credential-like
+// query parameters redacted. enabled is consulted on every request so settings
+// changes apply immediately. Headers, including Authorization, are never logged.
+func RequestLogger(enabled func() bool) func(http.Handler) http.Handler {
+	formatter := redactingFormatter{&middleware.DefaultLogFormatter{
+		Logger:  log.New(os.Stdout, "", log.LstdFlags),
+		NoColor: runtime.GOOS == "windows", // Same as middleware.Logger
+	}}
+	logger := middleware.RequestLogger(formatter)
+	return func(next http.Handler) http.Handler {
+		logged := logger(next)
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			if !enabled() {
+				next.ServeHTTP(w, r)
+				return
+			}
+			logged.ServeHTTP(w, r)
+		})
+	}
+}
+
+// redactingFormatter hands the wrapped formatter a copy of the request with a redacted URI
+type redactingFormatter struct {
+	middleware.LogFormatter
+}
+
+func (f redactingFormatter) NewLogEntry(r *http.Request) middleware.LogEntry {
+	redacted := r.WithContext(r.Context())
+	redacted.RequestURI = redactQuery(r.RequestURI)
+	return f.LogFormatter.NewLogEntry(redacted)
+}
+
+// redactQuery replaces the values of credential-like query parameters in uri,
+// leaving the rest of the query as sent
+func redactQuery(uri string) string {
+	path, query, ok := strings.Cut(uri, "?")
+	if !ok {
+		return uri
+	}
+	params := strings.Split(query, "&")
+	for i, param := range params {
+		key, _, _ := strings.Cut(param, "=")
+		name, err := url.QueryUnescape(key)
+		if err != nil {
+			name = key
+		}
+		if isSensitiveParam(strings.ToLower(name)) {
+			params[i] = key + "=REDACTED"
+		}
+	}
+	return path + "?" + strings.Join(params, "&")
+}
+
+// isSensitiveParam reports whether a lowercased query parameter name may carry a credential
+func isSensitiveParam(name string) bool {
+	switch name {
+	case "key", "apikey", "auth", "authorization", "signature", "sig":
+		return true
+	}
+	return strings.Contains(name, "token") ||
+		strings.Contains(name, "secret") ||
+		strings.Contains(name, "password") ||
+		strings.HasSuffix(name, "_key")
+}
+
+// CORS middleware restricts cross-origin requests to the configured origins.
+// allowedOrigins is consulted on every request so settings changes apply immediately.
+func CORS(allowedOrigins func() []string) func(http.Handler) http.Handler {
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			origin := r.Header.Get("Origin")
+
+			// Non-browser clients (curl, native mobile) don't send Origin
+			if origin == "" {
+				next.ServeHTTP(w, r)
+				return
+			}
+
+			allowOrigin, ok := matchOrigin(origin, allowedOrigins())
+			if !ok {
+				http.Error(w, "Origin not allowed", http.StatusForbidden)
+				return
+			}
+
+			w.Header().Set("Access-Control-Allow-Origin", allowOrigin)
+			if allowOrigin != "*" {
+				w.Header().Add("Vary", "Origin")
+			}
+			w.Header().Set("Access-Control-Allow-Methods", "GET, POST, PUT, PATCH, DELETE, OPTIONS")
+			w.Header().Set("Access-Control-Allow-Headers", "Accept, Content-Type, Authorization, If-None-Match")
+			w.Header().Set("Access-Control-Expose-Headers", "ETag")
+			w.Header().Set("Access-Control-Max-Age", "86400")
+
+			// Handle preflight requests
+			if r.Method == "OPTIONS" {
+				w.WriteHeader(http.StatusNoContent)
+				return
+			}
+
+			next.ServeHTTP(w, r)
+		})
+	}
+}
+
+// matchOrigin returns the Access-Control-Allow-Origin value for origin, if allowed
+func matchOrigin(origin string, allowed []string) (string, bool) {
+	for _, a := range allowed {
+		if a == "*" {
+			return "*", true
 		}
+		if strings.EqualFold(a, origin) {
+			return origin, true
+		}
+	}
+	return "", false
+}
 
-		next.ServeHTTP(w, r)
-	})
+// parseOrigins splits a comma-separated origin list, dropping blanks and trailing slashes
+func parseOrigins(val string) []string {
+	var origins []string
+	for _, o := range strings.Split(val, ",") {
+		o = strings.TrimRight(strings.TrimSpace(o), "/")
+		if o != "" {
+			origins = append(origins, o)
+		}
+	}
+	return origins
 }
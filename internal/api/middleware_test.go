@@ -0,0 +1,24 @@
+package api
+
+import "testing"
+
+func TestRedactQuery(t *testing.T) {
+	tests := []struct {
+		uri, want string
+	}{
+		{"/api/v1/tasks", "/api/v1/tasks"},
+		{"/api/v1/tasks?limit=5", "/api/v1/tasks?limit=5"},
+		{"/api/v1/tasks?token=abc&limit=5", "/api/v1/tasks?token=REDACTED&limit=5"},
+		{"/x?access_token=a&api_key=b&apikey=c&sig=d", "/x?access_token=REDACTED&api_key=REDACTED&apikey=REDACTED&sig=REDACTED"},
+		{"/x?Client_Secret=a&PASSWORD=b", "/x?Client_Secret=REDACTED&PASSWORD=REDACTED"},
+		{"/x?%74oken=abc", "/x?%74oken=REDACTED"}, // Escaped names are matched unescaped
+		{"/x?key", "/x?key=REDACTED"},
+		{"/x?keyboard=qwerty&monkey=1", "/x?keyboard=qwerty&monkey=1"},
+		{"/x?a=1&&b=2", "/x?a=1&&b=2"},
+	}
+	for _, tt := range tests {
+		if got := redactQuery(tt.uri); got != tt.want {
+			t.Errorf("redactQuery(%q) = %q, want %q", tt.uri, got, tt.want)
+		}
+	}
+}
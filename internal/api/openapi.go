@@ -0,0 +1,19 @@
+package api
+
+import (
+	_ "embed"
+	"net/http"
+)
+
+// openAPISpec is the hand-maintained OpenAPI 3 description of the routes in
+// setupRoutes. Update openapi.json whenever a route or request/response type changes.
+//
+//go:embed openapi.json
+var openAPISpec []byte
+
+// GetOpenAPISpec handles GET /api/v1/openapi.json
+func (s *Server) GetOpenAPISpec(w http.ResponseWriter, r *http.Request) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusOK)
+	_, _ = w.Write(openAPISpec)
+}
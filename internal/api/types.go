@@ -4,33 +4,112 @@ import "time"
 
 // TaskRequest represents a task creation/update request
 type TaskRequest struct {
-	Name           string  `json:"name"`
-	Prompt         string  `json:"prompt"`
-	CronExpr       string  `json:"cron_expr"`                // Empty for one-off tasks
-	ScheduledAt    *string `json:"scheduled_at,omitempty"`   // ISO datetime for one-off tasks
-	WorkingDir     string  `json:"working_dir"`
-	DiscordWebhook string  `json:"discord_webhook,omitempty"`
-	SlackWebhook   string  `json:"slack_webhook,omitempty"`
-	Enabled        bool    `json:"enabled"`
+	Name                   string   `json:"name"`
+	Prompt                 string   `json:"prompt"`
+	PromptFile             string   `json:"prompt_file,omitempty"`
+	StdinFile              string   `json:"stdin_file,omitempty"` // Piped to the CLI's stdin, relative to working_dir
+	SystemPrompt           string   `json:"system_prompt,omitempty"`
+	OutputFormat           string   `json:"output_format,omitempty"` // "text" (default), "json", or "stream-json"
+	CronExpr               string   `json:"cron_expr"`               // Empty for one-off tasks
+	ScheduledAt            *string  `json:"scheduled_at,omitempty"`  // ISO datetime for one-off tasks
+	WorkingDir             string   `json:"working_dir"`
+	DiscordWebhook         string   `json:"discord_webhook,omitempty"`
+	SlackWebhook           string   `json:"slack_webhook,omitempty"`
+	UsageThresholdOverride *float64 `json:"usage_threshold_override,omitempty"` // Omit or null to use the global threshold
+	Tags                   []string `json:"tags,omitempty"`
+	RunDuringQuietHours    bool     `json:"run_during_quiet_hours,omitempty"`
+	JitterSeconds          int      `json:"jitter_seconds,omitempty"`          // Random delay of up to this many seconds before cron runs
+	ActiveFrom             *string  `json:"active_from,omitempty"`             // RFC3339; cron runs before this are skipped
+	ActiveUntil            *string  `json:"active_until,omitempty"`            // RFC3339; the task is disabled after this
+	AlertAfterSeconds      int      `json:"alert_after_seconds,omitempty"`     // Webhook alert if a run is still going after this long
+	DisableAfterFailures   int      `json:"disable_after_failures,omitempty"`  // Disable the task after this many consecutive failures
+	StripAnsi              *bool    `json:"strip_ansi,omitempty"`              // Remove ANSI escape codes from output; omit for true
+	IncludePreviousOutput  bool     `json:"include_previous_output,omitempty"` // Append the last completed run's output to the prompt
+	TemplatePrompt         bool     `json:"template_prompt,omitempty"`         // Expand {{.Date}}-style template actions in the prompt
+	SkipPermissions        *bool    `json:"skip_permissions,omitempty"`        // Pass --dangerously-skip-permissions; omit for true
+	AllowedTools           string   `json:"allowed_tools,omitempty"`           // --allowedTools list used when skip_permissions is false
+	Container              string   `json:"container,omitempty"`               // Docker image to run the CLI in
+	WebhookDetail          string   `json:"webhook_detail,omitempty"`          // "full", "summary", or "status-only"; omit for the global setting
+	ResumeSession          bool     `json:"resume_session,omitempty"`          // Continue one Claude session across runs; false clears it
+	Enabled                bool     `json:"enabled"`
+}
+
+// TaskPatchRequest represents a partial task update; omitted fields are left unchanged.
+// Empty strings clear scheduled_at, active_from, and active_until.
+type TaskPatchRequest struct {
+	Name                   *string   `json:"name,omitempty"`
+	Prompt                 *string   `json:"prompt,omitempty"`
+	PromptFile             *string   `json:"prompt_file,omitempty"`
+	StdinFile              *string   `json:"stdin_file,omitempty"`
+	SystemPrompt           *string   `json:"system_prompt,omitempty"`
+	OutputFormat           *string   `json:"output_format,omitempty"`
+	CronExpr               *string   `json:"cron_expr,omitempty"`
+	ScheduledAt            *string   `json:"scheduled_at,omitempty"`
+	WorkingDir             *string   `json:"working_dir,omitempty"`
+	DiscordWebhook         *string   `json:"discord_webhook,omitempty"`
+	SlackWebhook           *string   `json:"slack_webhook,omitempty"`
+	UsageThresholdOverride *float64  `json:"usage_threshold_override,omitempty"` // Use PUT to clear back to the global threshold
+	Tags                   *[]string `json:"tags,omitempty"`
+	RunDuringQuietHours    *bool     `json:"run_during_quiet_hours,omitempty"`
+	JitterSeconds          *int      `json:"jitter_seconds,omitempty"`
+	ActiveFrom             *string   `json:"active_from,omitempty"`
+	ActiveUntil            *string   `json:"active_until,omitempty"`
+	AlertAfterSeconds      *int      `json:"alert_after_seconds,omitempty"`
+	DisableAfterFailures   *int      `json:"disable_after_failures,omitempty"`
+	StripAnsi              *bool     `json:"strip_ansi,omitempty"`
+	IncludePreviousOutput  *bool     `json:"include_previous_output,omitempty"`
+	TemplatePrompt         *bool     `json:"template_prompt,omitempty"`
+	SkipPermissions        *bool     `json:"skip_permissions,omitempty"`
+	AllowedTools           *string   `json:"allowed_tools,omitempty"`
+	Container              *string   `json:"container,omitempty"`
+	WebhookDetail          *string   `json:"webhook_detail,omitempty"` // Empty string restores the global setting
+	ResumeSession          *bool     `json:"resume_session,omitempty"`
+	Enabled                *bool     `json:"enabled,omitempty"`
 }
 
 // TaskResponse represents a task in API responses
 type TaskResponse struct {
-	ID             int64      `json:"id"`
-	Name           string     `json:"name"`
-	Prompt         string     `json:"prompt"`
-	CronExpr       string     `json:"cron_expr"`
-	ScheduledAt    *time.Time `json:"scheduled_at,omitempty"`
-	IsOneOff       bool       `json:"is_one_off"`
-	WorkingDir     string     `json:"working_dir"`
-	DiscordWebhook string     `json:"discord_webhook,omitempty"`
-	SlackWebhook   string     `json:"slack_webhook,omitempty"`
-	Enabled        bool       `json:"enabled"`
-	CreatedAt      time.Time  `json:"created_at"`
-	UpdatedAt      time.Time  `json:"updated_at"`
-	LastRunAt      *time.Time `json:"last_run_at,omitempty"`
-	NextRunAt      *time.Time `json:"next_run_at,omitempty"`
-	LastRunStatus  string     `json:"last_run_status,omitempty"`
+	ID                     int64      `json:"id"`
+	Name                   string     `json:"name"`
+	Prompt                 string     `json:"prompt"`
+	PromptFile             string     `json:"prompt_file,omitempty"`
+	PromptSource           string     `json:"prompt_source"` // "inline" or "file"
+	StdinFile              string     `json:"stdin_file,omitempty"`
+	SystemPrompt           string     `json:"system_prompt,omitempty"`
+	OutputFormat           string     `json:"output_format"`
+	CronExpr               string     `json:"cron_expr"`
+	ScheduleDescription    string     `json:"schedule_description"` // e.g. "every 5 minutes", "once at 2024-01-15 09:00"
+	ScheduledAt            *time.Time `json:"scheduled_at,omitempty"`
+	IsOneOff               bool       `json:"is_one_off"`
+	WorkingDir             string     `json:"working_dir"`
+	DiscordWebhook         string     `json:"discord_webhook,omitempty"`
+	SlackWebhook           string     `json:"slack_webhook,omitempty"`
+	UsageThresholdOverride *float64   `json:"usage_threshold_override,omitempty"`
+	Tags                   []string   `json:"tags"`
+	RunDuringQuietHours    bool       `json:"run_during_quiet_hours"`
+	JitterSeconds          int        `json:"jitter_seconds"`
+	ActiveFrom             *time.Time `json:"active_from,omitempty"`
+	ActiveUntil            *time.Time `json:"active_until,omitempty"`
+	AlertAfterSeconds      int        `json:"alert_after_seconds"`
+	DisableAfterFailures   int        `json:"disable_after_failures"`
+	StripAnsi              bool       `json:"strip_ansi"`
+	IncludePreviousOutput  bool       `json:"include_previous_output"`
+	TemplatePrompt         bool       `json:"template_prompt"`
+	SkipPermissions        bool       `json:"skip_permissions"`
+	AllowedTools           string     `json:"allowed_tools,omitempty"`
+	Container              string     `json:"container,omitempty"`
+	WebhookDetail          string     `json:"webhook_detail,omitempty"`
+	ResumeSession          bool       `json:"resume_session"`
+	SessionID              string     `json:"session_id,omitempty"` // Session the next run resumes
+	Enabled                bool       `json:"enabled"`
+	CreatedAt              time.Time  `json:"created_at"`
+	UpdatedAt              time.Time  `json:"updated_at"`
+	LastRunAt              *time.Time `json:"last_run_at,omitempty"`
+	NextRunAt              *time.Time `json:"next_run_at,omitempty"`
+	LastRunStatus          string     `json:"last_run_status,omitempty"`
+	RunCount               int        `json:"run_count"`         // Runs that weren't skipped
+	FailureStreak          int        `json:"failure_streak"`    // Consecutive failures since the last completed run
+	Warning                string     `json:"warning,omitempty"` // Set by create and update when the saved task can't run yet
 }
 
 // TaskListResponse represents a list of tasks
@@ -41,14 +120,34 @@ type TaskListResponse struct {
 
 // TaskRunResponse represents a task run in API responses
 type TaskRunResponse struct {
-	ID         int64      `json:"id"`
-	TaskID     int64      `json:"task_id"`
-	StartedAt  time.Time  `json:"started_at"`
-	EndedAt    *time.Time `json:"ended_at,omitempty"`
-	Status     string     `json:"status"`
-	Output     string     `json:"output"`
-	Error      string     `json:"error,omitempty"`
-	DurationMs *int64     `json:"duration_ms,omitempty"`
+	ID           int64      `json:"id"`
+	TaskID       int64      `json:"task_id"`
+	Seq          int64      `json:"seq"` // Run number within the task, from 1
+	StartedAt    time.Time  `json:"started_at"`
+	EndedAt      *time.Time `json:"ended_at,omitempty"`
+	Status       string     `json:"status"`
+	Output       string     `json:"output"`
+	Error        string     `json:"error,omitempty"`
+	WebhookError string     `json:"webhook_error,omitempty"`
+	Trigger      string     `json:"trigger,omitempty"` // "cron", "oneoff", "manual", or "api"
+	DurationMs   *int64     `json:"duration_ms,omitempty"`
+	CostUSD      *float64   `json:"cost_usd,omitempty"` // Reported by json and stream-json output only
+	InputTokens  int64      `json:"input_tokens,omitempty"`
+	OutputTokens int64      `json:"output_tokens,omitempty"`
+	Notes        string     `json:"notes,omitempty"`
+	Warning      string     `json:"warning,omitempty"` // Successful run whose output couldn't be parsed
+}
+
+// RunPatchRequest updates a run's annotation
+type RunPatchRequest struct {
+	Notes *string `json:"notes,omitempty"` // Empty string clears the note
+}
+
+// RunOutputTailResponse holds the end of a run's output
+type RunOutputTailResponse struct {
+	RunID     int64  `json:"run_id"`
+	Output    string `json:"output"`
+	Truncated bool   `json:"truncated"` // Earlier output was left out
 }
 
 // TaskRunsResponse represents a list of task runs
@@ -57,14 +156,142 @@ type TaskRunsResponse struct {
 	Total int               `json:"total"`
 }
 
+// RecentRunResponse is a run listed across all tasks, with its task's name
+type RecentRunResponse struct {
+	TaskRunResponse
+	TaskName string `json:"task_name"`
+}
+
+// RecentRunsResponse represents the newest runs across all tasks
+type RecentRunsResponse struct {
+	Runs  []RecentRunResponse `json:"runs"`
+	Total int                 `json:"total"`
+}
+
+// TaskStatsResponse summarizes a task's recent runs
+type TaskStatsResponse struct {
+	TaskID        int64   `json:"task_id"`
+	TotalRuns     int     `json:"total_runs"`
+	SampleSize    int     `json:"sample_size"`
+	Completed     int     `json:"completed"`
+	Failed        int     `json:"failed"`
+	Skipped       int     `json:"skipped"`
+	SuccessRate   float64 `json:"success_rate"` // Percent of completed vs completed+failed
+	MinDurationMs *int64  `json:"min_duration_ms,omitempty"`
+	MaxDurationMs *int64  `json:"max_duration_ms,omitempty"`
+	AvgDurationMs *int64  `json:"avg_duration_ms,omitempty"`
+	P95DurationMs *int64  `json:"p95_duration_ms,omitempty"`
+}
+
+// TaskReportResponse is the digest sent by POST /api/v1/tasks/{id}/report
+type TaskReportResponse struct {
+	TaskID         int64   `json:"task_id"`
+	TotalRuns      int     `json:"total_runs"`
+	SampleSize     int     `json:"sample_size"`
+	Completed      int     `json:"completed"`
+	Failed         int     `json:"failed"`
+	Skipped        int     `json:"skipped"`
+	SuccessRate    float64 `json:"success_rate"`                // Percent of completed vs completed+failed
+	LastErrorRunID *int64  `json:"last_error_run_id,omitempty"` // Most recent failed run in the sample
+	LastError      string  `json:"last_error,omitempty"`
+}
+
+// TaskCostEstimateResponse averages the cost of a task's recent runs
+type TaskCostEstimateResponse struct {
+	TaskID                int64    `json:"task_id"`
+	SampleSize            int      `json:"sample_size"` // Recent runs that reported usage
+	AvgCostUSD            float64  `json:"avg_cost_usd"`
+	AvgInputTokens        int64    `json:"avg_input_tokens"`
+	AvgOutputTokens       int64    `json:"avg_output_tokens"`
+	RunsPerDay            *int     `json:"runs_per_day,omitempty"`             // Omitted for one-off tasks
+	EstimatedDailyCostUSD *float64 `json:"estimated_daily_cost_usd,omitempty"` // avg_cost_usd × runs_per_day
+}
+
+// RunDiffResponse represents a unified diff between two runs' outputs
+type RunDiffResponse struct {
+	TaskID       int64  `json:"task_id"`
+	RunID        int64  `json:"run_id"`
+	AgainstRunID int64  `json:"against_run_id"`
+	Diff         string `json:"diff"`
+	Identical    bool   `json:"identical"`
+}
+
+// CronPreviewRequest asks for the upcoming fire times of a cron expression
+type CronPreviewRequest struct {
+	CronExpr string `json:"cron_expr"`
+	Timezone string `json:"timezone,omitempty"` // IANA name such as "Europe/London"; empty = server local time
+	Count    int    `json:"count,omitempty"`    // 1-100, default 5
+}
+
+// CronPreviewResponse lists the upcoming fire times of a cron expression
+type CronPreviewResponse struct {
+	CronExpr    string   `json:"cron_expr"`
+	Timezone    string   `json:"timezone"`
+	Normalized  string   `json:"normalized"`  // Month and weekday names as numbers, e.g. "0 0 9 * * 1"
+	Description string   `json:"description"` // e.g. "every 5 minutes"
+	NextRuns    []string `json:"next_runs"`   // RFC3339 in the requested timezone
+}
+
 // SettingsResponse represents the settings
 type SettingsResponse struct {
-	UsageThreshold float64 `json:"usage_threshold"`
+	UsageThreshold        float64  `json:"usage_threshold"`
+	UsageCheckEnabled     bool     `json:"usage_check_enabled"`
+	APIAllowedOrigins     string   `json:"api_allowed_origins"`
+	WebhookRetryAttempts  int      `json:"webhook_retry_attempts"`
+	WebhookTimeoutSeconds int      `json:"webhook_timeout_seconds"`
+	PublicBaseURL         string   `json:"public_base_url"`
+	AllowedWorkingDirs    []string `json:"allowed_working_dirs"`
+	ConfirmBeforeRun      bool     `json:"confirm_before_run"`
+	RenderMarkdown        bool     `json:"render_markdown"`   // TUI output view default; false shows raw output
+	QuietHoursStart       string   `json:"quiet_hours_start"` // "HH:MM" local time; empty = disabled
+	QuietHoursEnd         string   `json:"quiet_hours_end"`
+	SyncIntervalSeconds   int      `json:"sync_interval_seconds"`
+	LogStorage            string   `json:"log_storage"` // "db" or "file"
+	ClaudeBinary          string   `json:"claude_binary"`
+	RelaxedWebhookURLs    bool     `json:"relaxed_webhook_urls"`
+	MaxConcurrentRuns     int      `json:"max_concurrent_runs"`
+	MaxRunsPerTask        int      `json:"max_runs_per_task"` // 0 = keep every run
+	APIRequestLogging     bool     `json:"api_request_logging"`
+	DefaultWorkingDir     string   `json:"default_working_dir"`
+	DefaultCron           string   `json:"default_cron"`   // Pre-filled in the TUI add form; empty = none
+	WebhookDetail         string   `json:"webhook_detail"` // "full", "summary", or "status-only"
+	DiscordOutputLimit    int      `json:"discord_output_limit"`
+	SlackOutputLimit      int      `json:"slack_output_limit"`
+	ListPreviewLength     int      `json:"list_preview_length"`     // First-line previews, e.g. summary webhooks
+	MaxPromptLength       int      `json:"max_prompt_length"`       // Characters allowed in an inline prompt
+	DefaultDiscordWebhook string   `json:"default_discord_webhook"` // Used by tasks without their own
+	DefaultSlackWebhook   string   `json:"default_slack_webhook"`
 }
 
-// SettingsRequest represents a settings update request
+// SettingsRequest represents a settings update request; omitted fields are left unchanged
 type SettingsRequest struct {
-	UsageThreshold float64 `json:"usage_threshold"`
+	UsageThreshold        *float64  `json:"usage_threshold,omitempty"`
+	UsageCheckEnabled     *bool     `json:"usage_check_enabled,omitempty"` // false disables usage fetching and the threshold
+	APIAllowedOrigins     *string   `json:"api_allowed_origins,omitempty"`
+	WebhookRetryAttempts  *int      `json:"webhook_retry_attempts,omitempty"`
+	WebhookTimeoutSeconds *int      `json:"webhook_timeout_seconds,omitempty"` // Per delivery attempt (1-300)
+	PublicBaseURL         *string   `json:"public_base_url,omitempty"`         // Empty string disables run links
+	AllowedWorkingDirs    *[]string `json:"allowed_working_dirs,omitempty"`    // Rejected with 403; set it in the config file
+	ConfirmBeforeRun      *bool     `json:"confirm_before_run,omitempty"`
+	RenderMarkdown        *bool     `json:"render_markdown,omitempty"`   // false makes the TUI show raw output by default
+	QuietHoursStart       *string   `json:"quiet_hours_start,omitempty"` // Empty string (with end) disables quiet hours
+	QuietHoursEnd         *string   `json:"quiet_hours_end,omitempty"`
+	SyncIntervalSeconds   *int      `json:"sync_interval_seconds,omitempty"`   // How often the scheduler reloads tasks (1-3600)
+	LogStorage            *string   `json:"log_storage,omitempty"`             // "file" writes run output to log files, keeping a preview in the DB
+	ClaudeBinary          *string   `json:"claude_binary,omitempty"`           // Rejected with 403; set it in the config file
+	RelaxedWebhookURLs    *bool     `json:"relaxed_webhook_urls,omitempty"`    // true accepts any http(s) webhook URL
+	MaxConcurrentRuns     *int      `json:"max_concurrent_runs,omitempty"`     // Runs executed at once per process (0-64, 0 = unlimited)
+	MaxRunsPerTask        *int      `json:"max_runs_per_task,omitempty"`       // Older runs beyond this many are deleted (0 = unlimited)
+	APIRequestLogging     *bool     `json:"api_request_logging,omitempty"`     // false stops logging API requests
+	DefaultWorkingDir     *string   `json:"default_working_dir,omitempty"`     // Used when a task has no working_dir; empty restores the data dir
+	DefaultCron           *string   `json:"default_cron,omitempty"`            // Cron pre-filled in the TUI add form; empty clears it
+	WebhookDetail         *string   `json:"webhook_detail,omitempty"`          // Result message detail for tasks without their own
+	DiscordOutputLimit    *int      `json:"discord_output_limit,omitempty"`    // Output kept in Discord results, in bytes (1-4000)
+	SlackOutputLimit      *int      `json:"slack_output_limit,omitempty"`      // Output kept in Slack results, in bytes (1-2900)
+	ListPreviewLength     *int      `json:"list_preview_length,omitempty"`     // Length of first-line previews (1-1000)
+	MaxPromptLength       *int      `json:"max_prompt_length,omitempty"`       // Longest inline prompt accepted (1-100000)
+	DefaultDiscordWebhook *string   `json:"default_discord_webhook,omitempty"` // Notified by tasks with no discord_webhook; empty clears it
+	DefaultSlackWebhook   *string   `json:"default_slack_webhook,omitempty"`   // Notified by tasks with no slack_webhook; empty clears it
 }
 
 // UsageBucketResponse represents a usage bucket
@@ -79,6 +306,29 @@ type UsageResponse struct {
 	SevenDay UsageBucketResponse `json:"seven_day"`
 }
 
+// UsageStatusResponse explains whether usage data is available and why not
+type UsageStatusResponse struct {
+	Enabled          bool       `json:"enabled"`    // usage_check_enabled, and the server wasn't started with --no-usage-check
+	Configured       bool       `json:"configured"` // Credentials were found and hold an access token
+	CredentialsPath  string     `json:"credentials_path,omitempty"`
+	CredentialsError string     `json:"credentials_error,omitempty"`
+	LastFetchAt      *time.Time `json:"last_fetch_at,omitempty"` // Most recent usage API request by this server
+	LastFetchError   string     `json:"last_fetch_error,omitempty"`
+}
+
+// UsageSampleResponse represents a stored usage sample
+type UsageSampleResponse struct {
+	SampledAt time.Time `json:"sampled_at"`
+	FiveHour  float64   `json:"five_hour"`
+	SevenDay  float64   `json:"seven_day"`
+}
+
+// UsageHistoryResponse represents a usage time series
+type UsageHistoryResponse struct {
+	Samples []UsageSampleResponse `json:"samples"`
+	Total   int                   `json:"total"`
+}
+
 // ErrorResponse represents an error response
 type ErrorResponse struct {
 	Error   string `json:"error"`
@@ -94,6 +344,51 @@ type SuccessResponse struct {
 
 // HealthResponse represents the health check response
 type HealthResponse struct {
+	Status    string                  `json:"status"`
+	Version   string                  `json:"version,omitempty"`
+	Database  ComponentHealth         `json:"database"`
+	Scheduler SchedulerHealthResponse `json:"scheduler"`
+	Claude    ComponentHealth         `json:"claude"` // Reported only; a missing CLI fails runs but not the health check
+}
+
+// VersionResponse represents the build information of the running binary
+type VersionResponse struct {
+	Version   string `json:"version"`
+	Commit    string `json:"commit"`
+	BuildDate string `json:"build_date"`
+	GoVersion string `json:"go_version"`
+	OS        string `json:"os"`
+	Arch      string `json:"arch"`
+}
+
+// ComponentHealth represents the health of a single dependency
+type ComponentHealth struct {
+	Status string `json:"status"`
+	Error  string `json:"error,omitempty"`
+}
+
+// SchedulerHealthResponse represents the scheduler's health
+type SchedulerHealthResponse struct {
 	Status  string `json:"status"`
-	Version string `json:"version,omitempty"`
+	Running bool   `json:"running"`
+	Jobs    int    `json:"jobs"`
+	Error   string `json:"error,omitempty"`
+}
+
+// SchedulerJobResponse represents a single job loaded in the scheduler
+type SchedulerJobResponse struct {
+	TaskID    int64      `json:"task_id"`
+	EntryID   int        `json:"entry_id,omitempty"`
+	Kind      string     `json:"kind"`
+	CronExpr  string     `json:"cron_expr,omitempty"`
+	NextRunAt *time.Time `json:"next_run_at,omitempty"`
+	Running   bool       `json:"running"`
+}
+
+// SchedulerStatusResponse represents the scheduler's in-memory state
+type SchedulerStatusResponse struct {
+	Running bool                   `json:"running"`
+	Jobs    []SchedulerJobResponse `json:"jobs"`
+	Total   int                    `json:"total"`
+	Queued  int                    `json:"queued"` // Runs waiting for a max_concurrent_runs slot
 }
@@ -0,0 +1,23 @@
+package api
+
+import (
+	"embed"
+	"io/fs"
+	"net/http"
+)
+
+// webFS holds the read-only browser dashboard served at /. It only uses GET
+// endpoints, which same-origin browsers send without an Origin header, so it
+// works without adding the server to api_allowed_origins.
+//
+//go:embed web
+var webFS embed.FS
+
+// webHandler serves the embedded dashboard assets
+func webHandler() http.Handler {
+	sub, err := fs.Sub(webFS, "web")
+	if err != nil {
+		panic(err) // The embedded tree is fixed at build time
+	}
+	return http.FileServerFS(sub)
+}
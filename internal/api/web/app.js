@@ -0,0 +1,136 @@
+// Read-only dashboard for the claude-tasks API. Run output is stored when a
+// run finishes, so the page polls rather than streaming.
+const API = "/api/v1";
+const POLL_MS = 5000;
+
+let selectedTask = null;
+let selectedRun = null;
+
+async function get(path) {
+  const res = await fetch(API + path);
+  if (!res.ok) {
+    throw new Error(`${path}: ${res.status}`);
+  }
+  return res.json();
+}
+
+function el(tag, text, className) {
+  const node = document.createElement(tag);
+  if (text !== undefined) node.textContent = text;
+  if (className) node.className = className;
+  return node;
+}
+
+function when(iso) {
+  return iso ? new Date(iso).toLocaleString() : "—";
+}
+
+function duration(ms) {
+  if (ms === undefined || ms === null) return "—";
+  if (ms < 1000) return `${ms}ms`;
+  const s = Math.round(ms / 1000);
+  return s < 60 ? `${s}s` : `${Math.floor(s / 60)}m ${s % 60}s`;
+}
+
+async function loadScheduler() {
+  try {
+    const status = await get("/scheduler/status");
+    document.getElementById("scheduler").textContent =
+      status.running ? "Scheduler running" : "Scheduler stopped";
+  } catch {
+    document.getElementById("scheduler").textContent = "";
+  }
+}
+
+async function loadTasks() {
+  const { tasks } = await get("/tasks");
+  const body = document.querySelector("#tasks tbody");
+  body.replaceChildren();
+  document.getElementById("tasks-empty").hidden = tasks.length > 0;
+  for (const task of tasks) {
+    const row = document.createElement("tr");
+    if (!task.enabled) row.classList.add("disabled");
+    if (selectedTask && selectedTask.id === task.id) {
+      row.classList.add("selected");
+      selectedTask = task;
+    }
+    row.append(
+      el("td", task.name),
+      el("td", task.schedule_description),
+      el("td", task.last_run_status ? `${when(task.last_run_at)} (${task.last_run_status})` : when(task.last_run_at),
+        task.last_run_status ? `status-${task.last_run_status}` : ""),
+      el("td", task.enabled ? when(task.next_run_at) : "disabled"),
+    );
+    row.addEventListener("click", () => selectTask(task));
+    body.append(row);
+  }
+}
+
+async function selectTask(task) {
+  selectedTask = task;
+  selectedRun = null;
+  document.getElementById("output").hidden = true;
+  await Promise.all([loadTasks(), loadRuns()]);
+}
+
+async function loadRuns() {
+  if (!selectedTask) return;
+  document.getElementById("runs-pane").hidden = false;
+  document.getElementById("task-name").textContent = selectedTask.name;
+  document.getElementById("task-prompt").textContent =
+    selectedTask.prompt_source === "file" ? `Prompt file: ${selectedTask.prompt_file}` : selectedTask.prompt;
+
+  const { runs } = await get(`/tasks/${selectedTask.id}/runs?limit=50`);
+  const body = document.querySelector("#runs tbody");
+  body.replaceChildren();
+  for (const run of runs) {
+    const row = document.createElement("tr");
+    if (selectedRun && selectedRun.id === run.id) {
+      row.classList.add("selected");
+      selectedRun = run;
+    }
+    row.append(
+      el("td", when(run.started_at)),
+      el("td", run.status, `status-${run.status}`),
+      el("td", run.trigger || "—"),
+      el("td", duration(run.duration_ms)),
+    );
+    row.addEventListener("click", () => selectRun(run));
+    body.append(row);
+  }
+}
+
+async function selectRun(run) {
+  selectedRun = run;
+  await Promise.all([loadRuns(), loadOutput()]);
+}
+
+async function loadOutput() {
+  if (!selectedTask || !selectedRun) return;
+  const pre = document.getElementById("output");
+  pre.hidden = false;
+  if (selectedRun.status === "running") {
+    pre.textContent = "Running… output appears here when the run finishes.";
+    return;
+  }
+  const tail = await get(`/tasks/${selectedTask.id}/runs/${selectedRun.id}/output/tail?lines=500`);
+  let text = tail.output || "(no output)";
+  if (tail.truncated) text = "… earlier output omitted …\n" + text;
+  if (selectedRun.error) text += `\n\nError: ${selectedRun.error}`;
+  pre.textContent = text;
+}
+
+async function refresh() {
+  try {
+    await Promise.all([loadScheduler(), loadTasks(), loadRuns()]);
+    // Re-fetch output only while it can still change
+    if (selectedRun && document.getElementById("output").textContent.startsWith("Running")) {
+      await loadOutput();
+    }
+  } catch (err) {
+    console.error(err);
+  }
+}
+
+refresh();
+setInterval(refresh, POLL_MS);
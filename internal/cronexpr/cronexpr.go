@@ -0,0 +1,330 @@
+package cronexpr
+
+import (
+	"fmt"
+	"regexp"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/robfig/cron/v3"
+)
+
+// Parser parses 6-field (seconds-first) expressions, standard 5-field ones
+// (seconds default to 0), and descriptors such as @daily and @every 10m. The
+// scheduler uses it too, so anything validation accepts will run.
+var Parser = cron.NewParser(cron.SecondOptional | cron.Minute | cron.Hour | cron.Dom | cron.Month | cron.Dow | cron.Descriptor)
+
+// descriptors phrases the fixed @ schedules
+var descriptors = map[string]string{
+	"@yearly":   "at 00:00 on the 1st in January",
+	"@annually": "at 00:00 on the 1st in January",
+	"@monthly":  "at 00:00 on the 1st",
+	"@weekly":   "at 00:00 on Sunday",
+	"@daily":    "at 00:00 every day",
+	"@midnight": "at 00:00 every day",
+	"@hourly":   "every hour",
+}
+
+var weekdays = []string{"Sunday", "Monday", "Tuesday", "Wednesday", "Thursday", "Friday", "Saturday"}
+
+var months = []string{"", "January", "February", "March", "April", "May", "June",
+	"July", "August", "September", "October", "November", "December"}
+
+// nameNumbers maps the case-insensitive names the parser accepts to their field values
+var (
+	weekdayNumbers = map[string]string{"SUN": "0", "MON": "1", "TUE": "2", "WED": "3", "THU": "4", "FRI": "5", "SAT": "6"}
+	monthNumbers   = map[string]string{"JAN": "1", "FEB": "2", "MAR": "3", "APR": "4", "MAY": "5", "JUN": "6",
+		"JUL": "7", "AUG": "8", "SEP": "9", "OCT": "10", "NOV": "11", "DEC": "12"}
+	nameRe = regexp.MustCompile(`[A-Za-z]+`)
+)
+
+// Describe returns a human-readable phrase such as "every 5 minutes" or
+// "at 09:00 on Monday". Expressions it can't phrase are returned unchanged.
+func Describe(expr string) string {
+	if strings.HasPrefix(expr, "@") {
+		return describeDescriptor(expr)
+	}
+	fields := strings.Fields(withSeconds(expr))
+	if len(fields) != 6 {
+		return expr
+	}
+	if _, err := Parser.Parse(expr); err != nil {
+		return expr
+	}
+	fields = strings.Fields(Normalize(expr))
+	sec, min, hour, dom, month, dow := fields[0], fields[1], fields[2], fields[3], fields[4], fields[5]
+
+	timePart, ok := describeTime(sec, min, hour)
+	if !ok {
+		return expr
+	}
+
+	parts := []string{timePart}
+	if !isWildcard(dom) {
+		d, ok := describeList(dom, func(v int) string { return ordinal(v) })
+		if !ok {
+			return expr
+		}
+		parts = append(parts, "on the "+d)
+	}
+	if !isWildcard(dow) {
+		d, ok := describeWeekdays(dow)
+		if !ok {
+			return expr
+		}
+		parts = append(parts, d)
+	}
+	if !isWildcard(month) {
+		d, ok := describeList(month, func(v int) string { return name(months, v) })
+		if !ok {
+			return expr
+		}
+		parts = append(parts, "in "+d)
+	}
+	if len(parts) == 1 && strings.HasPrefix(timePart, "at ") && !strings.HasPrefix(timePart, "at minute") {
+		parts = append(parts, "every day")
+	}
+	return strings.Join(parts, " ")
+}
+
+// Normalize rewrites month and weekday names in a 6-field expression as numbers,
+// so "0 0 9 * * mon-fri" becomes "0 0 9 * * 1-5". A 5-field expression gains
+// the leading seconds field first. Other expressions are returned unchanged.
+func Normalize(expr string) string {
+	fields := strings.Fields(withSeconds(expr))
+	if len(fields) != 6 {
+		return expr
+	}
+	fields[4] = replaceNames(fields[4], monthNumbers)
+	fields[5] = replaceNames(fields[5], weekdayNumbers)
+	return strings.Join(fields, " ")
+}
+
+// withSeconds prepends a zero seconds field to a 5-field expression
+func withSeconds(expr string) string {
+	if strings.HasPrefix(expr, "@") || len(strings.Fields(expr)) != 5 {
+		return expr
+	}
+	return "0 " + strings.TrimSpace(expr)
+}
+
+// replaceNames substitutes the value of each name in field found in numbers
+func replaceNames(field string, numbers map[string]string) string {
+	return nameRe.ReplaceAllStringFunc(field, func(name string) string {
+		if n, ok := numbers[strings.ToUpper(name)]; ok {
+			return n
+		}
+		return name
+	})
+}
+
+// NextTimes returns the next n times expr fires after from, in from's location
+func NextTimes(expr string, from time.Time, n int) ([]time.Time, error) {
+	schedule, err := Parser.Parse(expr)
+	if err != nil {
+		return nil, err
+	}
+	times := make([]time.Time, 0, n)
+	for t := from; len(times) < n; {
+		t = schedule.Next(t)
+		if t.IsZero() {
+			break // No further matches (e.g. February 30th)
+		}
+		times = append(times, t)
+	}
+	return times, nil
+}
+
+// RunsPerDay counts how often expr fires in the 24 hours after from
+func RunsPerDay(expr string, from time.Time) (int, error) {
+	schedule, err := Parser.Parse(expr)
+	if err != nil {
+		return 0, err
+	}
+	end := from.Add(24 * time.Hour)
+	n := 0
+	// Schedules have one-second resolution, so this ends within 86400 steps
+	for t := schedule.Next(from); !t.IsZero() && !t.After(end); t = schedule.Next(t) {
+		n++
+	}
+	return n, nil
+}
+
+// describeDescriptor phrases an @ schedule such as @daily or @every 1h30m
+func describeDescriptor(expr string) string {
+	if _, err := Parser.Parse(expr); err != nil {
+		return expr
+	}
+	if d, ok := descriptors[expr]; ok {
+		return d
+	}
+	if every, ok := strings.CutPrefix(expr, "@every "); ok {
+		return "every " + strings.TrimSpace(every)
+	}
+	return expr
+}
+
+// DescribeOnce describes a one-off schedule; nil means run immediately
+func DescribeOnce(at *time.Time) string {
+	if at == nil {
+		return "once, immediately"
+	}
+	return "once at " + at.Format("2006-01-02 15:04")
+}
+
+// describeTime phrases the seconds, minutes, and hours fields
+func describeTime(sec, min, hour string) (string, bool) {
+	// Sub-minute schedules
+	if sec == "*" && min == "*" && hour == "*" {
+		return "every second", true
+	}
+	if n, ok := step(sec); ok && min == "*" && hour == "*" {
+		return plural(n, "second"), true
+	}
+	if sec != "0" {
+		return "", false
+	}
+
+	switch {
+	case min == "*" && hour == "*":
+		return "every minute", true
+	case isStep(min) && hour == "*":
+		n, _ := step(min)
+		return plural(n, "minute"), true
+	case min == "0" && hour == "*":
+		return "every hour", true
+	case isNumber(min) && hour == "*":
+		return fmt.Sprintf("at minute %s of every hour", min), true
+	case isNumber(min) && isStep(hour):
+		n, _ := step(hour)
+		if min == "0" {
+			return plural(n, "hour"), true
+		}
+		return fmt.Sprintf("at minute %s %s", min, plural(n, "hour")), true
+	case isNumber(min) && isNumber(hour):
+		return "at " + clock(hour, min), true
+	case isNumber(min) && strings.Contains(hour, ","):
+		var times []string
+		for _, h := range strings.Split(hour, ",") {
+			if !isNumber(h) {
+				return "", false
+			}
+			times = append(times, clock(h, min))
+		}
+		return "at " + joinAnd(times), true
+	case isNumber(min) && isRange(hour):
+		lo, hi, _ := strings.Cut(hour, "-")
+		return fmt.Sprintf("every hour from %s to %s", clock(lo, min), clock(hi, min)), true
+	}
+	return "", false
+}
+
+// describeWeekdays phrases the day-of-week field
+func describeWeekdays(dow string) (string, bool) {
+	switch dow {
+	case "1-5":
+		return "on weekdays", true
+	case "0,6", "6,0":
+		return "on weekends", true
+	}
+	d, ok := describeList(dow, func(v int) string { return name(weekdays, v%7) })
+	if !ok {
+		return "", false
+	}
+	return "on " + d, true
+}
+
+// describeList phrases a numeric list or range using label for each value
+func describeList(field string, label func(int) string) (string, bool) {
+	if isRange(field) {
+		lo, hi, _ := strings.Cut(field, "-")
+		l, err1 := strconv.Atoi(lo)
+		h, err2 := strconv.Atoi(hi)
+		if err1 != nil || err2 != nil {
+			return "", false
+		}
+		return label(l) + " through " + label(h), true
+	}
+	var names []string
+	for _, v := range strings.Split(field, ",") {
+		n, err := strconv.Atoi(v)
+		if err != nil {
+			return "", false
+		}
+		names = append(names, label(n))
+	}
+	return joinAnd(names), true
+}
+
+func isWildcard(field string) bool {
+	return field == "*" || field == "?"
+}
+
+func isNumber(field string) bool {
+	_, err := strconv.Atoi(field)
+	return err == nil
+}
+
+func isRange(field string) bool {
+	lo, hi, ok := strings.Cut(field, "-")
+	return ok && isNumber(lo) && isNumber(hi)
+}
+
+func isStep(field string) bool {
+	_, ok := step(field)
+	return ok
+}
+
+// step returns N for a "*/N" field
+func step(field string) (int, bool) {
+	rest, ok := strings.CutPrefix(field, "*/")
+	if !ok {
+		return 0, false
+	}
+	n, err := strconv.Atoi(rest)
+	return n, err == nil && n > 0
+}
+
+func plural(n int, unit string) string {
+	if n == 1 {
+		return "every " + unit
+	}
+	return fmt.Sprintf("every %d %ss", n, unit)
+}
+
+func clock(hour, min string) string {
+	h, _ := strconv.Atoi(hour)
+	m, _ := strconv.Atoi(min)
+	return fmt.Sprintf("%02d:%02d", h, m)
+}
+
+func name(names []string, v int) string {
+	if v >= 0 && v < len(names) && names[v] != "" {
+		return names[v]
+	}
+	return strconv.Itoa(v)
+}
+
+func ordinal(n int) string {
+	suffix := "th"
+	switch n % 10 {
+	case 1:
+		suffix = "st"
+	case 2:
+		suffix = "nd"
+	case 3:
+		suffix = "rd"
+	}
+	if n%100 >= 11 && n%100 <= 13 {
+		suffix = "th"
+	}
+	return strconv.Itoa(n) + suffix
+}
+
+func joinAnd(items []string) string {
+	if len(items) <= 1 {
+		return strings.Join(items, "")
+	}
+	return strings.Join(items[:len(items)-1], ", ") + " and " + items[len(items)-1]
+}
@@ -0,0 +1,150 @@
+package cronexpr
+
+import (
+	"testing"
+	"time"
+)
+
+func TestParse(t *testing.T) {
+	tests := []struct {
+		expr string
+		ok   bool
+	}{
+		{"0 */5 * * * *", true},
+		{"*/5 * * * *", true}, // 5-field, seconds default to 0
+		{"0 0 9 * * mon-fri", true},
+		{"0 9 1 jan,jul *", true},
+		{"@daily", true},
+		{"@every 90s", true},
+		{"@every 1h30m", true},
+		{"", false},
+		{"* * * *", false},
+		{"0 0 0 0 0 0 0", false},
+		{"61 * * * * *", false},
+		{"0 0 25 * * *", false},
+		{"0 0 9 * * funday", false},
+		{"@fortnightly", false},
+		{"@every soon", false},
+	}
+	for _, tt := range tests {
+		_, err := Parser.Parse(tt.expr)
+		if (err == nil) != tt.ok {
+			t.Errorf("Parse(%q) error = %v, want ok=%v", tt.expr, err, tt.ok)
+		}
+	}
+}
+
+func TestDescribe(t *testing.T) {
+	tests := []struct {
+		expr, want string
+	}{
+		{"* * * * * *", "every second"},
+		{"*/30 * * * * *", "every 30 seconds"},
+		{"0 * * * * *", "every minute"},
+		{"*/5 * * * *", "every 5 minutes"},
+		{"0 0 * * * *", "every hour"},
+		{"0 15 * * * *", "at minute 15 of every hour"},
+		{"0 0 */2 * * *", "every 2 hours"},
+		{"0 30 9 * * *", "at 09:30 every day"},
+		{"0 0 9,17 * * *", "at 09:00 and 17:00 every day"},
+		{"0 0 9-17 * * *", "every hour from 09:00 to 17:00"},
+		{"0 0 9 * * 1-5", "at 09:00 on weekdays"},
+		{"0 0 10 * * 0,6", "at 10:00 on weekends"},
+		{"0 0 9 1 * *", "at 09:00 on the 1st"},
+		{"0 0 9 2,3,11 * *", "at 09:00 on the 2nd, 3rd and 11th"},
+
+		// Month and weekday names read like their numbers
+		{"0 0 9 * * mon-fri", "at 09:00 on weekdays"},
+		{"0 0 9 * * MON,WED", "at 09:00 on Monday and Wednesday"},
+		{"0 0 9 * * sun", "at 09:00 on Sunday"},
+		{"0 0 9 1 jan *", "at 09:00 on the 1st in January"},
+		{"0 0 9 * jun-aug sat", "at 09:00 on Saturday in June through August"},
+
+		// Descriptors
+		{"@daily", "at 00:00 every day"},
+		{"@midnight", "at 00:00 every day"},
+		{"@weekly", "at 00:00 on Sunday"},
+		{"@monthly", "at 00:00 on the 1st"},
+		{"@yearly", "at 00:00 on the 1st in January"},
+		{"@hourly", "every hour"},
+		{"@every 10m", "every 10m"},
+
+		// Unphraseable or invalid expressions come back unchanged
+		{"0 0 9 L * *", "0 0 9 L * *"},
+		{"@every soon", "@every soon"},
+		{"not cron", "not cron"},
+	}
+	for _, tt := range tests {
+		if got := Describe(tt.expr); got != tt.want {
+			t.Errorf("Describe(%q) = %q, want %q", tt.expr, got, tt.want)
+		}
+	}
+}
+
+func TestNormalize(t *testing.T) {
+	tests := []struct {
+		expr, want string
+	}{
+		{"0 0 9 * * mon-fri", "0 0 9 * * 1-5"},
+		{"0 9 * JAN,Dec Sun", "0 0 9 * 1,12 0"},
+		{"*/5 * * * *", "0 */5 * * * *"},
+		{"0 0 9 * * 1-5", "0 0 9 * * 1-5"},
+		{"@daily", "@daily"},
+		{"* * *", "* * *"},
+	}
+	for _, tt := range tests {
+		if got := Normalize(tt.expr); got != tt.want {
+			t.Errorf("Normalize(%q) = %q, want %q", tt.expr, got, tt.want)
+		}
+	}
+}
+
+func TestRunsPerDay(t *testing.T) {
+	from := time.Date(2026, 3, 2, 12, 0, 0, 0, time.UTC) // A Monday
+	tests := []struct {
+		expr string
+		want int
+	}{
+		{"0 * * * * *", 1440},
+		{"*/15 * * * *", 96},
+		{"@hourly", 24},
+		{"@every 30m", 48},
+		{"0 30 9 * * *", 1},
+		{"0 0 9 * * sat", 0},
+		{"0 0 0 30 feb *", 0}, // Never fires
+	}
+	for _, tt := range tests {
+		got, err := RunsPerDay(tt.expr, from)
+		if err != nil {
+			t.Errorf("RunsPerDay(%q): %v", tt.expr, err)
+			continue
+		}
+		if got != tt.want {
+			t.Errorf("RunsPerDay(%q) = %d, want %d", tt.expr, got, tt.want)
+		}
+	}
+	if _, err := RunsPerDay("bogus", from); err == nil {
+		t.Error("RunsPerDay accepted an invalid expression")
+	}
+}
+
+func TestNextTimes(t *testing.T) {
+	from := time.Date(2026, 3, 2, 8, 59, 30, 0, time.UTC)
+	times, err := NextTimes("0 0 9,17 * * *", from, 3)
+	if err != nil {
+		t.Fatalf("NextTimes: %v", err)
+	}
+	want := []time.Time{
+		time.Date(2026, 3, 2, 9, 0, 0, 0, time.UTC),
+		time.Date(2026, 3, 2, 17, 0, 0, 0, time.UTC),
+		time.Date(2026, 3, 3, 9, 0, 0, 0, time.UTC),
+	}
+	if len(times) != len(want) {
+		t.Fatalf("got %d times, want %d", len(times), len(want))
+	}
+	for i := range want {
+		if !times[i].Equal(want[i]) {
+			t.Errorf("time %d = %v, want %v", i, times[i], want[i])
+		}
+	}
+}
@@ -0,0 +1,38 @@
+package db
+
+import (
+	"database/sql"
+	"errors"
+	"time"
+
+	"github.com/mattn/go-sqlite3"
+)
+
+// busyRetries is how many more times exec tries a write that failed with
+// SQLITE_BUSY or SQLITE_LOCKED, e.g. while a doctor VACUUM or a long sync
+// transaction holds the lock past busy_timeout
+const busyRetries = 3
+
+// busyBackoff is the wait before the first retry; it doubles after each attempt
+const busyBackoff = 50 * time.Millisecond
+
+// isBusy reports whether err is SQLite's "database is locked" or "table is locked"
+func isBusy(err error) bool {
+	var sqliteErr sqlite3.Error
+	return errors.As(err, &sqliteErr) &&
+		(sqliteErr.Code == sqlite3.ErrBusy || sqliteErr.Code == sqlite3.ErrLocked)
+}
+
+// exec runs a write outside a transaction, retrying with backoff while the
+// database stays locked
+func (db *DB) exec(query string, args ...any) (sql.Result, error) {
+	wait := busyBackoff
+	for attempt := 0; ; attempt++ {
+		result, err := db.conn.Exec(query, args...)
+		if err == nil || attempt == busyRetries || !isBusy(err) {
+			return result, err
+		}
+		time.Sleep(wait)
+		wait *= 2
+	}
+}
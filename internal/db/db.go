@@ -1,11 +1,19 @@
 package db
 
 import (
+	"crypto/cipher"
 	"database/sql"
+	"encoding/json"
+	"errors"
 	"fmt"
+	"io"
+	"math"
 	"os"
 	"path/filepath"
+	"strconv"
+	"strings"
 	"time"
+	"unicode/utf8"
 
 	_ "github.com/mattn/go-sqlite3"
 )
@@ -13,6 +21,10 @@ import (
 // DB wraps the SQLite database connection
 type DB struct {
 	conn *sql.DB
+	dir  string      // Data directory; run log files live under dir/logs
+	aead cipher.AEAD // Encrypts webhook URLs at rest; nil when EncryptionKeyEnv is unset
+
+	defaults map[string]string // Settings used when no value is stored, e.g. from a config file
 }
 
 // New creates a new database connection
@@ -23,20 +35,77 @@ func New(dbPath string) (*DB, error) {
 		return nil, fmt.Errorf("failed to create db directory: %w", err)
 	}
 
-	conn, err := sql.Open("sqlite3", dbPath+"?_foreign_keys=on")
+	// The TUI, daemon, and API server may all open the same file, and the scheduler
+	// and API write concurrently. WAL lets readers proceed during a write, and
+	// busy_timeout makes a blocked writer wait for the lock instead of failing
+	// immediately with "database is locked". Both are applied per connection
+	// via the DSN, so they hold for every connection in the pool. Writes that
+	// still time out are retried by exec.
+	conn, err := sql.Open("sqlite3", dbPath+"?_foreign_keys=on&_journal_mode=WAL&_busy_timeout=5000")
 	if err != nil {
 		return nil, fmt.Errorf("failed to open database: %w", err)
 	}
 
-	db := &DB{conn: conn}
+	aead, err := newSecretCipher(os.Getenv(EncryptionKeyEnv))
+	if err != nil {
+		conn.Close()
+		return nil, fmt.Errorf("failed to set up encryption: %w", err)
+	}
+
+	db := &DB{conn: conn, dir: dir, aead: aead}
 	if err := db.migrate(); err != nil {
 		conn.Close()
 		return nil, fmt.Errorf("failed to migrate database: %w", err)
 	}
 
+	// Warn rather than fail so the data stays reachable for `claude-tasks doctor`
+	if problems, err := db.IntegrityCheck(); err != nil || len(problems) > 0 {
+		fmt.Fprintf(os.Stderr, "Warning: %s failed its integrity check; run `claude-tasks doctor`\n", dbPath)
+	}
+
 	return db, nil
 }
 
+// IntegrityCheck runs PRAGMA integrity_check and returns the problems it reports; none means ok.
+// Badly damaged files can make the check itself fail with an error.
+func (db *DB) IntegrityCheck() ([]string, error) {
+	rows, err := db.conn.Query("PRAGMA integrity_check")
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var problems []string
+	for rows.Next() {
+		var line string
+		if err := rows.Scan(&line); err != nil {
+			return nil, err
+		}
+		if line != "ok" {
+			problems = append(problems, line)
+		}
+	}
+	return problems, rows.Err()
+}
+
+// Vacuum rebuilds the database file, reclaiming free pages
+func (db *DB) Vacuum() error {
+	_, err := db.exec("VACUUM")
+	return err
+}
+
+// Reindex rebuilds every index from its table
+func (db *DB) Reindex() error {
+	_, err := db.exec("REINDEX")
+	return err
+}
+
+// Ping verifies the database is reachable with a trivial query
+func (db *DB) Ping() error {
+	var one int
+	return db.conn.QueryRow("SELECT 1").Scan(&one)
+}
+
 // Close closes the database connection
 func (db *DB) Close() error {
 	return db.conn.Close()
@@ -73,6 +142,15 @@ func (db *DB) migrate() error {
 	CREATE INDEX IF NOT EXISTS idx_task_runs_task_id ON task_runs(task_id);
 	CREATE INDEX IF NOT EXISTS idx_task_runs_started_at ON task_runs(started_at);
 
+	CREATE TABLE IF NOT EXISTS usage_history (
+		id INTEGER PRIMARY KEY AUTOINCREMENT,
+		sampled_at DATETIME NOT NULL,
+		five_hour REAL NOT NULL,
+		seven_day REAL NOT NULL
+	);
+
+	CREATE INDEX IF NOT EXISTS idx_usage_history_sampled_at ON usage_history(sampled_at);
+
 	CREATE TABLE IF NOT EXISTS settings (
 		key TEXT PRIMARY KEY,
 		value TEXT NOT NULL
@@ -82,16 +160,103 @@ func (db *DB) migrate() error {
 	INSERT OR IGNORE INTO settings (key, value) VALUES ('usage_threshold', '80');
 	`
 
-	_, err := db.conn.Exec(schema)
+	_, err := db.exec(schema)
 	if err != nil {
 		return err
 	}
 
 	// Migration: Add slack_webhook column if it doesn't exist
-	_, _ = db.conn.Exec("ALTER TABLE tasks ADD COLUMN slack_webhook TEXT DEFAULT ''")
+	_, _ = db.exec("ALTER TABLE tasks ADD COLUMN slack_webhook TEXT DEFAULT ''")
 
 	// Migration: Add scheduled_at column for one-off tasks
-	_, _ = db.conn.Exec("ALTER TABLE tasks ADD COLUMN scheduled_at DATETIME")
+	_, _ = db.exec("ALTER TABLE tasks ADD COLUMN scheduled_at DATETIME")
+
+	// Migration: Add system_prompt column for --append-system-prompt
+	_, _ = db.exec("ALTER TABLE tasks ADD COLUMN system_prompt TEXT DEFAULT ''")
+
+	// Migration: Add prompt_file column for prompts kept in files
+	_, _ = db.exec("ALTER TABLE tasks ADD COLUMN prompt_file TEXT DEFAULT ''")
+
+	// Migration: Add stdin_file column for content piped to the CLI
+	_, _ = db.exec("ALTER TABLE tasks ADD COLUMN stdin_file TEXT DEFAULT ''")
+
+	// Migration: Add webhook_error column to record failed notification deliveries
+	_, _ = db.exec("ALTER TABLE task_runs ADD COLUMN webhook_error TEXT DEFAULT ''")
+
+	// Migration: Add triggered_by column recording what started each run
+	_, _ = db.exec("ALTER TABLE task_runs ADD COLUMN triggered_by TEXT DEFAULT ''")
+
+	// Migration: Add output_path column for output stored in log files
+	_, _ = db.exec("ALTER TABLE task_runs ADD COLUMN output_path TEXT DEFAULT ''")
+
+	// Migration: Add cost and token columns reported by the JSON output formats
+	_, _ = db.exec("ALTER TABLE task_runs ADD COLUMN cost_usd REAL")
+	_, _ = db.exec("ALTER TABLE task_runs ADD COLUMN input_tokens INTEGER DEFAULT 0")
+	_, _ = db.exec("ALTER TABLE task_runs ADD COLUMN output_tokens INTEGER DEFAULT 0")
+
+	// Migration: Add cancel_requested column, polled by the executor running the run
+	_, _ = db.exec("ALTER TABLE task_runs ADD COLUMN cancel_requested INTEGER DEFAULT 0")
+
+	// Migration: Add notes column for annotating runs after review
+	_, _ = db.exec("ALTER TABLE task_runs ADD COLUMN notes TEXT DEFAULT ''")
+
+	// Migration: Add warning column for successful runs whose output didn't parse
+	_, _ = db.exec("ALTER TABLE task_runs ADD COLUMN warning TEXT DEFAULT ''")
+
+	// Migration: Add seq column numbering each task's runs, and number existing runs in ID order
+	_, _ = db.exec("ALTER TABLE task_runs ADD COLUMN seq INTEGER DEFAULT 0")
+	_, _ = db.exec(`UPDATE task_runs SET seq = (
+		SELECT COUNT(*) FROM task_runs r WHERE r.task_id = task_runs.task_id AND r.id <= task_runs.id
+	) WHERE seq = 0`)
+	_, _ = db.exec("CREATE INDEX IF NOT EXISTS idx_task_runs_task_seq ON task_runs(task_id, seq)")
+
+	// Migration: Add tags column (JSON array of strings)
+	_, _ = db.exec("ALTER TABLE tasks ADD COLUMN tags TEXT DEFAULT '[]'")
+
+	// Migration: Add output_format column
+	_, _ = db.exec("ALTER TABLE tasks ADD COLUMN output_format TEXT DEFAULT 'text'")
+
+	// Migration: Add run_during_quiet_hours column
+	_, _ = db.exec("ALTER TABLE tasks ADD COLUMN run_during_quiet_hours INTEGER DEFAULT 0")
+
+	// Migration: Add jitter_seconds column
+	_, _ = db.exec("ALTER TABLE tasks ADD COLUMN jitter_seconds INTEGER DEFAULT 0")
+
+	// Migration: Add active window columns
+	_, _ = db.exec("ALTER TABLE tasks ADD COLUMN active_from DATETIME")
+	_, _ = db.exec("ALTER TABLE tasks ADD COLUMN active_until DATETIME")
+
+	// Migration: Add alert_after_seconds column for long-run alerts
+	_, _ = db.exec("ALTER TABLE tasks ADD COLUMN alert_after_seconds INTEGER DEFAULT 0")
+
+	// Migration: Add disable_after_failures column for auto-disabling broken tasks
+	_, _ = db.exec("ALTER TABLE tasks ADD COLUMN disable_after_failures INTEGER DEFAULT 0")
+
+	// Migration: Add strip_ansi column; existing tasks keep stripping escape codes
+	_, _ = db.exec("ALTER TABLE tasks ADD COLUMN strip_ansi INTEGER DEFAULT 1")
+
+	// Migration: Add include_previous_output column for chaining runs
+	_, _ = db.exec("ALTER TABLE tasks ADD COLUMN include_previous_output INTEGER DEFAULT 0")
+
+	// Migration: Add template_prompt column; existing prompts are sent as written
+	_, _ = db.exec("ALTER TABLE tasks ADD COLUMN template_prompt INTEGER DEFAULT 0")
+
+	// Migration: Add permission columns; existing tasks keep skipping permission prompts
+	_, _ = db.exec("ALTER TABLE tasks ADD COLUMN skip_permissions INTEGER DEFAULT 1")
+	_, _ = db.exec("ALTER TABLE tasks ADD COLUMN allowed_tools TEXT DEFAULT ''")
+
+	// Migration: Add container column for tasks run inside a Docker image
+	_, _ = db.exec("ALTER TABLE tasks ADD COLUMN container TEXT DEFAULT ''")
+
+	// Migration: Add webhook_detail column (empty = use the global setting)
+	_, _ = db.exec("ALTER TABLE tasks ADD COLUMN webhook_detail TEXT DEFAULT ''")
+
+	// Migration: Add resume_session and session_id columns for session continuity
+	_, _ = db.exec("ALTER TABLE tasks ADD COLUMN resume_session INTEGER DEFAULT 0")
+	_, _ = db.exec("ALTER TABLE tasks ADD COLUMN session_id TEXT DEFAULT ''")
+
+	// Migration: Add per-task usage threshold override (NULL = use global)
+	_, _ = db.exec("ALTER TABLE tasks ADD COLUMN usage_threshold_override REAL")
 
 	return nil
 }
@@ -100,15 +265,26 @@ func (db *DB) migrate() error {
 func (db *DB) GetSetting(key string) (string, error) {
 	var value string
 	err := db.conn.QueryRow("SELECT value FROM settings WHERE key = ?", key).Scan(&value)
+	if errors.Is(err, sql.ErrNoRows) {
+		if def, ok := db.defaults[key]; ok {
+			return def, nil
+		}
+	}
 	if err != nil {
 		return "", err
 	}
 	return value, nil
 }
 
+// SetSettingDefaults sets values GetSetting returns for keys with nothing stored.
+// Stored settings still win. Call it before the DB is shared between goroutines.
+func (db *DB) SetSettingDefaults(defaults map[string]string) {
+	db.defaults = defaults
+}
+
 // SetSetting sets a setting value
 func (db *DB) SetSetting(key, value string) error {
-	_, err := db.conn.Exec("INSERT OR REPLACE INTO settings (key, value) VALUES (?, ?)", key, value)
+	_, err := db.exec("INSERT OR REPLACE INTO settings (key, value) VALUES (?, ?)", key, value)
 	return err
 }
 
@@ -131,12 +307,473 @@ func (db *DB) SetUsageThreshold(threshold float64) error {
 	return db.SetSetting("usage_threshold", fmt.Sprintf("%.0f", threshold))
 }
 
+// GetAPIAllowedOrigins retrieves the comma-separated CORS origin allowlist
+func (db *DB) GetAPIAllowedOrigins() (string, error) {
+	val, err := db.GetSetting("api_allowed_origins")
+	if err != nil || strings.TrimSpace(val) == "" {
+		return "*", nil // Default to allowing any origin
+	}
+	return val, nil
+}
+
+// GetWebhookRetryAttempts retrieves how many times a webhook send is attempted
+func (db *DB) GetWebhookRetryAttempts() (int, error) {
+	val, err := db.GetSetting("webhook_retry_attempts")
+	if err != nil {
+		return 3, nil // Default to 3 attempts
+	}
+	attempts, err := strconv.Atoi(val)
+	if err != nil || attempts < 1 {
+		return 3, nil
+	}
+	return attempts, nil
+}
+
+// SetWebhookRetryAttempts sets how many times a webhook send is attempted
+func (db *DB) SetWebhookRetryAttempts(attempts int) error {
+	return db.SetSetting("webhook_retry_attempts", strconv.Itoa(attempts))
+}
+
+// DefaultWebhookTimeoutSeconds bounds each webhook delivery attempt
+const DefaultWebhookTimeoutSeconds = 10
+
+// GetWebhookTimeoutSeconds retrieves how long one webhook delivery attempt may take
+func (db *DB) GetWebhookTimeoutSeconds() (int, error) {
+	val, err := db.GetSetting("webhook_timeout_seconds")
+	if err != nil {
+		return DefaultWebhookTimeoutSeconds, nil
+	}
+	seconds, err := strconv.Atoi(val)
+	if err != nil || seconds < 1 {
+		return DefaultWebhookTimeoutSeconds, nil
+	}
+	return seconds, nil
+}
+
+// SetWebhookTimeoutSeconds sets how long one webhook delivery attempt may take
+func (db *DB) SetWebhookTimeoutSeconds(seconds int) error {
+	return db.SetSetting("webhook_timeout_seconds", strconv.Itoa(seconds))
+}
+
+// DefaultMaxConcurrentRuns caps how many runs one process executes at once
+const DefaultMaxConcurrentRuns = 4
+
+// GetMaxConcurrentRuns retrieves the concurrent run limit; 0 means unlimited
+func (db *DB) GetMaxConcurrentRuns() (int, error) {
+	val, err := db.GetSetting("max_concurrent_runs")
+	if err != nil {
+		return DefaultMaxConcurrentRuns, nil // Default to 4 runs
+	}
+	limit, err := strconv.Atoi(val)
+	if err != nil || limit < 0 {
+		return DefaultMaxConcurrentRuns, nil
+	}
+	return limit, nil
+}
+
+// SetMaxConcurrentRuns sets the concurrent run limit; 0 means unlimited
+func (db *DB) SetMaxConcurrentRuns(limit int) error {
+	return db.SetSetting("max_concurrent_runs", strconv.Itoa(limit))
+}
+
+// GetMaxRunsPerTask retrieves how many runs are kept per task; 0 means unlimited
+func (db *DB) GetMaxRunsPerTask() (int, error) {
+	val, err := db.GetSetting("max_runs_per_task")
+	if err != nil {
+		return 0, nil // Default to keeping every run
+	}
+	keep, err := strconv.Atoi(val)
+	if err != nil || keep < 0 {
+		return 0, nil
+	}
+	return keep, nil
+}
+
+// SetMaxRunsPerTask sets how many runs are kept per task; 0 means unlimited
+func (db *DB) SetMaxRunsPerTask(keep int) error {
+	return db.SetSetting("max_runs_per_task", strconv.Itoa(keep))
+}
+
+// DefaultSyncIntervalSeconds is how often the scheduler reloads tasks from the DB
+const DefaultSyncIntervalSeconds = 10
+
+// GetSyncIntervalSeconds retrieves how often the scheduler reloads tasks from the DB
+func (db *DB) GetSyncIntervalSeconds() (int, error) {
+	val, err := db.GetSetting("sync_interval_seconds")
+	if err != nil {
+		return DefaultSyncIntervalSeconds, nil // Default to 10 seconds
+	}
+	seconds, err := strconv.Atoi(val)
+	if err != nil || seconds < 1 {
+		return DefaultSyncIntervalSeconds, nil
+	}
+	return seconds, nil
+}
+
+// SetSyncIntervalSeconds sets how often the scheduler reloads tasks from the DB
+func (db *DB) SetSyncIntervalSeconds(seconds int) error {
+	return db.SetSetting("sync_interval_seconds", strconv.Itoa(seconds))
+}
+
+// GetPublicBaseURL retrieves the externally reachable API base URL used in notification links
+func (db *DB) GetPublicBaseURL() (string, error) {
+	val, err := db.GetSetting("public_base_url")
+	if err != nil {
+		return "", nil // Default to no links
+	}
+	return val, nil
+}
+
+// SetPublicBaseURL sets the externally reachable API base URL
+func (db *DB) SetPublicBaseURL(baseURL string) error {
+	return db.SetSetting("public_base_url", baseURL)
+}
+
+// GetAllowedWorkingDirs retrieves the working directory prefixes the API may use; empty = unrestricted
+func (db *DB) GetAllowedWorkingDirs() ([]string, error) {
+	val, err := db.GetSetting("allowed_working_dirs")
+	if err != nil || val == "" {
+		return nil, nil
+	}
+	var dirs []string
+	if err := json.Unmarshal([]byte(val), &dirs); err != nil {
+		return nil, fmt.Errorf("invalid allowed_working_dirs setting: %w", err)
+	}
+	return dirs, nil
+}
+
+// SetAllowedWorkingDirs sets the working directory prefixes the API may use
+func (db *DB) SetAllowedWorkingDirs(dirs []string) error {
+	if len(dirs) == 0 {
+		return db.SetSetting("allowed_working_dirs", "")
+	}
+	data, err := json.Marshal(dirs)
+	if err != nil {
+		return err
+	}
+	return db.SetSetting("allowed_working_dirs", string(data))
+}
+
+// GetUsageCheckEnabled reports whether usage is fetched and the threshold enforced
+func (db *DB) GetUsageCheckEnabled() (bool, error) {
+	val, err := db.GetSetting("usage_check_enabled")
+	if err != nil {
+		return true, nil // Default to checking usage
+	}
+	return val != "false", nil
+}
+
+// SetUsageCheckEnabled sets whether usage is fetched and the threshold enforced
+func (db *DB) SetUsageCheckEnabled(enabled bool) error {
+	return db.SetSetting("usage_check_enabled", strconv.FormatBool(enabled))
+}
+
+// GetDefaultWorkingDir retrieves the directory used for tasks saved without one.
+// When unset it is the data directory, so the result never depends on the CWD
+// of whichever process saved the task.
+func (db *DB) GetDefaultWorkingDir() (string, error) {
+	val, err := db.GetSetting("default_working_dir")
+	if err == nil && val != "" {
+		return val, nil
+	}
+	return filepath.Abs(db.dir)
+}
+
+// SetDefaultWorkingDir sets the directory used for tasks saved without one; empty restores the data directory
+func (db *DB) SetDefaultWorkingDir(dir string) error {
+	return db.SetSetting("default_working_dir", dir)
+}
+
+// GetConfiguredWorkingDir retrieves default_working_dir as set, or "" when it is unset
+func (db *DB) GetConfiguredWorkingDir() (string, error) {
+	val, err := db.GetSetting("default_working_dir")
+	if err != nil {
+		return "", nil
+	}
+	return val, nil
+}
+
+// GetDefaultCron retrieves the cron expression pre-filled in the TUI add form; "" = none
+func (db *DB) GetDefaultCron() (string, error) {
+	val, err := db.GetSetting("default_cron")
+	if err != nil {
+		return "", nil
+	}
+	return val, nil
+}
+
+// SetDefaultCron sets the cron expression pre-filled in the TUI add form; empty clears it
+func (db *DB) SetDefaultCron(expr string) error {
+	return db.SetSetting("default_cron", expr)
+}
+
+// GetAPIRequestLogging reports whether the API server logs each request
+func (db *DB) GetAPIRequestLogging() (bool, error) {
+	val, err := db.GetSetting("api_request_logging")
+	if err != nil {
+		return true, nil // Default to logging requests
+	}
+	return val != "false", nil
+}
+
+// SetAPIRequestLogging sets whether the API server logs each request
+func (db *DB) SetAPIRequestLogging(enabled bool) error {
+	return db.SetSetting("api_request_logging", strconv.FormatBool(enabled))
+}
+
+// GetConfirmBeforeRun reports whether the TUI asks before running a task manually
+func (db *DB) GetConfirmBeforeRun() (bool, error) {
+	val, err := db.GetSetting("confirm_before_run")
+	if err != nil {
+		return false, nil // Default to running immediately
+	}
+	return val == "true", nil
+}
+
+// SetConfirmBeforeRun sets whether the TUI asks before running a task manually
+func (db *DB) SetConfirmBeforeRun(confirm bool) error {
+	return db.SetSetting("confirm_before_run", strconv.FormatBool(confirm))
+}
+
+// GetRenderMarkdown reports whether the TUI output view renders run output as markdown
+func (db *DB) GetRenderMarkdown() (bool, error) {
+	val, err := db.GetSetting("render_markdown")
+	if err != nil {
+		return true, nil // Default to rendered output
+	}
+	return val != "false", nil
+}
+
+// SetRenderMarkdown sets whether the TUI output view renders run output as markdown
+func (db *DB) SetRenderMarkdown(render bool) error {
+	return db.SetSetting("render_markdown", strconv.FormatBool(render))
+}
+
+// DefaultClaudeBinary is the CLI looked up on PATH when claude_binary is unset
+const DefaultClaudeBinary = "claude"
+
+// GetClaudeBinary retrieves the Claude CLI name or path used to run tasks
+func (db *DB) GetClaudeBinary() (string, error) {
+	val, err := db.GetSetting("claude_binary")
+	if err != nil || val == "" {
+		return DefaultClaudeBinary, nil // Default to claude on PATH
+	}
+	return val, nil
+}
+
+// SetClaudeBinary sets the Claude CLI name or path; empty restores the default
+func (db *DB) SetClaudeBinary(binary string) error {
+	return db.SetSetting("claude_binary", binary)
+}
+
+// GetRelaxedWebhookURLs reports whether any http(s) URL is accepted as a webhook,
+// rather than only Discord and Slack webhook URLs
+func (db *DB) GetRelaxedWebhookURLs() (bool, error) {
+	val, err := db.GetSetting("relaxed_webhook_urls")
+	if err != nil {
+		return false, nil // Default to strict checking
+	}
+	return val == "true", nil
+}
+
+// SetRelaxedWebhookURLs sets whether any http(s) URL is accepted as a webhook
+func (db *DB) SetRelaxedWebhookURLs(relaxed bool) error {
+	return db.SetSetting("relaxed_webhook_urls", strconv.FormatBool(relaxed))
+}
+
+// GetLogStorage retrieves where run output is persisted (LogStorageDB or LogStorageFile)
+func (db *DB) GetLogStorage() (string, error) {
+	val, err := db.GetSetting("log_storage")
+	if err != nil || val != LogStorageFile {
+		return LogStorageDB, nil // Default to the DB column
+	}
+	return val, nil
+}
+
+// SetLogStorage sets where run output is persisted
+func (db *DB) SetLogStorage(storage string) error {
+	return db.SetSetting("log_storage", storage)
+}
+
+// GetWebhookDetail retrieves the default detail level of webhook result
+// messages (one of the WebhookDetail* constants)
+func (db *DB) GetWebhookDetail() (string, error) {
+	val, err := db.GetSetting("webhook_detail")
+	if err != nil || !ValidWebhookDetail(val) {
+		return WebhookDetailFull, nil // Default to the full message
+	}
+	return val, nil
+}
+
+// SetWebhookDetail sets the default detail level of webhook result messages
+func (db *DB) SetWebhookDetail(detail string) error {
+	return db.SetSetting("webhook_detail", detail)
+}
+
+// GetDefaultDiscordWebhook retrieves the Discord webhook used by tasks without their own
+func (db *DB) GetDefaultDiscordWebhook() (string, error) {
+	return db.getSecretSetting("default_discord_webhook")
+}
+
+// SetDefaultDiscordWebhook sets the Discord webhook used by tasks without their own
+func (db *DB) SetDefaultDiscordWebhook(url string) error {
+	return db.setSecretSetting("default_discord_webhook", url)
+}
+
+// GetDefaultSlackWebhook retrieves the Slack webhook used by tasks without their own
+func (db *DB) GetDefaultSlackWebhook() (string, error) {
+	return db.getSecretSetting("default_slack_webhook")
+}
+
+// SetDefaultSlackWebhook sets the Slack webhook used by tasks without their own
+func (db *DB) SetDefaultSlackWebhook(url string) error {
+	return db.setSecretSetting("default_slack_webhook", url)
+}
+
+// Lengths of run output in webhook result messages. The maximums leave room
+// for the truncation marker within Discord's 4096-character embed description
+// and Slack's 3000-character section text.
+const (
+	DefaultDiscordOutputLimit = 3500
+	MaxDiscordOutputLimit     = 4000
+	DefaultSlackOutputLimit   = 2500
+	MaxSlackOutputLimit       = 2900
+)
+
+// DefaultListPreviewLength is how many characters of a run's first line a
+// short preview shows, e.g. in summary webhook messages
+const DefaultListPreviewLength = 300
+
+// MaxListPreviewLength caps the list_preview_length setting
+const MaxListPreviewLength = 1000
+
+// GetDiscordOutputLimit retrieves how much run output a Discord result message includes
+func (db *DB) GetDiscordOutputLimit() (int, error) {
+	return db.getBoundedInt("discord_output_limit", DefaultDiscordOutputLimit, MaxDiscordOutputLimit), nil
+}
+
+// SetDiscordOutputLimit sets how much run output a Discord result message includes
+func (db *DB) SetDiscordOutputLimit(limit int) error {
+	return db.SetSetting("discord_output_limit", strconv.Itoa(limit))
+}
+
+// GetSlackOutputLimit retrieves how much run output a Slack result message includes
+func (db *DB) GetSlackOutputLimit() (int, error) {
+	return db.getBoundedInt("slack_output_limit", DefaultSlackOutputLimit, MaxSlackOutputLimit), nil
+}
+
+// SetSlackOutputLimit sets how much run output a Slack result message includes
+func (db *DB) SetSlackOutputLimit(limit int) error {
+	return db.SetSetting("slack_output_limit", strconv.Itoa(limit))
+}
+
+// GetListPreviewLength retrieves the length of short output previews
+func (db *DB) GetListPreviewLength() (int, error) {
+	return db.getBoundedInt("list_preview_length", DefaultListPreviewLength, MaxListPreviewLength), nil
+}
+
+// SetListPreviewLength sets the length of short output previews
+func (db *DB) SetListPreviewLength(length int) error {
+	return db.SetSetting("list_preview_length", strconv.Itoa(length))
+}
+
+// DefaultMaxPromptLength is how many characters an inline prompt may have
+// unless max_prompt_length is set
+const DefaultMaxPromptLength = 10000
+
+// MaxPromptLengthLimit caps the max_prompt_length setting
+const MaxPromptLengthLimit = 100000
+
+// GetMaxPromptLength retrieves the longest inline prompt accepted, in characters
+func (db *DB) GetMaxPromptLength() (int, error) {
+	return db.getBoundedInt("max_prompt_length", DefaultMaxPromptLength, MaxPromptLengthLimit), nil
+}
+
+// SetMaxPromptLength sets the longest inline prompt accepted, in characters
+func (db *DB) SetMaxPromptLength(length int) error {
+	return db.SetSetting("max_prompt_length", strconv.Itoa(length))
+}
+
+// getBoundedInt reads an integer setting, falling back to def when it is unset
+// or outside 1..max
+func (db *DB) getBoundedInt(key string, def, max int) int {
+	val, err := db.GetSetting(key)
+	if err != nil {
+		return def
+	}
+	n, err := strconv.Atoi(val)
+	if err != nil || n < 1 || n > max {
+		return def
+	}
+	return n
+}
+
+// GetQuietHours retrieves the window during which scheduled runs are skipped; unset = disabled
+func (db *DB) GetQuietHours() (QuietHours, error) {
+	start, _ := db.GetSetting("quiet_hours_start")
+	end, _ := db.GetSetting("quiet_hours_end")
+	return QuietHours{Start: start, End: end}, nil
+}
+
+// SetQuietHours sets the quiet hours window; empty bounds disable it
+func (db *DB) SetQuietHours(q QuietHours) error {
+	if err := db.SetSetting("quiet_hours_start", q.Start); err != nil {
+		return err
+	}
+	return db.SetSetting("quiet_hours_end", q.End)
+}
+
+// SetAPIAllowedOrigins sets the comma-separated CORS origin allowlist
+func (db *DB) SetAPIAllowedOrigins(origins string) error {
+	return db.SetSetting("api_allowed_origins", origins)
+}
+
+// taskColumns is the column list used by all task SELECTs, in scanTask order
+const taskColumns = `id, name, prompt, prompt_file, stdin_file, system_prompt, output_format, cron_expr, scheduled_at, working_dir, discord_webhook, slack_webhook, usage_threshold_override, tags, run_during_quiet_hours, jitter_seconds, active_from, active_until, alert_after_seconds, disable_after_failures, strip_ansi, include_previous_output, template_prompt, skip_permissions, allowed_tools, container, webhook_detail, resume_session, session_id, enabled, created_at, updated_at, last_run_at, next_run_at`
+
+// rowScanner is satisfied by both *sql.Row and *sql.Rows
+type rowScanner interface {
+	Scan(dest ...any) error
+}
+
+// scanTask scans a row selected with taskColumns into a Task, decrypting its webhook URLs
+func (db *DB) scanTask(row rowScanner) (*Task, error) {
+	task := &Task{}
+	var tags sql.NullString
+	err := row.Scan(&task.ID, &task.Name, &task.Prompt, &task.PromptFile, &task.StdinFile, &task.SystemPrompt, &task.OutputFormat, &task.CronExpr, &task.ScheduledAt, &task.WorkingDir, &task.DiscordWebhook, &task.SlackWebhook, &task.UsageThresholdOverride, &tags, &task.RunDuringQuietHours, &task.JitterSeconds, &task.ActiveFrom, &task.ActiveUntil, &task.AlertAfterSeconds, &task.DisableAfterFailures, &task.StripAnsi, &task.IncludePreviousOutput, &task.TemplatePrompt, &task.SkipPermissions, &task.AllowedTools, &task.Container, &task.WebhookDetail, &task.ResumeSession, &task.SessionID, &task.Enabled, &task.CreatedAt, &task.UpdatedAt, &task.LastRunAt, &task.NextRunAt)
+	if err != nil {
+		return nil, err
+	}
+	if tags.Valid && tags.String != "" {
+		if err := json.Unmarshal([]byte(tags.String), &task.Tags); err != nil {
+			return nil, fmt.Errorf("invalid tags for task %d: %w", task.ID, err)
+		}
+	}
+	if err := db.openWebhooks(task); err != nil {
+		return nil, err
+	}
+	return task, nil
+}
+
+// encodeTags serializes tags for the tags column, always as a JSON array
+func encodeTags(tags []string) string {
+	if len(tags) == 0 {
+		return "[]"
+	}
+	data, _ := json.Marshal(tags)
+	return string(data)
+}
+
 // CreateTask creates a new task
 func (db *DB) CreateTask(task *Task) error {
-	result, err := db.conn.Exec(`
-		INSERT INTO tasks (name, prompt, cron_expr, scheduled_at, working_dir, discord_webhook, slack_webhook, enabled, created_at, updated_at)
-		VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?, ?)
-	`, task.Name, task.Prompt, task.CronExpr, task.ScheduledAt, task.WorkingDir, task.DiscordWebhook, task.SlackWebhook, task.Enabled, time.Now(), time.Now())
+	discord, slack, err := db.sealWebhooks(task)
+	if err != nil {
+		return err
+	}
+	result, err := db.exec(`
+		INSERT INTO tasks (name, prompt, prompt_file, stdin_file, system_prompt, output_format, cron_expr, scheduled_at, working_dir, discord_webhook, slack_webhook, usage_threshold_override, tags, run_during_quiet_hours, jitter_seconds, active_from, active_until, alert_after_seconds, disable_after_failures, strip_ansi, include_previous_output, template_prompt, skip_permissions, allowed_tools, container, webhook_detail, resume_session, session_id, enabled, created_at, updated_at)
+		VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?)
+	`, task.Name, task.Prompt, task.PromptFile, task.StdinFile, task.SystemPrompt, task.OutputFormat, task.CronExpr, task.ScheduledAt, task.WorkingDir, discord, slack, task.UsageThresholdOverride, encodeTags(task.Tags), task.RunDuringQuietHours, task.JitterSeconds, task.ActiveFrom, task.ActiveUntil, task.AlertAfterSeconds, task.DisableAfterFailures, task.StripAnsi, task.IncludePreviousOutput, task.TemplatePrompt, task.SkipPermissions, task.AllowedTools, task.Container, task.WebhookDetail, task.ResumeSession, task.SessionID, task.Enabled, time.Now(), time.Now())
 	if err != nil {
 		return err
 	}
@@ -151,23 +788,12 @@ func (db *DB) CreateTask(task *Task) error {
 
 // GetTask retrieves a task by ID
 func (db *DB) GetTask(id int64) (*Task, error) {
-	task := &Task{}
-	err := db.conn.QueryRow(`
-		SELECT id, name, prompt, cron_expr, scheduled_at, working_dir, discord_webhook, slack_webhook, enabled, created_at, updated_at, last_run_at, next_run_at
-		FROM tasks WHERE id = ?
-	`, id).Scan(&task.ID, &task.Name, &task.Prompt, &task.CronExpr, &task.ScheduledAt, &task.WorkingDir, &task.DiscordWebhook, &task.SlackWebhook, &task.Enabled, &task.CreatedAt, &task.UpdatedAt, &task.LastRunAt, &task.NextRunAt)
-	if err != nil {
-		return nil, err
-	}
-	return task, nil
+	return db.scanTask(db.conn.QueryRow(`SELECT `+taskColumns+` FROM tasks WHERE id = ?`, id))
 }
 
 // ListTasks retrieves all tasks
 func (db *DB) ListTasks() ([]*Task, error) {
-	rows, err := db.conn.Query(`
-		SELECT id, name, prompt, cron_expr, scheduled_at, working_dir, discord_webhook, slack_webhook, enabled, created_at, updated_at, last_run_at, next_run_at
-		FROM tasks ORDER BY created_at DESC
-	`)
+	rows, err := db.conn.Query(`SELECT ` + taskColumns + ` FROM tasks ORDER BY created_at DESC`)
 	if err != nil {
 		return nil, err
 	}
@@ -175,8 +801,7 @@ func (db *DB) ListTasks() ([]*Task, error) {
 
 	var tasks []*Task
 	for rows.Next() {
-		task := &Task{}
-		err := rows.Scan(&task.ID, &task.Name, &task.Prompt, &task.CronExpr, &task.ScheduledAt, &task.WorkingDir, &task.DiscordWebhook, &task.SlackWebhook, &task.Enabled, &task.CreatedAt, &task.UpdatedAt, &task.LastRunAt, &task.NextRunAt)
+		task, err := db.scanTask(rows)
 		if err != nil {
 			return nil, err
 		}
@@ -185,34 +810,146 @@ func (db *DB) ListTasks() ([]*Task, error) {
 	return tasks, rows.Err()
 }
 
+// updateTaskSQL writes every editable column of a task; the id is the last argument.
+// session_id, last_run_at and next_run_at belong to the executor and scheduler,
+// which set them with targeted updates, so a stale copy can't roll them back.
+const updateTaskSQL = `
+		UPDATE tasks SET name = ?, prompt = ?, prompt_file = ?, stdin_file = ?, system_prompt = ?, output_format = ?, cron_expr = ?, scheduled_at = ?, working_dir = ?, discord_webhook = ?, slack_webhook = ?, usage_threshold_override = ?, tags = ?, run_during_quiet_hours = ?, jitter_seconds = ?, active_from = ?, active_until = ?, alert_after_seconds = ?, disable_after_failures = ?, strip_ansi = ?, include_previous_output = ?, template_prompt = ?, skip_permissions = ?, allowed_tools = ?, container = ?, webhook_detail = ?, resume_session = ?, enabled = ?, updated_at = ?
+		WHERE id = ?
+	`
+
+// updateTaskArgs returns the arguments for updateTaskSQL, sealing the webhooks
+func (db *DB) updateTaskArgs(task *Task) ([]any, error) {
+	discord, slack, err := db.sealWebhooks(task)
+	if err != nil {
+		return nil, err
+	}
+	return []any{task.Name, task.Prompt, task.PromptFile, task.StdinFile, task.SystemPrompt, task.OutputFormat, task.CronExpr, task.ScheduledAt, task.WorkingDir, discord, slack, task.UsageThresholdOverride, encodeTags(task.Tags), task.RunDuringQuietHours, task.JitterSeconds, task.ActiveFrom, task.ActiveUntil, task.AlertAfterSeconds, task.DisableAfterFailures, task.StripAnsi, task.IncludePreviousOutput, task.TemplatePrompt, task.SkipPermissions, task.AllowedTools, task.Container, task.WebhookDetail, task.ResumeSession, task.Enabled, task.UpdatedAt, task.ID}, nil
+}
+
 // UpdateTask updates a task
 func (db *DB) UpdateTask(task *Task) error {
 	task.UpdatedAt = time.Now()
-	_, err := db.conn.Exec(`
-		UPDATE tasks SET name = ?, prompt = ?, cron_expr = ?, scheduled_at = ?, working_dir = ?, discord_webhook = ?, slack_webhook = ?, enabled = ?, updated_at = ?, last_run_at = ?, next_run_at = ?
-		WHERE id = ?
-	`, task.Name, task.Prompt, task.CronExpr, task.ScheduledAt, task.WorkingDir, task.DiscordWebhook, task.SlackWebhook, task.Enabled, task.UpdatedAt, task.LastRunAt, task.NextRunAt, task.ID)
+	args, err := db.updateTaskArgs(task)
+	if err != nil {
+		return err
+	}
+	_, err = db.exec(updateTaskSQL, args...)
 	return err
 }
 
+// ErrTaskModified is returned by UpdateTaskIfUnchanged when the task was saved
+// by someone else after it was read
+var ErrTaskModified = errors.New("task was modified since it was read")
+
+// UpdateTaskIfUnchanged updates a task only if its updated_at still equals
+// updatedAt, the value read before the caller changed it. The check and the
+// write share a transaction, so concurrent read-modify-write cycles can't
+// silently overwrite each other.
+func (db *DB) UpdateTaskIfUnchanged(task *Task, updatedAt time.Time) error {
+	task.UpdatedAt = time.Now()
+	args, err := db.updateTaskArgs(task)
+	if err != nil {
+		return err
+	}
+	wait := busyBackoff
+	for attempt := 0; ; attempt++ {
+		err = db.updateTaskTx(task.ID, updatedAt, args)
+		if err == nil || attempt == busyRetries || !isBusy(err) {
+			return err
+		}
+		time.Sleep(wait)
+		wait *= 2
+	}
+}
+
+// updateTaskTx runs one attempt of UpdateTaskIfUnchanged
+func (db *DB) updateTaskTx(id int64, updatedAt time.Time, args []any) error {
+	tx, err := db.conn.Begin()
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	// Compared as times rather than in SQL, since rows written with
+	// CURRENT_TIMESTAMP don't round-trip to the same string
+	var current time.Time
+	if err := tx.QueryRow("SELECT updated_at FROM tasks WHERE id = ?", id).Scan(&current); err != nil {
+		return err
+	}
+	if !current.Equal(updatedAt) {
+		return ErrTaskModified
+	}
+	if _, err := tx.Exec(updateTaskSQL, args...); err != nil {
+		return err
+	}
+	return tx.Commit()
+}
+
 // DeleteTask deletes a task
 func (db *DB) DeleteTask(id int64) error {
-	_, err := db.conn.Exec("DELETE FROM tasks WHERE id = ?", id)
-	return err
+	if _, err := db.exec("DELETE FROM tasks WHERE id = ?", id); err != nil {
+		return err
+	}
+	// Runs are removed by the cascade; their log files go with them
+	_ = os.RemoveAll(filepath.Join(db.dir, "logs", strconv.FormatInt(id, 10)))
+	return nil
 }
 
 // ToggleTask enables or disables a task
 func (db *DB) ToggleTask(id int64) error {
-	_, err := db.conn.Exec("UPDATE tasks SET enabled = NOT enabled, updated_at = ? WHERE id = ?", time.Now(), id)
+	_, err := db.exec("UPDATE tasks SET enabled = NOT enabled, updated_at = ? WHERE id = ?", time.Now(), id)
+	return err
+}
+
+// DisableTask disables a task and clears its next run without touching other
+// fields, so edits saved while a run was in flight survive
+func (db *DB) DisableTask(id int64) error {
+	_, err := db.exec("UPDATE tasks SET enabled = 0, next_run_at = NULL, updated_at = ? WHERE id = ?", time.Now(), id)
+	return err
+}
+
+// SetTaskNextRunAt records when a task is next scheduled to run; nil clears it
+func (db *DB) SetTaskNextRunAt(id int64, nextRunAt *time.Time) error {
+	_, err := db.exec("UPDATE tasks SET next_run_at = ? WHERE id = ?", nextRunAt, id)
 	return err
 }
 
-// CreateTaskRun creates a new task run record
+// SetTaskLastRunAt records when a task's latest run finished
+func (db *DB) SetTaskLastRunAt(id int64, lastRunAt time.Time) error {
+	_, err := db.exec("UPDATE tasks SET last_run_at = ? WHERE id = ?", lastRunAt, id)
+	return err
+}
+
+// SetTaskSessionID stores the Claude session a task resumes; empty starts a new one next run
+func (db *DB) SetTaskSessionID(id int64, sessionID string) error {
+	_, err := db.exec("UPDATE tasks SET session_id = ? WHERE id = ?", sessionID, id)
+	return err
+}
+
+// taskRunColumns is the column list used by all task run SELECTs, in scanTaskRun order
+const taskRunColumns = `id, task_id, seq, started_at, ended_at, status, output, error, webhook_error, triggered_by, output_path, cost_usd, input_tokens, output_tokens, notes, warning`
+
+// scanTaskRun scans a row selected with taskRunColumns into a TaskRun; extra
+// receives any columns selected after them
+func scanTaskRun(row rowScanner, extra ...any) (*TaskRun, error) {
+	run := &TaskRun{}
+	dest := []any{&run.ID, &run.TaskID, &run.Seq, &run.StartedAt, &run.EndedAt, &run.Status, &run.Output, &run.Error, &run.WebhookError, &run.Trigger, &run.OutputPath, &run.CostUSD, &run.InputTokens, &run.OutputTokens, &run.Notes, &run.Warning}
+	err := row.Scan(append(dest, extra...)...)
+	if err != nil {
+		return nil, err
+	}
+	return run, nil
+}
+
+// CreateTaskRun creates a new task run record and sets its ID and Seq. Seq is
+// computed inside the INSERT, which SQLite runs atomically, so concurrent runs
+// of one task never share a number.
 func (db *DB) CreateTaskRun(run *TaskRun) error {
-	result, err := db.conn.Exec(`
-		INSERT INTO task_runs (task_id, started_at, status, output, error)
-		VALUES (?, ?, ?, ?, ?)
-	`, run.TaskID, run.StartedAt, run.Status, run.Output, run.Error)
+	result, err := db.exec(`
+		INSERT INTO task_runs (task_id, seq, started_at, status, output, error, triggered_by)
+		VALUES (?, (SELECT COALESCE(MAX(seq), 0) + 1 FROM task_runs WHERE task_id = ?), ?, ?, ?, ?, ?)
+	`, run.TaskID, run.TaskID, run.StartedAt, run.Status, run.Output, run.Error, run.Trigger)
 	if err != nil {
 		return err
 	}
@@ -222,24 +959,166 @@ func (db *DB) CreateTaskRun(run *TaskRun) error {
 		return err
 	}
 	run.ID = id
-	return nil
+	return db.conn.QueryRow("SELECT seq FROM task_runs WHERE id = ?", id).Scan(&run.Seq)
 }
 
-// UpdateTaskRun updates a task run
+// UpdateTaskRun updates a task run. For file-backed runs only a preview of
+// Output is written to the DB; the full text is in the log file.
 func (db *DB) UpdateTaskRun(run *TaskRun) error {
-	_, err := db.conn.Exec(`
-		UPDATE task_runs SET ended_at = ?, status = ?, output = ?, error = ?
+	output := run.Output
+	if run.OutputPath != "" {
+		output = outputPreview(output)
+	}
+	_, err := db.exec(`
+		UPDATE task_runs SET ended_at = ?, status = ?, output = ?, error = ?, webhook_error = ?, output_path = ?,
+			cost_usd = ?, input_tokens = ?, output_tokens = ?, warning = ?
 		WHERE id = ?
-	`, run.EndedAt, run.Status, run.Output, run.Error, run.ID)
+	`, run.EndedAt, run.Status, output, run.Error, run.WebhookError, run.OutputPath, run.CostUSD, run.InputTokens, run.OutputTokens, run.Warning, run.ID)
 	return err
 }
 
+// RunLogPath returns the log file path for a run: <data dir>/logs/<taskid>/<runid>.log
+func (db *DB) RunLogPath(taskID, runID int64) string {
+	return filepath.Join(db.dir, "logs", strconv.FormatInt(taskID, 10), strconv.FormatInt(runID, 10)+".log")
+}
+
+// WriteRunLog writes the run's full output to its log file and sets OutputPath.
+// Call before UpdateTaskRun so the DB keeps only the preview.
+func (db *DB) WriteRunLog(run *TaskRun) error {
+	path := db.RunLogPath(run.TaskID, run.ID)
+	if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+		return fmt.Errorf("failed to create log directory: %w", err)
+	}
+	if err := os.WriteFile(path, []byte(run.Output), 0644); err != nil {
+		return fmt.Errorf("failed to write run log: %w", err)
+	}
+	run.OutputPath = path
+	return nil
+}
+
+// LoadRunOutput replaces a file-backed run's preview with the full output from
+// its log file. Runs stored in the DB are left unchanged. On error the preview is kept.
+func (db *DB) LoadRunOutput(run *TaskRun) error {
+	if run.OutputPath == "" {
+		return nil
+	}
+	data, err := os.ReadFile(run.OutputPath)
+	if err != nil {
+		return fmt.Errorf("failed to read run log: %w", err)
+	}
+	run.Output = string(data)
+	return nil
+}
+
+// outputPreview truncates output to OutputPreviewLen bytes on a rune boundary
+func outputPreview(output string) string {
+	if len(output) <= OutputPreviewLen {
+		return output
+	}
+	cut := OutputPreviewLen
+	for cut > 0 && !utf8.RuneStart(output[cut]) {
+		cut--
+	}
+	return output[:cut]
+}
+
+// tailChunk is how much output GetTaskRunOutputTail reads per step when counting lines
+const tailChunk = 8192
+
+// GetTaskRunOutputTail returns the end of a run's output: the last lines lines
+// when lines > 0, otherwise the last maxBytes bytes. Log files are read from the
+// end and DB output through substr, a chunk at a time, so only the tail is
+// loaded. truncated reports whether earlier output was left out.
+func (db *DB) GetTaskRunOutputTail(taskID, runID int64, lines, maxBytes int) (tail string, truncated bool, err error) {
+	var outputPath string
+	var size int64
+	err = db.conn.QueryRow(`
+		SELECT COALESCE(output_path, ''), COALESCE(length(CAST(output AS BLOB)), 0) FROM task_runs WHERE id = ? AND task_id = ?
+	`, runID, taskID).Scan(&outputPath, &size)
+	if err != nil {
+		return "", false, err
+	}
+
+	readAt := func(off, n int64) ([]byte, error) {
+		var chunk []byte
+		err := db.conn.QueryRow(`SELECT substr(CAST(output AS BLOB), ?, ?) FROM task_runs WHERE id = ?`, off+1, n, runID).Scan(&chunk)
+		return chunk, err
+	}
+	if outputPath != "" {
+		f, err := os.Open(outputPath)
+		if err != nil {
+			return "", false, fmt.Errorf("failed to read run log: %w", err)
+		}
+		defer f.Close()
+		info, err := f.Stat()
+		if err != nil {
+			return "", false, fmt.Errorf("failed to read run log: %w", err)
+		}
+		size = info.Size()
+		readAt = func(off, n int64) ([]byte, error) {
+			chunk := make([]byte, n)
+			read, err := f.ReadAt(chunk, off)
+			if err == io.EOF {
+				err = nil
+			}
+			return chunk[:read], err
+		}
+	}
+
+	if lines <= 0 {
+		n := min(int64(maxBytes), size)
+		chunk, err := readAt(size-n, n)
+		if err != nil {
+			return "", false, err
+		}
+		// Don't start mid-rune
+		for len(chunk) > 0 && !utf8.RuneStart(chunk[0]) {
+			chunk = chunk[1:]
+		}
+		return string(chunk), int64(len(chunk)) < size, nil
+	}
+
+	// Read backwards until the buffer holds enough newlines; a trailing newline doesn't start a line
+	var buf []byte
+	for off := size; off > 0; {
+		n := min(int64(tailChunk), off)
+		off -= n
+		chunk, err := readAt(off, n)
+		if err != nil {
+			return "", false, err
+		}
+		buf = append(chunk, buf...)
+		if strings.Count(strings.TrimSuffix(string(buf), "\n"), "\n") >= lines {
+			break
+		}
+	}
+	start := len(strings.TrimSuffix(string(buf), "\n"))
+	for i := 0; i < lines; i++ {
+		j := strings.LastIndexByte(string(buf[:start]), '\n')
+		if j < 0 {
+			return string(buf), int64(len(buf)) < size, nil
+		}
+		start = j
+	}
+	return string(buf[start+1:]), true, nil
+}
+
 // GetTaskRuns retrieves runs for a task
 func (db *DB) GetTaskRuns(taskID int64, limit int) ([]*TaskRun, error) {
+	return db.GetTaskRunsPage(taskID, limit, 0)
+}
+
+// GetAllTaskRuns retrieves every run for a task, newest first
+func (db *DB) GetAllTaskRuns(taskID int64) ([]*TaskRun, error) {
+	return db.GetTaskRunsPage(taskID, -1, 0) // SQLite treats a negative LIMIT as no limit
+}
+
+// GetTaskRunsPage retrieves runs for a task, newest first, skipping the first offset runs
+func (db *DB) GetTaskRunsPage(taskID int64, limit, offset int) ([]*TaskRun, error) {
 	rows, err := db.conn.Query(`
-		SELECT id, task_id, started_at, ended_at, status, output, error
-		FROM task_runs WHERE task_id = ? ORDER BY started_at DESC LIMIT ?
-	`, taskID, limit)
+		SELECT `+taskRunColumns+`
+		FROM task_runs WHERE task_id = ? ORDER BY started_at DESC LIMIT ? OFFSET ?
+	`, taskID, limit, offset)
 	if err != nil {
 		return nil, err
 	}
@@ -247,8 +1126,7 @@ func (db *DB) GetTaskRuns(taskID int64, limit int) ([]*TaskRun, error) {
 
 	var runs []*TaskRun
 	for rows.Next() {
-		run := &TaskRun{}
-		err := rows.Scan(&run.ID, &run.TaskID, &run.StartedAt, &run.EndedAt, &run.Status, &run.Output, &run.Error)
+		run, err := scanTaskRun(rows)
 		if err != nil {
 			return nil, err
 		}
@@ -257,17 +1135,309 @@ func (db *DB) GetTaskRuns(taskID int64, limit int) ([]*TaskRun, error) {
 	return runs, rows.Err()
 }
 
+// RecentRun is a run together with the name of its task
+type RecentRun struct {
+	*TaskRun
+	TaskName string
+}
+
+// GetRecentRuns retrieves the newest runs across all tasks, optionally only
+// those with status; an empty status matches every run
+func (db *DB) GetRecentRuns(limit int, status string) ([]*RecentRun, error) {
+	rows, err := db.conn.Query(`
+		SELECT `+taskRunColumns+`, task_name FROM (
+			SELECT task_runs.*, tasks.name AS task_name
+			FROM task_runs JOIN tasks ON tasks.id = task_runs.task_id
+			WHERE ? = '' OR task_runs.status = ?
+		) ORDER BY started_at DESC, id DESC LIMIT ?
+	`, status, status, limit)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var runs []*RecentRun
+	for rows.Next() {
+		recent := &RecentRun{}
+		if recent.TaskRun, err = scanTaskRun(rows, &recent.TaskName); err != nil {
+			return nil, err
+		}
+		runs = append(runs, recent)
+	}
+	return runs, rows.Err()
+}
+
+// CountTaskRuns returns the number of runs recorded for a task
+func (db *DB) CountTaskRuns(taskID int64) (int, error) {
+	var count int
+	err := db.conn.QueryRow("SELECT COUNT(*) FROM task_runs WHERE task_id = ?", taskID).Scan(&count)
+	return count, err
+}
+
+// GetRunningTaskRuns retrieves every run still in progress, oldest first
+func (db *DB) GetRunningTaskRuns() ([]*TaskRun, error) {
+	rows, err := db.conn.Query(`
+		SELECT `+taskRunColumns+`
+		FROM task_runs WHERE status = ? ORDER BY started_at
+	`, RunStatusRunning)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var runs []*TaskRun
+	for rows.Next() {
+		run, err := scanTaskRun(rows)
+		if err != nil {
+			return nil, err
+		}
+		runs = append(runs, run)
+	}
+	return runs, rows.Err()
+}
+
+// RequestRunCancel asks whichever process is executing a run to stop it
+func (db *DB) RequestRunCancel(runID int64) error {
+	result, err := db.exec("UPDATE task_runs SET cancel_requested = 1 WHERE id = ? AND status = ?", runID, RunStatusRunning)
+	if err != nil {
+		return err
+	}
+	if n, err := result.RowsAffected(); err == nil && n == 0 {
+		return fmt.Errorf("run #%d is not running", runID)
+	}
+	return nil
+}
+
+// SetTaskRunNotes replaces a run's notes. UpdateTaskRun leaves them alone, so a
+// note added while the run is in progress survives its completion.
+func (db *DB) SetTaskRunNotes(runID int64, notes string) error {
+	_, err := db.exec("UPDATE task_runs SET notes = ? WHERE id = ?", notes, runID)
+	return err
+}
+
+// PruneRunsByCount deletes a task's runs beyond the newest keep, along with their
+// log files, and returns how many were deleted. Runs still in progress are kept.
+func (db *DB) PruneRunsByCount(taskID int64, keep int) (int, error) {
+	rows, err := db.conn.Query(`
+		SELECT id, COALESCE(output_path, '') FROM task_runs
+		WHERE task_id = ? AND status != ?
+		ORDER BY started_at DESC, id DESC
+		LIMIT -1 OFFSET ?
+	`, taskID, RunStatusRunning, keep)
+	if err != nil {
+		return 0, err
+	}
+	var ids []int64
+	var paths []string
+	for rows.Next() {
+		var id int64
+		var path string
+		if err := rows.Scan(&id, &path); err != nil {
+			rows.Close()
+			return 0, err
+		}
+		ids = append(ids, id)
+		if path != "" {
+			paths = append(paths, path)
+		}
+	}
+	rows.Close()
+	if err := rows.Err(); err != nil {
+		return 0, err
+	}
+
+	for _, id := range ids {
+		if _, err := db.exec("DELETE FROM task_runs WHERE id = ?", id); err != nil {
+			return 0, err
+		}
+	}
+	for _, path := range paths {
+		_ = os.Remove(path)
+	}
+	return len(ids), nil
+}
+
+// RunCancelRequested reports whether cancellation was requested for a run
+func (db *DB) RunCancelRequested(runID int64) (bool, error) {
+	var requested bool
+	err := db.conn.QueryRow("SELECT COALESCE(cancel_requested, 0) FROM task_runs WHERE id = ?", runID).Scan(&requested)
+	return requested, err
+}
+
 // GetLatestTaskRun retrieves the most recent run for a task
 func (db *DB) GetLatestTaskRun(taskID int64) (*TaskRun, error) {
-	run := &TaskRun{}
-	err := db.conn.QueryRow(`
-		SELECT id, task_id, started_at, ended_at, status, output, error
+	return scanTaskRun(db.conn.QueryRow(`
+		SELECT `+taskRunColumns+`
 		FROM task_runs WHERE task_id = ? ORDER BY started_at DESC LIMIT 1
-	`, taskID).Scan(&run.ID, &run.TaskID, &run.StartedAt, &run.EndedAt, &run.Status, &run.Output, &run.Error)
+	`, taskID))
+}
+
+// GetLastCompletedTaskRun retrieves the most recent successful run for a task
+func (db *DB) GetLastCompletedTaskRun(taskID int64) (*TaskRun, error) {
+	return scanTaskRun(db.conn.QueryRow(`
+		SELECT `+taskRunColumns+`
+		FROM task_runs WHERE task_id = ? AND status = ? ORDER BY started_at DESC LIMIT 1
+	`, taskID, RunStatusCompleted))
+}
+
+// GetTaskRun retrieves a single run by ID
+func (db *DB) GetTaskRun(id int64) (*TaskRun, error) {
+	return scanTaskRun(db.conn.QueryRow(`
+		SELECT `+taskRunColumns+`
+		FROM task_runs WHERE id = ?
+	`, id))
+}
+
+// GetPreviousTaskRun retrieves the run of the same task immediately before the given run
+func (db *DB) GetPreviousTaskRun(taskID, runID int64) (*TaskRun, error) {
+	return scanTaskRun(db.conn.QueryRow(`
+		SELECT `+taskRunColumns+`
+		FROM task_runs WHERE task_id = ? AND id < ? ORDER BY id DESC LIMIT 1
+	`, taskID, runID))
+}
+
+// runDurationMs is the SQL expression for a finished completed/failed run's duration in milliseconds
+const runDurationMs = `CASE WHEN ended_at IS NOT NULL AND status IN ('completed', 'failed')
+	THEN (julianday(ended_at) - julianday(started_at)) * 86400000 END`
+
+// GetTaskRunStats computes duration and success statistics over a task's last limit runs
+func (db *DB) GetTaskRunStats(taskID int64, limit int) (*RunStats, error) {
+	stats := &RunStats{}
+	if err := db.conn.QueryRow("SELECT COUNT(*) FROM task_runs WHERE task_id = ?", taskID).Scan(&stats.TotalRuns); err != nil {
+		return nil, err
+	}
+
+	var minMs, maxMs, avgMs sql.NullFloat64
+	err := db.conn.QueryRow(`
+		SELECT COUNT(*),
+			COALESCE(SUM(status = 'completed'), 0),
+			COALESCE(SUM(status = 'failed'), 0),
+			COALESCE(SUM(status = 'skipped'), 0),
+			MIN(duration_ms), MAX(duration_ms), AVG(duration_ms)
+		FROM (
+			SELECT status, `+runDurationMs+` AS duration_ms
+			FROM task_runs WHERE task_id = ? ORDER BY started_at DESC LIMIT ?
+		)
+	`, taskID, limit).Scan(&stats.SampleSize, &stats.Completed, &stats.Failed, &stats.Skipped, &minMs, &maxMs, &avgMs)
 	if err != nil {
 		return nil, err
 	}
-	return run, nil
+
+	if finished := stats.Completed + stats.Failed; finished > 0 {
+		stats.SuccessRate = float64(stats.Completed) / float64(finished) * 100
+	}
+	stats.MinDuration = msToDuration(minMs)
+	stats.MaxDuration = msToDuration(maxMs)
+	stats.AvgDuration = msToDuration(avgMs)
+
+	// SQLite has no percentile aggregate, so take the nearest-rank p95 from the sorted durations
+	rows, err := db.conn.Query(`
+		SELECT duration_ms FROM (
+			SELECT `+runDurationMs+` AS duration_ms
+			FROM task_runs WHERE task_id = ? ORDER BY started_at DESC LIMIT ?
+		) WHERE duration_ms IS NOT NULL ORDER BY duration_ms
+	`, taskID, limit)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var durations []float64
+	for rows.Next() {
+		var ms float64
+		if err := rows.Scan(&ms); err != nil {
+			return nil, err
+		}
+		durations = append(durations, ms)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	if n := len(durations); n > 0 {
+		rank := int(math.Ceil(0.95*float64(n))) - 1
+		stats.P95Duration = msToDuration(sql.NullFloat64{Float64: durations[rank], Valid: true})
+	}
+
+	return stats, nil
+}
+
+// GetTaskCostEstimate averages cost and tokens over the task's last limit runs
+// that reported usage. Runs without a cost (text output, older runs) are skipped.
+func (db *DB) GetTaskCostEstimate(taskID int64, limit int) (*CostEstimate, error) {
+	est := &CostEstimate{}
+	err := db.conn.QueryRow(`
+		SELECT COUNT(*), COALESCE(AVG(cost_usd), 0), COALESCE(AVG(input_tokens), 0), COALESCE(AVG(output_tokens), 0)
+		FROM (
+			SELECT cost_usd, input_tokens, output_tokens
+			FROM task_runs WHERE task_id = ? AND cost_usd IS NOT NULL ORDER BY started_at DESC LIMIT ?
+		)
+	`, taskID, limit).Scan(&est.SampleSize, &est.AvgCostUSD, &est.AvgInputTokens, &est.AvgOutputTokens)
+	if err != nil {
+		return nil, err
+	}
+	return est, nil
+}
+
+// msToDuration converts a nullable millisecond value to a Duration (0 when NULL)
+func msToDuration(ms sql.NullFloat64) time.Duration {
+	if !ms.Valid {
+		return 0
+	}
+	return time.Duration(math.Round(ms.Float64)) * time.Millisecond
+}
+
+// GetRunSummary counts runs of every task started at or after since
+func (db *DB) GetRunSummary(since time.Time) (*RunSummary, error) {
+	summary := &RunSummary{}
+	err := db.conn.QueryRow(`
+		SELECT COUNT(*),
+			COALESCE(SUM(status = 'completed'), 0),
+			COALESCE(SUM(status = 'failed'), 0),
+			COALESCE(SUM(status = 'skipped'), 0),
+			COALESCE(SUM(status = 'running'), 0)
+		FROM task_runs WHERE started_at >= ?
+	`, since).Scan(&summary.Total, &summary.Completed, &summary.Failed, &summary.Skipped, &summary.Running)
+	if err != nil {
+		return nil, err
+	}
+	return summary, nil
+}
+
+// runCountsQuery computes RunCounts per task; callers append a WHERE or GROUP BY tail
+const runCountsQuery = `
+	SELECT task_id,
+		COALESCE(SUM(status != 'skipped'), 0),
+		COALESCE(SUM(status = 'failed' AND id > COALESCE((
+			SELECT MAX(c.id) FROM task_runs c WHERE c.task_id = r.task_id AND c.status = 'completed'
+		), 0)), 0)
+	FROM task_runs r`
+
+// GetRunCounts retrieves run counts for all tasks that have runs
+func (db *DB) GetRunCounts() (map[int64]RunCounts, error) {
+	rows, err := db.conn.Query(runCountsQuery + " GROUP BY task_id")
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	counts := make(map[int64]RunCounts)
+	for rows.Next() {
+		var taskID int64
+		var c RunCounts
+		if err := rows.Scan(&taskID, &c.RunCount, &c.FailureStreak); err != nil {
+			return nil, err
+		}
+		counts[taskID] = c
+	}
+	return counts, rows.Err()
+}
+
+// GetTaskRunCounts retrieves run counts for one task; zero when it has no runs
+func (db *DB) GetTaskRunCounts(taskID int64) (RunCounts, error) {
+	var c RunCounts
+	var id sql.NullInt64
+	err := db.conn.QueryRow(runCountsQuery+" WHERE task_id = ?", taskID).Scan(&id, &c.RunCount, &c.FailureStreak)
+	return c, err
 }
 
 // GetLastRunStatuses retrieves the last run status for all tasks
@@ -294,3 +1464,43 @@ func (db *DB) GetLastRunStatuses() (map[int64]RunStatus, error) {
 	}
 	return statuses, rows.Err()
 }
+
+// RecordUsageSample stores a usage snapshot
+func (db *DB) RecordUsageSample(sample *UsageSample) error {
+	result, err := db.exec(`
+		INSERT INTO usage_history (sampled_at, five_hour, seven_day)
+		VALUES (?, ?, ?)
+	`, sample.SampledAt, sample.FiveHour, sample.SevenDay)
+	if err != nil {
+		return err
+	}
+
+	id, err := result.LastInsertId()
+	if err != nil {
+		return err
+	}
+	sample.ID = id
+	return nil
+}
+
+// GetUsageHistory retrieves usage samples taken at or after since, oldest first
+func (db *DB) GetUsageHistory(since time.Time) ([]*UsageSample, error) {
+	rows, err := db.conn.Query(`
+		SELECT id, sampled_at, five_hour, seven_day
+		FROM usage_history WHERE sampled_at >= ? ORDER BY sampled_at ASC
+	`, since)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var samples []*UsageSample
+	for rows.Next() {
+		sample := &UsageSample{}
+		if err := rows.Scan(&sample.ID, &sample.SampledAt, &sample.FiveHour, &sample.SevenDay); err != nil {
+			return nil, err
+		}
+		samples = append(samples, sample)
+	}
+	return samples, rows.Err()
+}
@@ -0,0 +1,193 @@
+package db
+
+import (
+	"errors"
+	"fmt"
+	"path/filepath"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+)
+
+// TestConcurrentRunWrites hammers two handles on one file, as the daemon and
+// API server do, and checks that no write fails with "database is locked" and
+// that each task's runs get distinct, gapless seq numbers
+func TestConcurrentRunWrites(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "tasks.db")
+	first, err := New(path)
+	if err != nil {
+		t.Fatalf("opening database: %v", err)
+	}
+	defer first.Close()
+	second, err := New(path)
+	if err != nil {
+		t.Fatalf("opening second handle: %v", err)
+	}
+	defer second.Close()
+
+	var taskIDs []int64
+	for i := 0; i < 2; i++ {
+		task := &Task{Name: fmt.Sprintf("task %d", i), Prompt: "p", CronExpr: "0 * * * * *", WorkingDir: t.TempDir()}
+		if err := first.CreateTask(task); err != nil {
+			t.Fatalf("creating task: %v", err)
+		}
+		taskIDs = append(taskIDs, task.ID)
+	}
+
+	const writers, runsPerWriter = 8, 25
+	var wg sync.WaitGroup
+	errs := make(chan error, writers*runsPerWriter*2)
+	for w := 0; w < writers; w++ {
+		handle := first
+		if w%2 == 1 {
+			handle = second
+		}
+		wg.Add(1)
+		go func(w int, handle *DB) {
+			defer wg.Done()
+			for i := 0; i < runsPerWriter; i++ {
+				run := &TaskRun{TaskID: taskIDs[(w+i)%len(taskIDs)], StartedAt: time.Now(), Status: RunStatusRunning, Trigger: TriggerManual}
+				if err := handle.CreateTaskRun(run); err != nil {
+					errs <- fmt.Errorf("creating run: %w", err)
+					continue
+				}
+				ended := time.Now()
+				run.EndedAt = &ended
+				run.Status = RunStatusCompleted
+				run.Output = strings.Repeat("x", 1024)
+				if err := handle.UpdateTaskRun(run); err != nil {
+					errs <- fmt.Errorf("updating run %d: %w", run.ID, err)
+				}
+			}
+		}(w, handle)
+	}
+	wg.Wait()
+	close(errs)
+
+	for err := range errs {
+		if strings.Contains(err.Error(), "locked") {
+			t.Errorf("write hit a lock: %v", err)
+		} else {
+			t.Errorf("write failed: %v", err)
+		}
+	}
+
+	total := 0
+	for _, taskID := range taskIDs {
+		runs, err := first.GetAllTaskRuns(taskID)
+		if err != nil {
+			t.Fatalf("listing runs: %v", err)
+		}
+		total += len(runs)
+		seen := make(map[int64]bool)
+		for _, run := range runs {
+			if seen[run.Seq] {
+				t.Errorf("task %d: seq %d used twice", taskID, run.Seq)
+			}
+			seen[run.Seq] = true
+			if run.Status != RunStatusCompleted {
+				t.Errorf("task %d run %d: status %q, want completed", taskID, run.ID, run.Status)
+			}
+		}
+		for seq := int64(1); seq <= int64(len(runs)); seq++ {
+			if !seen[seq] {
+				t.Errorf("task %d: seq %d missing", taskID, seq)
+			}
+		}
+	}
+	if total != writers*runsPerWriter {
+		t.Errorf("stored %d runs, want %d", total, writers*runsPerWriter)
+	}
+}
+
+func TestUpdateTaskIfUnchanged(t *testing.T) {
+	database, err := New(filepath.Join(t.TempDir(), "tasks.db"))
+	if err != nil {
+		t.Fatalf("opening database: %v", err)
+	}
+	defer database.Close()
+
+	task := &Task{Name: "task", Prompt: "p", CronExpr: "0 * * * * *", WorkingDir: t.TempDir()}
+	if err := database.CreateTask(task); err != nil {
+		t.Fatalf("creating task: %v", err)
+	}
+
+	first, _ := database.GetTask(task.ID)
+	second, _ := database.GetTask(task.ID)
+	first.Prompt = "first"
+	if err := database.UpdateTaskIfUnchanged(first, first.UpdatedAt); err != nil {
+		t.Fatalf("first update: %v", err)
+	}
+	second.Name = "second"
+	if err := database.UpdateTaskIfUnchanged(second, second.UpdatedAt); !errors.Is(err, ErrTaskModified) {
+		t.Fatalf("stale update returned %v, want ErrTaskModified", err)
+	}
+	stored, _ := database.GetTask(task.ID)
+	if stored.Prompt != "first" || stored.Name != "task" {
+		t.Errorf("stored task = %q/%q, want the first update only", stored.Name, stored.Prompt)
+	}
+
+	// Rows stamped by SQLite's CURRENT_TIMESTAMP must still compare equal
+	if _, err := database.conn.Exec("INSERT INTO tasks (name, prompt, cron_expr, working_dir) VALUES ('raw', 'p', '0 * * * * *', '/tmp')"); err != nil {
+		t.Fatalf("inserting raw task: %v", err)
+	}
+	tasks, err := database.ListTasks()
+	if err != nil {
+		t.Fatalf("listing tasks: %v", err)
+	}
+	for _, raw := range tasks {
+		if raw.Name != "raw" {
+			continue
+		}
+		raw.Prompt = "edited"
+		if err := database.UpdateTaskIfUnchanged(raw, raw.UpdatedAt); err != nil {
+			t.Errorf("updating a CURRENT_TIMESTAMP row: %v", err)
+		}
+	}
+}
+
+func TestUpdateTaskKeepsRuntimeColumns(t *testing.T) {
+	database, err := New(filepath.Join(t.TempDir(), "tasks.db"))
+	if err != nil {
+		t.Fatalf("opening database: %v", err)
+	}
+	defer database.Close()
+
+	task := &Task{Name: "task", Prompt: "p", CronExpr: "0 * * * * *", WorkingDir: t.TempDir(), ResumeSession: true}
+	if err := database.CreateTask(task); err != nil {
+		t.Fatalf("creating task: %v", err)
+	}
+
+	// A run finishes between an editor reading the task and saving it
+	stale, _ := database.GetTask(task.ID)
+	ran := time.Now().Truncate(time.Second)
+	next := ran.Add(time.Hour)
+	if err := database.SetTaskSessionID(task.ID, "session-1"); err != nil {
+		t.Fatal(err)
+	}
+	if err := database.SetTaskLastRunAt(task.ID, ran); err != nil {
+		t.Fatal(err)
+	}
+	if err := database.SetTaskNextRunAt(task.ID, &next); err != nil {
+		t.Fatal(err)
+	}
+
+	stale.Prompt = "edited"
+	if err := database.UpdateTask(stale); err != nil {
+		t.Fatalf("updating task: %v", err)
+	}
+	stored, _ := database.GetTask(task.ID)
+	if stored.Prompt != "edited" {
+		t.Errorf("prompt = %q, want edited", stored.Prompt)
+	}
+	if stored.SessionID != "session-1" {
+		t.Errorf("session_id = %q, want session-1", stored.SessionID)
+	}
+	if stored.LastRunAt == nil || !stored.LastRunAt.Equal(ran) {
+		t.Errorf("last_run_at = %v, want %v", stored.LastRunAt, ran)
+	}
+	if stored.NextRunAt == nil || !stored.NextRunAt.Equal(next) {
+		t.Errorf("next_run_at = %v, want %v", stored.NextRunAt, next)
+	}
+}
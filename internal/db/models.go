@@ -1,22 +1,93 @@
 package db
 
-import "time"
+import (
+	"strings"
+	"time"
+)
 
 // Task represents a scheduled Claude task
 type Task struct {
-	ID             int64      `json:"id"`
-	Name           string     `json:"name"`
-	Prompt         string     `json:"prompt"`
-	CronExpr       string     `json:"cron_expr"`                // Empty for one-off tasks
-	ScheduledAt    *time.Time `json:"scheduled_at,omitempty"`   // When one-off task should run (nil = run immediately)
-	WorkingDir     string     `json:"working_dir"`
-	DiscordWebhook string     `json:"discord_webhook,omitempty"`
-	SlackWebhook   string     `json:"slack_webhook,omitempty"`
-	Enabled        bool       `json:"enabled"`
-	CreatedAt      time.Time  `json:"created_at"`
-	UpdatedAt      time.Time  `json:"updated_at"`
-	LastRunAt      *time.Time `json:"last_run_at,omitempty"`
-	NextRunAt      *time.Time `json:"next_run_at,omitempty"`
+	ID                     int64      `json:"id"`
+	Name                   string     `json:"name"`
+	Prompt                 string     `json:"prompt"`
+	PromptFile             string     `json:"prompt_file,omitempty"`   // Read at run time, relative to WorkingDir; exclusive with Prompt
+	StdinFile              string     `json:"stdin_file,omitempty"`    // Piped to the CLI's stdin at run time, relative to WorkingDir
+	SystemPrompt           string     `json:"system_prompt,omitempty"` // Passed via --append-system-prompt when set
+	OutputFormat           string     `json:"output_format"`           // One of the OutputFormat* constants
+	CronExpr               string     `json:"cron_expr"`               // Empty for one-off tasks
+	ScheduledAt            *time.Time `json:"scheduled_at,omitempty"`  // When one-off task should run (nil = run immediately)
+	WorkingDir             string     `json:"working_dir"`
+	DiscordWebhook         string     `json:"discord_webhook,omitempty"`          // Empty = default_discord_webhook; WebhookOptOut = none
+	SlackWebhook           string     `json:"slack_webhook,omitempty"`            // Empty = default_slack_webhook; WebhookOptOut = none
+	UsageThresholdOverride *float64   `json:"usage_threshold_override,omitempty"` // Replaces the global threshold; nil = use global
+	Tags                   []string   `json:"tags,omitempty"`                     // Normalized via NormalizeTags
+	RunDuringQuietHours    bool       `json:"run_during_quiet_hours"`             // Exempt from the global quiet hours window
+	JitterSeconds          int        `json:"jitter_seconds"`                     // Cron runs are delayed by a random 0..JitterSeconds
+	ActiveFrom             *time.Time `json:"active_from,omitempty"`              // Cron runs before this are skipped; nil = no start bound
+	ActiveUntil            *time.Time `json:"active_until,omitempty"`             // Cron runs after this are skipped and the task is disabled
+	AlertAfterSeconds      int        `json:"alert_after_seconds"`                // Notify once if a run is still going after this long; 0 = off
+	DisableAfterFailures   int        `json:"disable_after_failures"`             // Disable the task after this many consecutive failed runs; 0 = never
+	StripAnsi              bool       `json:"strip_ansi"`                         // Remove ANSI escape sequences from stored output; new tasks default to true
+	IncludePreviousOutput  bool       `json:"include_previous_output"`            // Append the last completed run's output to the prompt
+	TemplatePrompt         bool       `json:"template_prompt"`                    // Expand {{.Date}}-style template actions in the prompt
+	SkipPermissions        bool       `json:"skip_permissions"`                   // Pass --dangerously-skip-permissions; new tasks default to true
+	AllowedTools           string     `json:"allowed_tools,omitempty"`            // Passed as --allowedTools when SkipPermissions is false
+	Container              string     `json:"container,omitempty"`                // Docker image to run the CLI in; empty runs it directly
+	WebhookDetail          string     `json:"webhook_detail,omitempty"`           // One of the WebhookDetail* constants; empty = the global setting
+	ResumeSession          bool       `json:"resume_session"`                     // Continue the same Claude session on every run
+	SessionID              string     `json:"session_id,omitempty"`               // Session the next run resumes; set by the executor
+	Enabled                bool       `json:"enabled"`
+	CreatedAt              time.Time  `json:"created_at"`
+	UpdatedAt              time.Time  `json:"updated_at"`
+	LastRunAt              *time.Time `json:"last_run_at,omitempty"`
+	NextRunAt              *time.Time `json:"next_run_at,omitempty"`
+}
+
+// MaxJitterSeconds caps the per-task cron jitter
+const MaxJitterSeconds = 3600
+
+// MaxAlertAfterSeconds caps the per-task long-run alert threshold
+const MaxAlertAfterSeconds = 86400
+
+// MaxDisableAfterFailures caps the per-task failure streak that disables a task
+const MaxDisableAfterFailures = 1000
+
+// MaxRunNotesLength caps a run annotation, in bytes
+const MaxRunNotesLength = 2000
+
+// Output formats passed to the CLI's --output-format flag
+const (
+	OutputFormatText       = "text"
+	OutputFormatJSON       = "json"
+	OutputFormatStreamJSON = "stream-json"
+)
+
+// ValidOutputFormat reports whether format is a supported output format
+func ValidOutputFormat(format string) bool {
+	switch format {
+	case OutputFormatText, OutputFormatJSON, OutputFormatStreamJSON:
+		return true
+	}
+	return false
+}
+
+// How much of a run webhook result messages include, per task or the webhook_detail setting
+const (
+	WebhookDetailFull    = "full"        // Status, output, and error
+	WebhookDetailSummary = "summary"     // Status and the first line of output and error
+	WebhookDetailStatus  = "status-only" // Status alone
+)
+
+// WebhookOptOut as a task's webhook URL stops it falling back to the default webhook
+const WebhookOptOut = "-"
+
+// ValidWebhookDetail reports whether detail is one of the WebhookDetail* constants
+func ValidWebhookDetail(detail string) bool {
+	switch detail {
+	case WebhookDetailFull, WebhookDetailSummary, WebhookDetailStatus:
+		return true
+	}
+	return false
 }
 
 // IsOneOff returns true if this is a one-off (non-recurring) task
@@ -24,15 +95,185 @@ func (t *Task) IsOneOff() bool {
 	return t.CronExpr == ""
 }
 
+// InactiveReason explains why at falls outside the task's active window; empty means it is inside
+func (t *Task) InactiveReason(at time.Time) string {
+	if t.ActiveFrom != nil && at.Before(*t.ActiveFrom) {
+		return "Not active until " + t.ActiveFrom.Local().Format("2006-01-02 15:04")
+	}
+	if t.WindowEnded(at) {
+		return "Active window ended " + t.ActiveUntil.Local().Format("2006-01-02 15:04")
+	}
+	return ""
+}
+
+// WindowEnded returns true if the task's active window closed before at
+func (t *Task) WindowEnded(at time.Time) bool {
+	return t.ActiveUntil != nil && at.After(*t.ActiveUntil)
+}
+
+// HasTag returns true if the task carries tag (case-insensitive)
+func (t *Task) HasTag(tag string) bool {
+	for _, tg := range t.Tags {
+		if strings.EqualFold(tg, tag) {
+			return true
+		}
+	}
+	return false
+}
+
+// QuietHours is a daily local-time window, with "HH:MM" bounds, during
+// which scheduled runs are skipped. End before Start crosses midnight.
+type QuietHours struct {
+	Start string
+	End   string
+}
+
+// Enabled reports whether both bounds are set
+func (q QuietHours) Enabled() bool {
+	return q.Start != "" && q.End != ""
+}
+
+// Contains reports whether t falls inside the window (start inclusive, end exclusive)
+func (q QuietHours) Contains(t time.Time) bool {
+	if !q.Enabled() {
+		return false
+	}
+	start, err1 := parseClock(q.Start)
+	end, err2 := parseClock(q.End)
+	if err1 != nil || err2 != nil || start == end {
+		return false
+	}
+	now := t.Hour()*60 + t.Minute()
+	if start < end {
+		return now >= start && now < end
+	}
+	return now >= start || now < end
+}
+
+// ValidClock reports whether s is a 24-hour "HH:MM" time
+func ValidClock(s string) bool {
+	_, err := parseClock(s)
+	return err == nil
+}
+
+// parseClock returns minutes since midnight for an "HH:MM" time
+func parseClock(s string) (int, error) {
+	t, err := time.Parse("15:04", s)
+	if err != nil {
+		return 0, err
+	}
+	return t.Hour()*60 + t.Minute(), nil
+}
+
+// NormalizeTags trims, lowercases, and de-duplicates tags, dropping empty ones
+func NormalizeTags(tags []string) []string {
+	var out []string
+	seen := make(map[string]bool)
+	for _, tag := range tags {
+		tag = strings.ToLower(strings.TrimSpace(tag))
+		if tag == "" || seen[tag] {
+			continue
+		}
+		seen[tag] = true
+		out = append(out, tag)
+	}
+	return out
+}
+
+// ValidTag reports whether a normalized tag is acceptable: at most 32
+// characters with no spaces or commas (tags are entered comma-separated)
+func ValidTag(tag string) bool {
+	return tag != "" && len(tag) <= 32 && !strings.ContainsAny(tag, ", \t")
+}
+
+// ValidContainer reports whether image looks like a Docker image reference. It
+// must not start with "-" so it can't be read as a docker run flag.
+func ValidContainer(image string) bool {
+	return image != "" && len(image) <= 255 && !strings.HasPrefix(image, "-") &&
+		!strings.ContainsAny(image, " \t\n")
+}
+
 // TaskRun represents an execution of a task
 type TaskRun struct {
-	ID        int64      `json:"id"`
-	TaskID    int64      `json:"task_id"`
-	StartedAt time.Time  `json:"started_at"`
-	EndedAt   *time.Time `json:"ended_at,omitempty"`
-	Status    RunStatus  `json:"status"`
-	Output    string     `json:"output"`
-	Error     string     `json:"error,omitempty"`
+	ID           int64      `json:"id"`
+	TaskID       int64      `json:"task_id"`
+	Seq          int64      `json:"seq"` // Numbers the task's runs from 1, assigned by CreateTaskRun
+	StartedAt    time.Time  `json:"started_at"`
+	EndedAt      *time.Time `json:"ended_at,omitempty"`
+	Status       RunStatus  `json:"status"`
+	Output       string     `json:"output"`
+	Error        string     `json:"error,omitempty"`
+	WebhookError string     `json:"webhook_error,omitempty"` // Set when notification delivery failed after all retries
+	Trigger      RunTrigger `json:"trigger,omitempty"`       // What started the run; empty for runs recorded before triggers existed
+	OutputPath   string     `json:"output_path,omitempty"`   // Log file holding the full output; the DB column then keeps a preview
+	CostUSD      *float64   `json:"cost_usd,omitempty"`      // From the JSON result event; nil for text output or older runs
+	InputTokens  int64      `json:"input_tokens,omitempty"`  // Includes cache reads and writes
+	OutputTokens int64      `json:"output_tokens,omitempty"`
+	Notes        string     `json:"notes,omitempty"`   // Reviewer's annotation, set via SetTaskRunNotes
+	Warning      string     `json:"warning,omitempty"` // Set when a run succeeded but its output couldn't be parsed
+}
+
+// Where run output is persisted, per the log_storage setting
+const (
+	LogStorageDB   = "db"   // Full output in the task_runs.output column
+	LogStorageFile = "file" // Full output in logs/<taskid>/<runid>.log, preview in the DB
+)
+
+// OutputPreviewLen is how much of a file-backed run's output is kept in the DB
+const OutputPreviewLen = 2000
+
+// RunStats summarizes a task's recent runs
+type RunStats struct {
+	TotalRuns   int     // All runs ever recorded for the task
+	SampleSize  int     // Most recent runs the remaining fields cover
+	Completed   int     // Completed runs in the sample
+	Failed      int     // Failed runs in the sample
+	Skipped     int     // Skipped runs in the sample
+	SuccessRate float64 // Completed / (Completed + Failed) as 0-100; 0 when neither
+	// Durations cover finished completed/failed runs in the sample
+	MinDuration time.Duration
+	MaxDuration time.Duration
+	AvgDuration time.Duration
+	P95Duration time.Duration
+}
+
+// CostEstimate averages the reported cost and token usage of a task's recent runs
+type CostEstimate struct {
+	SampleSize      int // Recent runs that reported usage
+	AvgCostUSD      float64
+	AvgInputTokens  float64
+	AvgOutputTokens float64
+}
+
+// RunCounts summarizes a task's whole run history for dashboards
+type RunCounts struct {
+	RunCount      int // Runs that weren't skipped, including one in progress
+	FailureStreak int // Failed runs since the last completed one; skipped runs don't break it
+}
+
+// RunSummary counts runs across all tasks over a time window
+type RunSummary struct {
+	Total     int
+	Completed int
+	Failed    int
+	Skipped   int
+	Running   int
+}
+
+// SuccessRate returns Completed / (Completed + Failed) as 0-100; 0 when neither
+func (s *RunSummary) SuccessRate() float64 {
+	if finished := s.Completed + s.Failed; finished > 0 {
+		return float64(s.Completed) / float64(finished) * 100
+	}
+	return 0
+}
+
+// UsageSample is a point-in-time snapshot of API usage
+type UsageSample struct {
+	ID        int64     `json:"id"`
+	SampledAt time.Time `json:"sampled_at"`
+	FiveHour  float64   `json:"five_hour"`
+	SevenDay  float64   `json:"seven_day"`
 }
 
 // RunStatus represents the status of a task run
@@ -43,4 +284,17 @@ const (
 	RunStatusRunning   RunStatus = "running"
 	RunStatusCompleted RunStatus = "completed"
 	RunStatusFailed    RunStatus = "failed"
+	RunStatusSkipped   RunStatus = "skipped" // Not executed (e.g. usage above threshold)
+)
+
+// RunTrigger records which code path started a task run. There is no
+// "dependency" trigger yet because tasks can't depend on one another; add it
+// alongside task dependencies.
+type RunTrigger string
+
+const (
+	TriggerCron   RunTrigger = "cron"   // Fired by the task's cron schedule
+	TriggerOneOff RunTrigger = "oneoff" // One-off task reaching its scheduled time
+	TriggerManual RunTrigger = "manual" // "Run now" from the TUI
+	TriggerAPI    RunTrigger = "api"    // POST /api/v1/tasks/{id}/run
 )
@@ -0,0 +1,197 @@
+package db
+
+import (
+	"crypto/aes"
+	"crypto/cipher"
+	"crypto/rand"
+	"crypto/sha256"
+	"database/sql"
+	"encoding/base64"
+	"fmt"
+	"strings"
+)
+
+// EncryptionKeyEnv names the environment variable holding the key used to encrypt
+// webhook URLs at rest. Any string works; it is hashed into an AES-256 key.
+const EncryptionKeyEnv = "CLAUDE_TASKS_ENCRYPTION_KEY"
+
+// encryptedPrefix marks a column value sealed by sealSecret
+const encryptedPrefix = "enc:v1:"
+
+// newSecretCipher returns the AES-GCM cipher for key, or nil when key is empty
+func newSecretCipher(key string) (cipher.AEAD, error) {
+	if key == "" {
+		return nil, nil
+	}
+	sum := sha256.Sum256([]byte(key))
+	block, err := aes.NewCipher(sum[:])
+	if err != nil {
+		return nil, err
+	}
+	return cipher.NewGCM(block)
+}
+
+// EncryptionEnabled reports whether an encryption key was configured
+func (db *DB) EncryptionEnabled() bool {
+	return db.aead != nil
+}
+
+// sealSecret encrypts value for storage. Without a key, empty, and already
+// encrypted values are returned unchanged.
+func (db *DB) sealSecret(value string) (string, error) {
+	if db.aead == nil || value == "" || strings.HasPrefix(value, encryptedPrefix) {
+		return value, nil
+	}
+	nonce := make([]byte, db.aead.NonceSize())
+	if _, err := rand.Read(nonce); err != nil {
+		return "", fmt.Errorf("generating nonce: %w", err)
+	}
+	sealed := db.aead.Seal(nonce, nonce, []byte(value), nil)
+	return encryptedPrefix + base64.StdEncoding.EncodeToString(sealed), nil
+}
+
+// openSecret decrypts a value written by sealSecret; plaintext values pass through
+func (db *DB) openSecret(stored string) (string, error) {
+	encoded, ok := strings.CutPrefix(stored, encryptedPrefix)
+	if !ok {
+		return stored, nil
+	}
+	if db.aead == nil {
+		return "", fmt.Errorf("value is encrypted; set %s", EncryptionKeyEnv)
+	}
+	sealed, err := base64.StdEncoding.DecodeString(encoded)
+	if err != nil || len(sealed) < db.aead.NonceSize() {
+		return "", fmt.Errorf("malformed encrypted value")
+	}
+	nonce, ciphertext := sealed[:db.aead.NonceSize()], sealed[db.aead.NonceSize():]
+	plain, err := db.aead.Open(nil, nonce, ciphertext, nil)
+	if err != nil {
+		return "", fmt.Errorf("decryption failed; is %s correct?", EncryptionKeyEnv)
+	}
+	return string(plain), nil
+}
+
+// sealWebhooks returns the task's webhook URLs as they should be stored
+func (db *DB) sealWebhooks(task *Task) (discord, slack string, err error) {
+	if discord, err = db.sealSecret(task.DiscordWebhook); err != nil {
+		return "", "", err
+	}
+	if slack, err = db.sealSecret(task.SlackWebhook); err != nil {
+		return "", "", err
+	}
+	return discord, slack, nil
+}
+
+// openWebhooks decrypts the task's webhook URLs in place after a read
+func (db *DB) openWebhooks(task *Task) error {
+	var err error
+	if task.DiscordWebhook, err = db.openSecret(task.DiscordWebhook); err != nil {
+		return fmt.Errorf("task %d discord_webhook: %w", task.ID, err)
+	}
+	if task.SlackWebhook, err = db.openSecret(task.SlackWebhook); err != nil {
+		return fmt.Errorf("task %d slack_webhook: %w", task.ID, err)
+	}
+	return nil
+}
+
+// secretSettings are the settings stored with sealSecret
+var secretSettings = []string{"default_discord_webhook", "default_slack_webhook"}
+
+// getSecretSetting reads a setting written by setSecretSetting; unset is empty
+func (db *DB) getSecretSetting(key string) (string, error) {
+	val, err := db.GetSetting(key)
+	if err != nil {
+		return "", nil
+	}
+	plain, err := db.openSecret(val)
+	if err != nil {
+		return "", fmt.Errorf("setting %s: %w", key, err)
+	}
+	return plain, nil
+}
+
+// setSecretSetting stores a setting encrypted when a key is configured
+func (db *DB) setSecretSetting(key, value string) error {
+	sealed, err := db.sealSecret(value)
+	if err != nil {
+		return err
+	}
+	return db.SetSetting(key, sealed)
+}
+
+// EncryptSecrets encrypts webhook URLs still stored in plaintext, e.g. after a
+// key is first configured, and returns how many tasks and settings were rewritten
+func (db *DB) EncryptSecrets() (int, error) {
+	if db.aead == nil {
+		return 0, fmt.Errorf("%s is not set", EncryptionKeyEnv)
+	}
+
+	tx, err := db.conn.Begin()
+	if err != nil {
+		return 0, err
+	}
+	defer tx.Rollback()
+
+	type secrets struct {
+		id             int64
+		discord, slack string
+	}
+	rows, err := tx.Query("SELECT id, COALESCE(discord_webhook, ''), COALESCE(slack_webhook, '') FROM tasks")
+	if err != nil {
+		return 0, err
+	}
+	var pending []secrets
+	for rows.Next() {
+		var s secrets
+		if err := rows.Scan(&s.id, &s.discord, &s.slack); err != nil {
+			rows.Close()
+			return 0, err
+		}
+		pending = append(pending, s)
+	}
+	rows.Close()
+	if err := rows.Err(); err != nil {
+		return 0, err
+	}
+
+	changed := 0
+	for _, s := range pending {
+		discord, err := db.sealSecret(s.discord)
+		if err != nil {
+			return 0, err
+		}
+		slack, err := db.sealSecret(s.slack)
+		if err != nil {
+			return 0, err
+		}
+		if discord == s.discord && slack == s.slack {
+			continue
+		}
+		if _, err := tx.Exec("UPDATE tasks SET discord_webhook = ?, slack_webhook = ? WHERE id = ?", discord, slack, s.id); err != nil {
+			return 0, err
+		}
+		changed++
+	}
+
+	for _, key := range secretSettings {
+		var val string
+		if err := tx.QueryRow("SELECT value FROM settings WHERE key = ?", key).Scan(&val); err != nil {
+			if err == sql.ErrNoRows {
+				continue
+			}
+			return 0, err
+		}
+		sealed, err := db.sealSecret(val)
+		if err != nil {
+			return 0, err
+		}
+		if sealed == val {
+			continue
+		}
+		if _, err := tx.Exec("UPDATE settings SET value = ? WHERE key = ?", sealed, key); err != nil {
+			return 0, err
+		}
+		changed++
+	}
+	return changed, tx.Commit()
+}
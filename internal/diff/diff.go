@@ -0,0 +1,22 @@
+package diff
+
+import (
+	"fmt"
+
+	"github.com/kylemclaren/claude-tasks/internal/db"
+	"github.com/pmezard/go-difflib/difflib"
+)
+
+// Runs returns a unified diff of two runs' outputs, from the older run to the newer one.
+// An empty string means the outputs are identical.
+func Runs(from, to *db.TaskRun) (string, error) {
+	return difflib.GetUnifiedDiffString(difflib.UnifiedDiff{
+		A:        difflib.SplitLines(from.Output),
+		B:        difflib.SplitLines(to.Output),
+		FromFile: fmt.Sprintf("run %d", from.ID),
+		FromDate: from.StartedAt.Format("2006-01-02 15:04:05"),
+		ToFile:   fmt.Sprintf("run %d", to.ID),
+		ToDate:   to.StartedAt.Format("2006-01-02 15:04:05"),
+		Context:  3,
+	})
+}
@@ -0,0 +1,50 @@
+package executor
+
+import (
+	"bytes"
+	"io"
+	"sync"
+)
+
+// echoMu keeps lines from concurrent runs from interleaving on the echo writer
+var echoMu sync.Mutex
+
+// lineWriter copies output to w a line at a time, prefixing each line
+type lineWriter struct {
+	w       io.Writer
+	prefix  []byte
+	pending []byte // Partial line waiting for its newline
+}
+
+func newLineWriter(w io.Writer, prefix string) *lineWriter {
+	return &lineWriter{w: w, prefix: []byte(prefix)}
+}
+
+// Write echoes each complete line in p; errors from w are ignored so a broken
+// console never fails the run
+func (l *lineWriter) Write(p []byte) (int, error) {
+	l.pending = append(l.pending, p...)
+	for {
+		i := bytes.IndexByte(l.pending, '\n')
+		if i < 0 {
+			break
+		}
+		l.emit(l.pending[:i+1])
+		l.pending = l.pending[i+1:]
+	}
+	return len(p), nil
+}
+
+// Flush echoes a final line that had no trailing newline
+func (l *lineWriter) Flush() {
+	if len(l.pending) > 0 {
+		l.emit(append(l.pending, '\n'))
+		l.pending = nil
+	}
+}
+
+func (l *lineWriter) emit(line []byte) {
+	echoMu.Lock()
+	defer echoMu.Unlock()
+	_, _ = l.w.Write(append(append([]byte(nil), l.prefix...), line...))
+}
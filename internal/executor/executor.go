@@ -3,10 +3,22 @@ package executor
 import (
 	"bytes"
 	"context"
+	"crypto/rand"
+	"encoding/json"
+	"errors"
 	"fmt"
+	"io"
+	"os"
 	"os/exec"
+	"path/filepath"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"syscall"
+	"text/template"
 	"time"
 
+	"github.com/charmbracelet/x/ansi"
 	"github.com/kylemclaren/claude-tasks/internal/db"
 	"github.com/kylemclaren/claude-tasks/internal/usage"
 	"github.com/kylemclaren/claude-tasks/internal/webhook"
@@ -17,19 +29,30 @@ type Executor struct {
 	db          *db.DB
 	discord     *webhook.Discord
 	slack       *webhook.Slack
-	usageClient *usage.Client
+	usageClient atomic.Pointer[usage.Client] // nil when credentials weren't found
+	noUsage     bool                         // Set by DisableUsageCheck; overrides the usage_check_enabled setting
+	echo        io.Writer                    // Set by EchoOutput; receives run output as it is produced
+
+	// Every run goes through the queue so max_concurrent_runs holds for all triggers
+	queue    chan job
+	slotMu   sync.Mutex
+	slotFree *sync.Cond
+	inFlight int
+	waiting  int // Dequeued jobs blocked in acquireSlot
 }
 
 // New creates a new executor
 func New(database *db.DB) *Executor {
-	usageClient, _ := usage.NewClient() // Ignore error, will be nil if credentials not found
-
-	return &Executor{
-		db:          database,
-		discord:     webhook.NewDiscord(),
-		slack:       webhook.NewSlack(),
-		usageClient: usageClient,
+	e := &Executor{
+		db:      database,
+		discord: webhook.NewDiscord(),
+		slack:   webhook.NewSlack(),
+		queue:   make(chan job, QueueSize),
 	}
+	e.slotFree = sync.NewCond(&e.slotMu)
+	e.ReloadUsageClient()
+	go e.dispatch()
+	return e
 }
 
 // Result represents the result of a task execution
@@ -41,14 +64,31 @@ type Result struct {
 	SkipReason string
 }
 
-// Execute runs a Claude CLI command for the given task
-func (e *Executor) Execute(ctx context.Context, task *db.Task) *Result {
+// Execute runs a Claude CLI command for the given task, recording trigger on the run
+func (e *Executor) Execute(ctx context.Context, task *db.Task, trigger db.RunTrigger) *Result {
 	startTime := time.Now()
 
+	// Skip cron-fired runs outside the task's active window
+	if trigger == db.TriggerCron {
+		if reason := task.InactiveReason(startTime); reason != "" {
+			return e.skipRun(task, trigger, startTime, reason)
+		}
+	}
+
+	// Skip cron-fired runs inside the quiet hours window unless the task opts out
+	if trigger == db.TriggerCron && !task.RunDuringQuietHours {
+		if quiet, _ := e.db.GetQuietHours(); quiet.Contains(startTime) {
+			return e.skipRun(task, trigger, startTime, fmt.Sprintf("Quiet hours (%s-%s)", quiet.Start, quiet.End))
+		}
+	}
+
 	// Check usage threshold before running
-	if e.usageClient != nil {
+	if e.usageCheckEnabled() {
 		threshold, _ := e.db.GetUsageThreshold()
-		ok, usageData, err := e.usageClient.CheckThreshold(threshold)
+		if task.UsageThresholdOverride != nil {
+			threshold = *task.UsageThresholdOverride
+		}
+		ok, usageData, err := e.usageClient.Load().CheckThreshold(threshold)
 		if err == nil && !ok {
 			// Usage is above threshold, skip the task
 			skipReason := fmt.Sprintf("Usage above threshold (%.0f%%): 5h=%.0f%%, 7d=%.0f%%. Resets in %s",
@@ -56,23 +96,7 @@ func (e *Executor) Execute(ctx context.Context, task *db.Task) *Result {
 				usageData.FiveHour.Utilization,
 				usageData.SevenDay.Utilization,
 				usageData.FormatTimeUntilReset())
-
-			// Create a skipped run record
-			run := &db.TaskRun{
-				TaskID:    task.ID,
-				StartedAt: startTime,
-				Status:    db.RunStatusFailed,
-				Error:     skipReason,
-			}
-			endTime := time.Now()
-			run.EndedAt = &endTime
-			_ = e.db.CreateTaskRun(run)
-
-			return &Result{
-				Skipped:    true,
-				SkipReason: skipReason,
-				Duration:   time.Since(startTime),
-			}
+			return e.skipRun(task, trigger, startTime, skipReason)
 		}
 	}
 
@@ -81,47 +105,123 @@ func (e *Executor) Execute(ctx context.Context, task *db.Task) *Result {
 		TaskID:    task.ID,
 		StartedAt: startTime,
 		Status:    db.RunStatusRunning,
+		Trigger:   trigger,
 	}
 	if err := e.db.CreateTaskRun(run); err != nil {
 		return &Result{Error: fmt.Errorf("failed to create run record: %w", err)}
 	}
 
-	// Build and execute command
-	// -p enables print mode (non-interactive), prompt is positional arg
-	// --dangerously-skip-permissions bypasses permission prompts for scheduled tasks
-	cmd := exec.CommandContext(ctx, "claude", "-p", "--dangerously-skip-permissions", task.Prompt)
+	if err := checkTaskPaths(e.db, task); err != nil {
+		return e.failRun(task, run, err)
+	}
+
+	// Resolve the prompt, reading it from disk for file-backed tasks
+	prompt, err := loadPrompt(task)
+	if err != nil {
+		return e.failRun(task, run, err)
+	}
+	prompt, err = renderPrompt(task, prompt, startTime)
+	if err != nil {
+		return e.failRun(task, run, err)
+	}
+	if task.IncludePreviousOutput {
+		prompt = e.appendPreviousOutput(task, prompt)
+	}
+
+	session, flags := sessionArgs(task)
+	name, args, err := e.command(task, prompt, flags)
+	if err != nil {
+		return e.failRun(task, run, err)
+	}
+
+	// Build and execute command, stopping it if the run is cancelled
+	ctx, cancel := context.WithCancel(ctx)
+	defer cancel()
+	cancelled := e.watchCancel(ctx, run.ID, cancel)
+	cmd := exec.CommandContext(ctx, name, args...)
 	cmd.Dir = task.WorkingDir
+	cmd.WaitDelay = killWaitDelay
+	if task.Container != "" {
+		// docker run forwards SIGTERM to the container; a killed client would leave it running
+		cmd.Cancel = func() error { return cmd.Process.Signal(syscall.SIGTERM) }
+	}
+
+	// Pipe the task's stdin file, if any, to the CLI
+	if task.StdinFile != "" {
+		stdin, err := os.Open(ResolvePath(task.WorkingDir, task.StdinFile))
+		if err != nil {
+			return e.failRun(task, run, fmt.Errorf("failed to open stdin file: %w", err))
+		}
+		defer stdin.Close()
+		cmd.Stdin = stdin
+	}
 
 	var stdout, stderr bytes.Buffer
 	cmd.Stdout = &stdout
 	cmd.Stderr = &stderr
+	flushEcho := func() {}
+	if e.echo != nil {
+		echoOut := newLineWriter(e.echo, "["+task.Name+"] ")
+		echoErr := newLineWriter(e.echo, "["+task.Name+"] stderr: ")
+		cmd.Stdout = io.MultiWriter(&stdout, echoOut)
+		cmd.Stderr = io.MultiWriter(&stderr, echoErr)
+		flushEcho = func() { echoOut.Flush(); echoErr.Flush() }
+	}
 
-	err := cmd.Run()
+	stopAlert := e.startLongRunAlert(task, run)
+	err = cmd.Run()
+	stopAlert()
+	flushEcho()
 	endTime := time.Now()
 	duration := endTime.Sub(startTime)
 
 	// Update run record
 	run.EndedAt = &endTime
 	run.Output = stdout.String()
-	if err != nil {
+	if task.StripAnsi {
+		run.Output = ansi.Strip(run.Output)
+	}
+	if storage, _ := e.db.GetLogStorage(); storage == db.LogStorageFile {
+		if err := e.db.WriteRunLog(run); err != nil {
+			fmt.Printf("Task %d: %v; keeping output in the database\n", task.ID, err)
+		}
+	}
+	// A zero exit can still carry an error in the JSON result event
+	event := findResultEvent(task.OutputFormat, run.Output)
+	reported, isError := reportedError(event)
+	recordUsage(run, event)
+	if err == nil {
+		run.Warning = formatWarning(task.OutputFormat, event)
+	}
+	updateSession(task, session, event, err != nil && strings.Contains(stderr.String(), expiredSessionMessage))
+	switch {
+	case err != nil && cancelled():
+		err = errors.New(cancelledError)
+		run.Status = db.RunStatusFailed
+		run.Error = cancelledError
+	case err != nil:
 		run.Status = db.RunStatusFailed
 		run.Error = fmt.Sprintf("%s\n%s", err.Error(), stderr.String())
-	} else {
+	case isError:
+		run.Status = db.RunStatusFailed
+		run.Error = reported
+	default:
 		run.Status = db.RunStatusCompleted
 	}
-	_ = e.db.UpdateTaskRun(run)
+	logDBError(task.ID, "saving run", e.db.UpdateTaskRun(run))
 
-	// Update task's last run time
+	// Update task's last run time and session. Only these columns are written,
+	// since task is the snapshot taken when the run was queued.
 	task.LastRunAt = &endTime
-	_ = e.db.UpdateTask(task)
+	logDBError(task.ID, "saving last run time", e.db.SetTaskLastRunAt(task.ID, endTime))
+	if task.ResumeSession {
+		logDBError(task.ID, "saving session", e.db.SetTaskSessionID(task.ID, task.SessionID))
+	}
 
 	// Send webhook notifications if configured
-	if task.DiscordWebhook != "" {
-		_ = e.discord.SendResult(task.DiscordWebhook, task, run)
-	}
-	if task.SlackWebhook != "" {
-		_ = e.slack.SendResult(task.SlackWebhook, task, run)
-	}
+	e.notify(task, run)
+	e.disableIfFailing(task, run)
+	e.pruneRuns(task)
 
 	result := &Result{
 		Output:   stdout.String(),
@@ -129,19 +229,643 @@ func (e *Executor) Execute(ctx context.Context, task *db.Task) *Result {
 	}
 	if err != nil {
 		result.Error = fmt.Errorf("%s: %s", err.Error(), stderr.String())
+	} else if isError {
+		result.Error = errors.New(reported)
 	}
 
 	return result
 }
 
-// ExecuteAsync runs a task asynchronously
-func (e *Executor) ExecuteAsync(task *db.Task) <-chan *Result {
-	ch := make(chan *Result, 1)
+// cancelPollInterval is how often a run checks whether cancellation was requested
+const cancelPollInterval = time.Second
+
+// killWaitDelay bounds how long a killed run waits for tools the CLI spawned to close its output
+const killWaitDelay = 5 * time.Second
+
+// cancelledError is recorded on runs stopped via db.RequestRunCancel
+const cancelledError = "Cancelled"
+
+// watchCancel calls cancel once cancellation of the run is requested, which may
+// come from another process. It stops when ctx is done; the returned func
+// reports whether the run was cancelled.
+func (e *Executor) watchCancel(ctx context.Context, runID int64, cancel context.CancelFunc) func() bool {
+	var requested atomic.Bool
 	go func() {
-		ctx, cancel := context.WithTimeout(context.Background(), 30*time.Minute)
-		defer cancel()
-		ch <- e.Execute(ctx, task)
-		close(ch)
+		ticker := time.NewTicker(cancelPollInterval)
+		defer ticker.Stop()
+		for {
+			select {
+			case <-ctx.Done():
+				return
+			case <-ticker.C:
+				if ok, _ := e.db.RunCancelRequested(runID); ok {
+					requested.Store(true)
+					cancel()
+					return
+				}
+			}
+		}
 	}()
+	return requested.Load
+}
+
+// startLongRunAlert arms a one-time notification for runs that outlive the
+// task's AlertAfterSeconds. The returned func cancels it once the run ends.
+func (e *Executor) startLongRunAlert(task *db.Task, run *db.TaskRun) func() {
+	if task.AlertAfterSeconds <= 0 {
+		return func() {}
+	}
+	// The timer goroutine gets copies; the originals are updated once the run ends
+	taskCopy, runCopy := *task, *run
+	after := time.Duration(task.AlertAfterSeconds) * time.Second
+	timer := time.AfterFunc(after, func() {
+		message := fmt.Sprintf("still running after %s", after)
+		fmt.Printf("Task %d %s\n", taskCopy.ID, message)
+		e.alert(&taskCopy, &runCopy, message)
+	})
+	return func() { timer.Stop() }
+}
+
+// alert sends message to the task's webhooks; failures are logged, not recorded on the run
+func (e *Executor) alert(task *db.Task, run *db.TaskRun, message string) {
+	discord, slack := e.webhooks(task)
+	if discord == "" && slack == "" {
+		return
+	}
+
+	e.loadWebhookConfig()
+
+	if discord != "" {
+		if err := e.discord.SendAlert(discord, task, run, message); err != nil {
+			fmt.Printf("Task %d: discord alert failed: %v\n", task.ID, err)
+		}
+	}
+	if slack != "" {
+		if err := e.slack.SendAlert(slack, task, run, message); err != nil {
+			fmt.Printf("Task %d: slack alert failed: %v\n", task.ID, err)
+		}
+	}
+}
+
+// ErrNoWebhooks is returned by Report for tasks that notify no webhooks
+var ErrNoWebhooks = errors.New("task has no webhooks configured")
+
+// Report sends a digest of the task's last n runs to its webhooks. The digest is
+// returned even when a delivery fails so callers can show what was sent.
+func (e *Executor) Report(task *db.Task, n int) (*webhook.Digest, error) {
+	discord, slack := e.webhooks(task)
+	if discord == "" && slack == "" {
+		return nil, ErrNoWebhooks
+	}
+
+	stats, err := e.db.GetTaskRunStats(task.ID, n)
+	if err != nil {
+		return nil, fmt.Errorf("computing run stats: %w", err)
+	}
+	runs, err := e.db.GetTaskRuns(task.ID, n)
+	if err != nil {
+		return nil, fmt.Errorf("loading runs: %w", err)
+	}
+	digest := &webhook.Digest{Stats: stats}
+	for _, run := range runs {
+		if run.Status == db.RunStatusFailed {
+			digest.LastFailure = run
+			break
+		}
+	}
+
+	e.loadWebhookConfig()
+
+	var failures []string
+	if discord != "" {
+		if err := e.discord.SendDigest(discord, task, digest); err != nil {
+			failures = append(failures, "discord: "+err.Error())
+		}
+	}
+	if slack != "" {
+		if err := e.slack.SendDigest(slack, task, digest); err != nil {
+			failures = append(failures, "slack: "+err.Error())
+		}
+	}
+	if len(failures) > 0 {
+		return digest, errors.New(strings.Join(failures, "; "))
+	}
+	return digest, nil
+}
+
+// resultEvent is the final event printed by --output-format json and stream-json
+type resultEvent struct {
+	Type         string   `json:"type"`
+	Subtype      string   `json:"subtype"`
+	SessionID    string   `json:"session_id"`
+	IsError      bool     `json:"is_error"`
+	Result       string   `json:"result"`
+	TotalCostUSD *float64 `json:"total_cost_usd"`
+	Usage        struct {
+		InputTokens              int64 `json:"input_tokens"`
+		CacheCreationInputTokens int64 `json:"cache_creation_input_tokens"`
+		CacheReadInputTokens     int64 `json:"cache_read_input_tokens"`
+		OutputTokens             int64 `json:"output_tokens"`
+	} `json:"usage"`
+}
+
+// findResultEvent returns the result event in the output, or nil if there is none.
+// Text output has no result event, so only the JSON formats are inspected.
+func findResultEvent(format, output string) *resultEvent {
+	var lines []string
+	switch format {
+	case db.OutputFormatJSON:
+		lines = []string{output}
+	case db.OutputFormatStreamJSON:
+		lines = strings.Split(strings.TrimSpace(output), "\n")
+	default:
+		return nil
+	}
+
+	// The result event comes last, so search from the end
+	for i := len(lines) - 1; i >= 0; i-- {
+		var event resultEvent
+		if json.Unmarshal([]byte(lines[i]), &event) == nil && event.Type == "result" {
+			return &event
+		}
+	}
+	return nil
+}
+
+// formatWarning explains a successful JSON run with no result event, which
+// usually means the CLI's output schema changed. The raw output is kept on
+// the run as usual, so it can be inspected.
+func formatWarning(format string, event *resultEvent) string {
+	if event != nil || (format != db.OutputFormatJSON && format != db.OutputFormatStreamJSON) {
+		return ""
+	}
+	return "no result event parsed from " + format + " output; the CLI output format may have changed"
+}
+
+// reportedError returns the error Claude reported in its result event, if any
+func reportedError(event *resultEvent) (string, bool) {
+	if event == nil || !event.IsError {
+		return "", false
+	}
+	if event.Result != "" {
+		return event.Result, true
+	}
+	return "Claude reported an error (" + event.Subtype + ")", true
+}
+
+// recordUsage copies the cost and token counts from the result event onto the run
+func recordUsage(run *db.TaskRun, event *resultEvent) {
+	if event == nil || event.TotalCostUSD == nil {
+		return
+	}
+	run.CostUSD = event.TotalCostUSD
+	run.InputTokens = event.Usage.InputTokens + event.Usage.CacheCreationInputTokens + event.Usage.CacheReadInputTokens
+	run.OutputTokens = event.Usage.OutputTokens
+}
+
+// expiredSessionMessage is what the CLI prints when --resume names a session it no longer has
+const expiredSessionMessage = "No conversation found"
+
+// sessionArgs returns the session a resumable task's run continues and the
+// flags selecting it. A task without a session yet is given a new ID, so the
+// first run can be resumed whatever its output format.
+func sessionArgs(task *db.Task) (string, []string) {
+	if !task.ResumeSession {
+		return "", nil
+	}
+	if task.SessionID != "" {
+		return task.SessionID, []string{"--resume", task.SessionID}
+	}
+	id := newSessionID()
+	return id, []string{"--session-id", id}
+}
+
+// updateSession records the session the next run should resume. The JSON
+// result event reports the ID actually used; an expired session is dropped
+// so the next run starts a new one.
+func updateSession(task *db.Task, session string, event *resultEvent, expired bool) {
+	if !task.ResumeSession {
+		return
+	}
+	switch {
+	case expired:
+		task.SessionID = ""
+	case event != nil && event.SessionID != "":
+		task.SessionID = event.SessionID
+	default:
+		task.SessionID = session
+	}
+}
+
+// newSessionID returns a random (version 4) UUID, the form --session-id requires
+func newSessionID() string {
+	var b [16]byte
+	_, _ = rand.Read(b[:])
+	b[6] = b[6]&0x0f | 0x40
+	b[8] = b[8]&0x3f | 0x80
+	return fmt.Sprintf("%x-%x-%x-%x-%x", b[0:4], b[4:6], b[6:8], b[8:10], b[10:16])
+}
+
+// DisableUsageCheck turns off usage fetching and threshold enforcement for this
+// executor regardless of the usage_check_enabled setting. Call before running tasks.
+func (e *Executor) DisableUsageCheck() {
+	e.noUsage = true
+}
+
+// EchoOutput copies the output of every run to w line by line, each line
+// prefixed with the task name. Call before running tasks.
+func (e *Executor) EchoOutput(w io.Writer) {
+	e.echo = w
+}
+
+// ReloadUsageClient re-reads the usage API credentials, e.g. after a fresh login
+func (e *Executor) ReloadUsageClient() {
+	client, _ := usage.NewClient() // Ignore error, will be nil if credentials not found
+	e.usageClient.Store(client)
+}
+
+// usageCheckEnabled reports whether runs should be gated on the usage threshold
+func (e *Executor) usageCheckEnabled() bool {
+	if e.usageClient.Load() == nil || e.noUsage {
+		return false
+	}
+	enabled, _ := e.db.GetUsageCheckEnabled()
+	return enabled
+}
+
+// skipRun records a skipped run with the reason in its error field
+func (e *Executor) skipRun(task *db.Task, trigger db.RunTrigger, startTime time.Time, reason string) *Result {
+	run := &db.TaskRun{
+		TaskID:    task.ID,
+		StartedAt: startTime,
+		Status:    db.RunStatusSkipped,
+		Error:     reason,
+		Trigger:   trigger,
+	}
+	endTime := time.Now()
+	run.EndedAt = &endTime
+	logDBError(task.ID, "saving skipped run", e.db.CreateTaskRun(run))
+	e.pruneRuns(task)
+
+	return &Result{
+		Skipped:    true,
+		SkipReason: reason,
+		Duration:   time.Since(startTime),
+	}
+}
+
+// failRun records a run that failed before the CLI was started
+func (e *Executor) failRun(task *db.Task, run *db.TaskRun, err error) *Result {
+	endTime := time.Now()
+	run.EndedAt = &endTime
+	run.Status = db.RunStatusFailed
+	run.Error = err.Error()
+	logDBError(task.ID, "saving run", e.db.UpdateTaskRun(run))
+
+	task.LastRunAt = &endTime
+	logDBError(task.ID, "saving last run time", e.db.SetTaskLastRunAt(task.ID, endTime))
+
+	e.notify(task, run)
+	e.disableIfFailing(task, run)
+	e.pruneRuns(task)
+
+	return &Result{Error: err, Duration: endTime.Sub(run.StartedAt)}
+}
+
+// disableIfFailing disables the task once a failed run completes a streak of
+// DisableAfterFailures, and alerts its webhooks. The scheduler skips disabled
+// tasks, so no further cron runs start.
+func (e *Executor) disableIfFailing(task *db.Task, run *db.TaskRun) {
+	if task.DisableAfterFailures <= 0 || run.Status != db.RunStatusFailed || !task.Enabled {
+		return
+	}
+	counts, err := e.db.GetTaskRunCounts(task.ID)
+	if err != nil {
+		fmt.Printf("Task %d: checking failure streak failed: %v\n", task.ID, err)
+		return
+	}
+	streak := counts.FailureStreak
+	if streak < task.DisableAfterFailures {
+		return
+	}
+
+	task.Enabled = false
+	task.NextRunAt = nil
+	logDBError(task.ID, "disabling task", e.db.DisableTask(task.ID))
+	message := fmt.Sprintf("auto-disabled after %d consecutive failures", streak)
+	fmt.Printf("Task %d %s\n", task.ID, message)
+	e.alert(task, run, message)
+}
+
+// pruneRuns trims the task's run history to the max_runs_per_task setting
+func (e *Executor) pruneRuns(task *db.Task) {
+	keep, _ := e.db.GetMaxRunsPerTask()
+	if keep <= 0 {
+		return
+	}
+	if _, err := e.db.PruneRunsByCount(task.ID, keep); err != nil {
+		fmt.Printf("Task %d: pruning runs failed: %v\n", task.ID, err)
+	}
+}
+
+// logDBError reports a failed write that would otherwise leave the run's
+// record stale without any trace
+func logDBError(taskID int64, action string, err error) {
+	if err != nil {
+		fmt.Printf("Task %d: %s failed: %v\n", taskID, action, err)
+	}
+}
+
+// loadWebhookConfig applies the current notification settings to both senders
+func (e *Executor) loadWebhookConfig() {
+	attempts, _ := e.db.GetWebhookRetryAttempts()
+	timeout, _ := e.db.GetWebhookTimeoutSeconds()
+	baseURL, _ := e.db.GetPublicBaseURL()
+	detail, _ := e.db.GetWebhookDetail()
+	discordLimit, _ := e.db.GetDiscordOutputLimit()
+	slackLimit, _ := e.db.GetSlackOutputLimit()
+	preview, _ := e.db.GetListPreviewLength()
+	relaxed, _ := e.db.GetRelaxedWebhookURLs()
+	cfg := &webhook.Config{
+		Attempts:           attempts,
+		Timeout:            time.Duration(timeout) * time.Second,
+		PublicBaseURL:      baseURL,
+		Detail:             detail,
+		DiscordOutputLimit: discordLimit,
+		SlackOutputLimit:   slackLimit,
+		PreviewLength:      preview,
+		RelaxedURLs:        relaxed,
+	}
+	e.discord.SetConfig(cfg)
+	e.slack.SetConfig(cfg)
+}
+
+// webhooks returns the URLs a task notifies: its own, else the default
+// webhooks, or none for WebhookOptOut
+func (e *Executor) webhooks(task *db.Task) (discord, slack string) {
+	discord, slack = task.DiscordWebhook, task.SlackWebhook
+	if discord == "" {
+		var err error
+		if discord, err = e.db.GetDefaultDiscordWebhook(); err != nil {
+			fmt.Printf("Task %d: reading default discord webhook: %v\n", task.ID, err)
+		}
+	}
+	if slack == "" {
+		var err error
+		if slack, err = e.db.GetDefaultSlackWebhook(); err != nil {
+			fmt.Printf("Task %d: reading default slack webhook: %v\n", task.ID, err)
+		}
+	}
+	if discord == db.WebhookOptOut {
+		discord = ""
+	}
+	if slack == db.WebhookOptOut {
+		slack = ""
+	}
+	return discord, slack
+}
+
+// notify sends the run result to the task's webhooks, recording any delivery
+// failure on the run so it's visible even when the task itself succeeded
+func (e *Executor) notify(task *db.Task, run *db.TaskRun) {
+	discord, slack := e.webhooks(task)
+	if discord == "" && slack == "" {
+		return
+	}
+
+	e.loadWebhookConfig()
+
+	var failures []string
+	if discord != "" {
+		if err := e.discord.SendResult(discord, task, run); err != nil {
+			failures = append(failures, "discord: "+err.Error())
+		}
+	}
+	if slack != "" {
+		if err := e.slack.SendResult(slack, task, run); err != nil {
+			failures = append(failures, "slack: "+err.Error())
+		}
+	}
+
+	if len(failures) > 0 {
+		run.WebhookError = strings.Join(failures, "; ")
+		logDBError(task.ID, "saving webhook error", e.db.UpdateTaskRun(run))
+	}
+}
+
+// LookupClaude resolves the Claude CLI from the claude_binary setting, returning
+// an actionable error when it isn't installed or the configured path is wrong
+func LookupClaude(database *db.DB) (string, error) {
+	binary, _ := database.GetClaudeBinary()
+	path, err := exec.LookPath(binary)
+	if err != nil {
+		if binary == db.DefaultClaudeBinary {
+			return "", fmt.Errorf("claude CLI not found on PATH; install it or set claude_binary in the config file")
+		}
+		return "", fmt.Errorf("claude CLI not found at %q; check claude_binary in the config file", binary)
+	}
+	return path, nil
+}
+
+// LookupDocker finds the docker CLI used to run tasks that set a container
+func LookupDocker() (string, error) {
+	path, err := exec.LookPath("docker")
+	if err != nil {
+		return "", fmt.Errorf("docker not found on PATH; it is required to run tasks in a container")
+	}
+	return path, nil
+}
+
+// command returns the program and arguments for a run: the Claude CLI itself,
+// or docker running it inside the task's container image
+func (e *Executor) command(task *db.Task, prompt string, extra []string) (string, []string, error) {
+	args := buildArgs(task, prompt, extra...)
+	if task.Container == "" {
+		claude, err := LookupClaude(e.db)
+		return claude, args, err
+	}
+	docker, err := LookupDocker()
+	if err != nil {
+		return "", nil, err
+	}
+	return docker, containerArgs(task, args), nil
+}
+
+// containerArgs wraps CLI arguments in a docker run that mounts the working
+// directory at the same path. The image must provide claude on its PATH.
+func containerArgs(task *db.Task, claudeArgs []string) []string {
+	args := []string{"run", "--rm", "--init"}
+	if task.StdinFile != "" {
+		args = append(args, "-i") // Forward the piped stdin file
+	}
+	args = append(args, "-v", task.WorkingDir+":"+task.WorkingDir, "-w", task.WorkingDir, task.Container, "claude")
+	return append(args, claudeArgs...)
+}
+
+// CheckAllowedPath rejects paths outside the allowed_working_dirs prefixes, when configured
+func CheckAllowedPath(database *db.DB, path string) error {
+	allowed, err := database.GetAllowedWorkingDirs()
+	if err != nil {
+		return err
+	}
+	if len(allowed) == 0 {
+		return nil
+	}
+
+	abs, err := filepath.Abs(path)
+	if err != nil {
+		return fmt.Errorf("invalid path: %s", path)
+	}
+	for _, prefix := range allowed {
+		if isWithin(filepath.Clean(prefix), abs) {
+			return nil
+		}
+	}
+	return fmt.Errorf("path is outside allowed working directories: %s", abs)
+}
+
+// isWithin reports whether path is dir or lies beneath it; both must be absolute and clean
+func isWithin(dir, path string) bool {
+	rel, err := filepath.Rel(dir, path)
+	if err != nil {
+		return false
+	}
+	return rel != ".." && !strings.HasPrefix(rel, ".."+string(filepath.Separator))
+}
+
+// checkTaskPaths applies CheckAllowedPath to every path a run touches, so tasks
+// saved before the allowlist was narrowed can't run outside it
+func checkTaskPaths(database *db.DB, task *db.Task) error {
+	paths := []string{task.WorkingDir}
+	if task.PromptFile != "" {
+		paths = append(paths, ResolvePath(task.WorkingDir, task.PromptFile))
+	}
+	if task.StdinFile != "" {
+		paths = append(paths, ResolvePath(task.WorkingDir, task.StdinFile))
+	}
+	for _, path := range paths {
+		if err := CheckAllowedPath(database, path); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// ResolvePath resolves path relative to a task's working directory
+func ResolvePath(workingDir, path string) string {
+	if filepath.IsAbs(path) {
+		return path
+	}
+	return filepath.Join(workingDir, path)
+}
+
+// loadPrompt returns the task's prompt, reading PromptFile at run time if set
+func loadPrompt(task *db.Task) (string, error) {
+	if task.PromptFile == "" {
+		return task.Prompt, nil
+	}
+	data, err := os.ReadFile(ResolvePath(task.WorkingDir, task.PromptFile))
+	if err != nil {
+		return "", fmt.Errorf("failed to read prompt file: %w", err)
+	}
+	return string(data), nil
+}
+
+// PromptData holds the variables available to prompt templates
+type PromptData struct {
+	Date       string // Current date, 2006-01-02
+	Time       string // Current time, 15:04
+	TaskName   string
+	WorkingDir string
+	LastRunAt  string // RFC3339 end of the previous run; empty if never run
+}
+
+// renderPrompt expands text/template actions such as {{.Date}} in a prompt.
+// Only tasks with TemplatePrompt set are expanded, since prompts about Go,
+// Helm, or Jinja code often contain {{ }} meant literally.
+func renderPrompt(task *db.Task, prompt string, now time.Time) (string, error) {
+	if !task.TemplatePrompt || !strings.Contains(prompt, "{{") {
+		return prompt, nil
+	}
+	tmpl, err := template.New(task.Name).Parse(prompt)
+	if err != nil {
+		return "", fmt.Errorf("invalid prompt template: %w", err)
+	}
+	data := PromptData{
+		Date:       now.Format("2006-01-02"),
+		Time:       now.Format("15:04"),
+		TaskName:   task.Name,
+		WorkingDir: task.WorkingDir,
+	}
+	if task.LastRunAt != nil {
+		data.LastRunAt = task.LastRunAt.Format(time.RFC3339)
+	}
+	var b strings.Builder
+	if err := tmpl.Execute(&b, data); err != nil {
+		return "", fmt.Errorf("invalid prompt template: %w", err)
+	}
+	return b.String(), nil
+}
+
+// MaxPreviousOutputBytes caps how much of the previous run's output is added to the prompt
+const MaxPreviousOutputBytes = 16 * 1024
+
+// appendPreviousOutput adds the end of the task's last completed run to prompt.
+// The prompt is returned unchanged when there is no such run or it produced no output.
+func (e *Executor) appendPreviousOutput(task *db.Task, prompt string) string {
+	prev, err := e.db.GetLastCompletedTaskRun(task.ID)
+	if err != nil {
+		return prompt
+	}
+	output, truncated, err := e.db.GetTaskRunOutputTail(task.ID, prev.ID, 0, MaxPreviousOutputBytes)
+	if err != nil || strings.TrimSpace(output) == "" {
+		return prompt
+	}
+	header := fmt.Sprintf("Output of the previous run (%s):", prev.StartedAt.Format(time.RFC3339))
+	if truncated {
+		header = fmt.Sprintf("Output of the previous run (%s, last %d bytes):", prev.StartedAt.Format(time.RFC3339), MaxPreviousOutputBytes)
+	}
+	return prompt + "\n\n" + header + "\n<previous_output>\n" + strings.TrimRight(output, "\n") + "\n</previous_output>"
+}
+
+// buildArgs returns the Claude CLI arguments for a task, with extra flags
+// placed before the prompt
+func buildArgs(task *db.Task, prompt string, extra ...string) []string {
+	// -p enables print mode (non-interactive), prompt is positional arg
+	args := []string{"-p"}
+	// --dangerously-skip-permissions bypasses permission prompts for scheduled tasks;
+	// without it, tools outside --allowedTools are denied since nobody can approve them
+	if task.SkipPermissions {
+		args = append(args, "--dangerously-skip-permissions")
+	} else if task.AllowedTools != "" {
+		// The flag is variadic; the = form keeps it from swallowing the prompt
+		args = append(args, "--allowedTools="+task.AllowedTools)
+	}
+	if task.SystemPrompt != "" {
+		args = append(args, "--append-system-prompt", task.SystemPrompt)
+	}
+	// Text is the CLI default; stream-json requires --verbose in print mode
+	switch task.OutputFormat {
+	case db.OutputFormatJSON:
+		args = append(args, "--output-format", db.OutputFormatJSON)
+	case db.OutputFormatStreamJSON:
+		args = append(args, "--output-format", db.OutputFormatStreamJSON, "--verbose")
+	}
+	args = append(args, extra...)
+	// Prompt must remain the final positional argument
+	return append(args, prompt)
+}
+
+// ExecuteAsync queues a task to run once a slot under the max_concurrent_runs
+// setting is free. Runs start in the order they were queued; when the queue is
+// full the run is recorded as skipped instead.
+func (e *Executor) ExecuteAsync(task *db.Task, trigger db.RunTrigger) <-chan *Result {
+	ch := make(chan *Result, 1)
+	select {
+	case e.queue <- job{task: task, trigger: trigger, result: ch}:
+	default:
+		ch <- e.skipRun(task, trigger, time.Now(), fmt.Sprintf("Run queue full (%d waiting)", QueueSize))
+		close(ch)
+	}
 	return ch
 }
@@ -0,0 +1,74 @@
+package executor
+
+import (
+	"context"
+	"time"
+
+	"github.com/kylemclaren/claude-tasks/internal/db"
+)
+
+// QueueSize is how many runs can wait for a free slot; runs queued beyond it are skipped
+const QueueSize = 100
+
+// runTimeout bounds a single run, measured from when it leaves the queue
+const runTimeout = 30 * time.Minute
+
+// job is a queued execution and the channel its result is delivered on
+type job struct {
+	task    *db.Task
+	trigger db.RunTrigger
+	result  chan *Result
+}
+
+// dispatch starts queued jobs in order, each once a slot is free
+func (e *Executor) dispatch() {
+	for j := range e.queue {
+		e.acquireSlot()
+		go func() {
+			defer e.releaseSlot()
+			ctx, cancel := context.WithTimeout(context.Background(), runTimeout)
+			defer cancel()
+			j.result <- e.Execute(ctx, j.task, j.trigger)
+			close(j.result)
+		}()
+	}
+}
+
+// acquireSlot blocks until fewer than max_concurrent_runs jobs are in flight.
+// The limit is re-read on each wake-up, so a raised limit applies once
+// LimitChanged is called or a run finishes.
+func (e *Executor) acquireSlot() {
+	e.slotMu.Lock()
+	defer e.slotMu.Unlock()
+	for {
+		limit, _ := e.db.GetMaxConcurrentRuns()
+		if limit <= 0 || e.inFlight < limit {
+			break
+		}
+		e.waiting++
+		e.slotFree.Wait()
+		e.waiting--
+	}
+	e.inFlight++
+}
+
+func (e *Executor) releaseSlot() {
+	e.slotMu.Lock()
+	e.inFlight--
+	e.slotMu.Unlock()
+	e.slotFree.Signal()
+}
+
+// LimitChanged wakes the dispatcher to re-read max_concurrent_runs, so a
+// raised limit starts waiting runs without waiting for one to finish
+func (e *Executor) LimitChanged() {
+	e.slotFree.Broadcast()
+}
+
+// Queued returns how many runs are waiting for a slot, including the one
+// the dispatcher holds while it waits
+func (e *Executor) Queued() int {
+	e.slotMu.Lock()
+	defer e.slotMu.Unlock()
+	return len(e.queue) + e.waiting
+}
@@ -0,0 +1,105 @@
+package executor
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/kylemclaren/claude-tasks/internal/db"
+)
+
+// newQueueExecutor returns an executor whose claude is a script that blocks
+// until release is called, and a task for it to run
+func newQueueExecutor(t *testing.T, limit int) (*Executor, *db.Task, func()) {
+	t.Helper()
+	dir := t.TempDir()
+	database, err := db.New(filepath.Join(dir, "tasks.db"))
+	if err != nil {
+		t.Fatalf("opening database: %v", err)
+	}
+	t.Cleanup(func() { database.Close() })
+
+	gate := filepath.Join(dir, "release")
+	script := filepath.Join(dir, "claude")
+	body := "#!/bin/sh\nwhile [ ! -f '" + gate + "' ]; do sleep 0.01; done\necho done\n"
+	if err := os.WriteFile(script, []byte(body), 0755); err != nil {
+		t.Fatal(err)
+	}
+	if err := database.SetClaudeBinary(script); err != nil {
+		t.Fatal(err)
+	}
+	if err := database.SetMaxConcurrentRuns(limit); err != nil {
+		t.Fatal(err)
+	}
+
+	task := &db.Task{Name: "queued", Prompt: "p", CronExpr: "0 * * * * *", WorkingDir: dir}
+	if err := database.CreateTask(task); err != nil {
+		t.Fatalf("creating task: %v", err)
+	}
+
+	e := New(database)
+	e.DisableUsageCheck()
+	release := func() { os.WriteFile(gate, nil, 0644) }
+	t.Cleanup(release)
+	return e, task, release
+}
+
+// waitQueued polls until e.Queued() reports want
+func waitQueued(t *testing.T, e *Executor, want int) {
+	t.Helper()
+	deadline := time.Now().Add(5 * time.Second)
+	for e.Queued() != want {
+		if time.Now().After(deadline) {
+			t.Fatalf("Queued() = %d, want %d", e.Queued(), want)
+		}
+		time.Sleep(5 * time.Millisecond)
+	}
+}
+
+func TestQueueHoldsRunsBeyondLimit(t *testing.T) {
+	e, task, release := newQueueExecutor(t, 1)
+
+	var results []<-chan *Result
+	for i := 0; i < 3; i++ {
+		results = append(results, e.ExecuteAsync(task, db.TriggerManual))
+	}
+
+	// One run holds the slot; the dispatcher waits with the second and the third is in the channel
+	waitQueued(t, e, 2)
+
+	release()
+	for i, ch := range results {
+		select {
+		case r := <-ch:
+			if r.Error != nil || r.Skipped {
+				t.Errorf("run %d: error=%v skipped=%v", i, r.Error, r.Skipped)
+			}
+		case <-time.After(10 * time.Second):
+			t.Fatalf("run %d never finished", i)
+		}
+	}
+	waitQueued(t, e, 0)
+}
+
+func TestRaisedLimitStartsWaitingRuns(t *testing.T) {
+	e, task, release := newQueueExecutor(t, 1)
+
+	var results []<-chan *Result
+	for i := 0; i < 3; i++ {
+		results = append(results, e.ExecuteAsync(task, db.TriggerManual))
+	}
+	waitQueued(t, e, 2)
+
+	// Nothing finishes, so only LimitChanged can wake the dispatcher
+	if err := e.db.SetMaxConcurrentRuns(3); err != nil {
+		t.Fatal(err)
+	}
+	e.LimitChanged()
+	waitQueued(t, e, 0)
+
+	release()
+	for _, ch := range results {
+		<-ch
+	}
+}
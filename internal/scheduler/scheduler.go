@@ -2,11 +2,16 @@ package scheduler
 
 import (
 	"fmt"
+	"io"
+	"math/rand/v2"
+	"sort"
 	"sync"
 	"time"
 
+	"github.com/kylemclaren/claude-tasks/internal/cronexpr"
 	"github.com/kylemclaren/claude-tasks/internal/db"
 	"github.com/kylemclaren/claude-tasks/internal/executor"
+	"github.com/kylemclaren/claude-tasks/internal/usage"
 	"github.com/robfig/cron/v3"
 )
 
@@ -16,23 +21,30 @@ type Scheduler struct {
 	db           *db.DB
 	executor     *executor.Executor
 	jobs         map[int64]cron.EntryID
-	cronExprs    map[int64]string       // Track cron expressions to detect changes
-	oneOffTimers map[int64]*time.Timer  // Track one-off task timers
+	cronExprs    map[int64]string      // Track cron expressions to detect changes
+	oneOffTimers map[int64]*time.Timer // Track one-off task timers
+	oneOffTimes  map[int64]time.Time   // When each one-off timer fires
+	active       map[int64]int         // Executions started by the scheduler that haven't finished
 	mu           sync.RWMutex
 	running      bool
+	noUsage      bool // Set by DisableUsageCheck
 	stopSync     chan struct{}
+	syncNow      chan struct{} // Signalled by TriggerSync
 }
 
 // New creates a new scheduler
 func New(database *db.DB) *Scheduler {
 	return &Scheduler{
-		cron:         cron.New(cron.WithSeconds()),
+		cron:         cron.New(cron.WithParser(cronexpr.Parser)),
 		db:           database,
 		executor:     executor.New(database),
 		jobs:         make(map[int64]cron.EntryID),
 		cronExprs:    make(map[int64]string),
 		oneOffTimers: make(map[int64]*time.Timer),
+		oneOffTimes:  make(map[int64]time.Time),
+		active:       make(map[int64]int),
 		stopSync:     make(chan struct{}),
+		syncNow:      make(chan struct{}, 1),
 	}
 }
 
@@ -66,6 +78,9 @@ func (s *Scheduler) Start() error {
 	// Start background sync to pick up DB changes
 	go s.syncLoop()
 
+	// Record usage over time for trend charts
+	go s.usageHistoryLoop()
+
 	return nil
 }
 
@@ -83,6 +98,7 @@ func (s *Scheduler) Stop() {
 		timer.Stop()
 	}
 	s.oneOffTimers = make(map[int64]*time.Timer)
+	s.oneOffTimes = make(map[int64]time.Time)
 
 	s.mu.Unlock()
 
@@ -93,6 +109,27 @@ func (s *Scheduler) Stop() {
 	<-ctx.Done()
 }
 
+// disableExpiredTask disables a task whose active window has ended and removes its job
+func (s *Scheduler) disableExpiredTask(taskID int64) {
+	_ = s.db.DisableTask(taskID)
+	s.RemoveTask(taskID)
+}
+
+// waitJitter sleeps for a random 0..JitterSeconds so tasks sharing a schedule
+// don't all start at once. It returns false if the scheduler stopped meanwhile.
+func (s *Scheduler) waitJitter(task *db.Task) bool {
+	if task.JitterSeconds <= 0 {
+		return true
+	}
+	delay := time.Duration(rand.IntN(task.JitterSeconds+1)) * time.Second
+	select {
+	case <-time.After(delay):
+		return true
+	case <-s.stopSync:
+		return false
+	}
+}
+
 // AddTask schedules a new task
 func (s *Scheduler) AddTask(task *db.Task) error {
 	s.mu.Lock()
@@ -114,10 +151,16 @@ func (s *Scheduler) RemoveTask(taskID int64) {
 	}
 
 	// Cancel one-off timer if exists
+	s.cancelOneOffLocked(taskID)
+}
+
+// cancelOneOffLocked stops a task's one-off timer, if any; caller must hold s.mu
+func (s *Scheduler) cancelOneOffLocked(taskID int64) {
 	if timer, ok := s.oneOffTimers[taskID]; ok {
 		timer.Stop()
 		delete(s.oneOffTimers, taskID)
 	}
+	delete(s.oneOffTimes, taskID)
 }
 
 // UpdateTask updates a task's schedule
@@ -142,12 +185,9 @@ func (s *Scheduler) GetNextRunTime(taskID int64) *time.Time {
 		}
 	}
 
-	// Check one-off tasks (return from DB since timer doesn't expose time)
-	if _, ok := s.oneOffTimers[taskID]; ok {
-		task, err := s.db.GetTask(taskID)
-		if err == nil && task.NextRunAt != nil {
-			return task.NextRunAt
-		}
+	// Check one-off tasks
+	if next, ok := s.oneOffTimes[taskID]; ok {
+		return &next
 	}
 
 	return nil
@@ -158,6 +198,11 @@ func (s *Scheduler) GetAllNextRunTimes() map[int64]time.Time {
 	s.mu.RLock()
 	defer s.mu.RUnlock()
 
+	return s.nextRunTimesLocked()
+}
+
+// nextRunTimesLocked computes next run times; caller must hold s.mu
+func (s *Scheduler) nextRunTimesLocked() map[int64]time.Time {
 	result := make(map[int64]time.Time)
 
 	// Get cron job next runs
@@ -168,15 +213,94 @@ func (s *Scheduler) GetAllNextRunTimes() map[int64]time.Time {
 		}
 	}
 
-	// Get one-off task next runs from DB
+	// One-off timers fire at the time they were armed for
+	for taskID, next := range s.oneOffTimes {
+		result[taskID] = next
+	}
+
+	return result
+}
+
+// JobStatus describes a task as currently loaded in the scheduler
+type JobStatus struct {
+	TaskID    int64
+	EntryID   cron.EntryID // Zero for one-off timers
+	Kind      string       // "cron" or "oneoff"
+	CronExpr  string
+	NextRunAt *time.Time
+	Running   bool
+}
+
+// Status is a point-in-time snapshot of the scheduler's internal state
+type Status struct {
+	Running bool
+	Jobs    []JobStatus
+	Queued  int // Runs waiting for a max_concurrent_runs slot
+}
+
+// Status snapshots the loaded cron jobs and one-off timers
+func (s *Scheduler) Status() Status {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	nextRuns := s.nextRunTimesLocked()
+	status := Status{
+		Running: s.running,
+		Jobs:    make([]JobStatus, 0, len(s.jobs)+len(s.oneOffTimers)),
+		Queued:  s.executor.Queued(),
+	}
+
+	for taskID, entryID := range s.jobs {
+		job := JobStatus{
+			TaskID:   taskID,
+			EntryID:  entryID,
+			Kind:     "cron",
+			CronExpr: s.cronExprs[taskID],
+			Running:  s.active[taskID] > 0,
+		}
+		if next, ok := nextRuns[taskID]; ok {
+			job.NextRunAt = &next
+		}
+		status.Jobs = append(status.Jobs, job)
+	}
+
 	for taskID := range s.oneOffTimers {
-		task, err := s.db.GetTask(taskID)
-		if err == nil && task.NextRunAt != nil {
-			result[taskID] = *task.NextRunAt
+		job := JobStatus{
+			TaskID:  taskID,
+			Kind:    "oneoff",
+			Running: s.active[taskID] > 0,
+		}
+		if next, ok := nextRuns[taskID]; ok {
+			job.NextRunAt = &next
 		}
+		status.Jobs = append(status.Jobs, job)
 	}
 
-	return result
+	sort.Slice(status.Jobs, func(i, j int) bool {
+		return status.Jobs[i].TaskID < status.Jobs[j].TaskID
+	})
+
+	return status
+}
+
+// execute runs a task through the executor, tracking it as active until it finishes.
+// The executor gets its own copy, so callers may keep using task.
+func (s *Scheduler) execute(task *db.Task, trigger db.RunTrigger) {
+	s.mu.Lock()
+	s.active[task.ID]++
+	s.mu.Unlock()
+
+	queued := *task
+	done := s.executor.ExecuteAsync(&queued, trigger)
+	go func() {
+		<-done
+		s.mu.Lock()
+		s.active[task.ID]--
+		if s.active[task.ID] <= 0 {
+			delete(s.active, task.ID)
+		}
+		s.mu.Unlock()
+	}()
 }
 
 func (s *Scheduler) scheduleTaskLocked(task *db.Task) error {
@@ -204,15 +328,23 @@ func (s *Scheduler) scheduleTaskLocked(task *db.Task) error {
 		if !freshTask.Enabled {
 			return
 		}
-		s.executor.ExecuteAsync(freshTask)
+		if !s.waitJitter(freshTask) {
+			return
+		}
+		s.execute(freshTask, db.TriggerCron)
+
+		// Past the active window: the skip is recorded, now stop scheduling it
+		if freshTask.WindowEnded(time.Now()) {
+			s.disableExpiredTask(taskID)
+			return
+		}
 
 		// Update next run time in DB after execution
 		s.mu.RLock()
 		if eid, ok := s.jobs[taskID]; ok {
 			entry := s.cron.Entry(eid)
 			if !entry.Next.IsZero() {
-				freshTask.NextRunAt = &entry.Next
-				_ = s.db.UpdateTask(freshTask)
+				_ = s.db.SetTaskNextRunAt(taskID, &entry.Next)
 			}
 		}
 		s.mu.RUnlock()
@@ -228,7 +360,7 @@ func (s *Scheduler) scheduleTaskLocked(task *db.Task) error {
 	entry := s.cron.Entry(entryID)
 	if !entry.Next.IsZero() {
 		task.NextRunAt = &entry.Next
-		_ = s.db.UpdateTask(task)
+		_ = s.db.SetTaskNextRunAt(task.ID, task.NextRunAt)
 	}
 
 	return nil
@@ -237,10 +369,7 @@ func (s *Scheduler) scheduleTaskLocked(task *db.Task) error {
 // scheduleOneOffTaskLocked schedules a one-off task
 func (s *Scheduler) scheduleOneOffTaskLocked(task *db.Task) error {
 	// Cancel existing timer if any
-	if timer, ok := s.oneOffTimers[task.ID]; ok {
-		timer.Stop()
-		delete(s.oneOffTimers, task.ID)
-	}
+	s.cancelOneOffLocked(task.ID)
 
 	taskID := task.ID
 
@@ -262,10 +391,11 @@ func (s *Scheduler) scheduleOneOffTaskLocked(task *db.Task) error {
 		s.executeOneOff(taskID)
 	})
 	s.oneOffTimers[task.ID] = timer
+	s.oneOffTimes[task.ID] = *task.ScheduledAt
 
 	// Update NextRunAt in DB
 	task.NextRunAt = task.ScheduledAt
-	_ = s.db.UpdateTask(task)
+	_ = s.db.SetTaskNextRunAt(task.ID, task.NextRunAt)
 
 	return nil
 }
@@ -283,19 +413,24 @@ func (s *Scheduler) executeOneOff(taskID int64) {
 	}
 
 	// Execute the task
-	s.executor.ExecuteAsync(task)
+	s.execute(task, db.TriggerOneOff)
 
 	// Auto-disable the task after execution
-	task.Enabled = false
-	task.NextRunAt = nil
-	_ = s.db.UpdateTask(task)
+	_ = s.db.DisableTask(taskID)
 
 	// Clean up timer reference
 	s.mu.Lock()
 	delete(s.oneOffTimers, taskID)
+	delete(s.oneOffTimes, taskID)
 	s.mu.Unlock()
 }
 
+// Execute queues task for an immediate run started by trigger. Runs from the
+// API go through here too, so Status reports them like scheduled ones.
+func (s *Scheduler) Execute(task *db.Task, trigger db.RunTrigger) {
+	s.execute(task, trigger)
+}
+
 // RunTaskNow executes a task immediately
 func (s *Scheduler) RunTaskNow(taskID int64) error {
 	task, err := s.db.GetTask(taskID)
@@ -303,24 +438,114 @@ func (s *Scheduler) RunTaskNow(taskID int64) error {
 		return fmt.Errorf("task not found: %w", err)
 	}
 
-	go func() {
-		s.executor.ExecuteAsync(task)
-	}()
+	s.execute(task, db.TriggerManual)
 
 	return nil
 }
 
-// syncLoop periodically syncs tasks from DB
+// syncLoop periodically syncs tasks from DB. The interval is re-read from
+// settings after every sync so changes take effect without a restart.
 func (s *Scheduler) syncLoop() {
-	ticker := time.NewTicker(10 * time.Second)
+	timer := time.NewTimer(s.syncInterval())
+	defer timer.Stop()
+
+	for {
+		select {
+		case <-s.stopSync:
+			return
+		case <-timer.C:
+		case <-s.syncNow:
+			timer.Stop()
+		}
+		s.SyncTasks()
+		timer.Reset(s.syncInterval())
+	}
+}
+
+// syncInterval returns the configured DB sync interval
+func (s *Scheduler) syncInterval() time.Duration {
+	seconds, _ := s.db.GetSyncIntervalSeconds()
+	return time.Duration(seconds) * time.Second
+}
+
+// TriggerSync asks the sync loop to reload tasks from the DB right away
+// instead of waiting for the next interval. It never blocks; triggers that
+// arrive while a sync is already pending are coalesced.
+func (s *Scheduler) TriggerSync() {
+	select {
+	case s.syncNow <- struct{}{}:
+	default:
+	}
+}
+
+// Reload picks up DB changes immediately: tasks are re-synced and usage
+// credentials re-read. Other settings are already read at each use.
+func (s *Scheduler) Reload() {
+	s.executor.ReloadUsageClient()
+	s.executor.LimitChanged()
+	s.SyncTasks()
+}
+
+// Executor returns the executor that runs this scheduler's tasks. Other
+// triggers in the same process should share it so max_concurrent_runs and
+// the run queue cover them too.
+func (s *Scheduler) Executor() *executor.Executor {
+	return s.executor
+}
+
+// DisableUsageCheck turns off usage sampling and threshold enforcement for this
+// scheduler regardless of the usage_check_enabled setting. Call before Start.
+func (s *Scheduler) DisableUsageCheck() {
+	s.noUsage = true
+	s.executor.DisableUsageCheck()
+}
+
+// EchoOutput prints the output of this scheduler's runs to w as they execute.
+// Call before Start.
+func (s *Scheduler) EchoOutput(w io.Writer) {
+	s.executor.EchoOutput(w)
+}
+
+// UsageCheckEnabled reports whether usage is fetched and the threshold enforced
+func (s *Scheduler) UsageCheckEnabled() bool {
+	if s.noUsage {
+		return false
+	}
+	enabled, _ := s.db.GetUsageCheckEnabled()
+	return enabled
+}
+
+// usageSampleInterval is how often usage is recorded to usage_history
+const usageSampleInterval = 5 * time.Minute
+
+// usageHistoryLoop periodically samples API usage into the DB
+func (s *Scheduler) usageHistoryLoop() {
+	if s.noUsage {
+		return
+	}
+	client, err := usage.NewClient()
+	if err != nil {
+		return // No credentials, nothing to sample
+	}
+
+	ticker := time.NewTicker(usageSampleInterval)
 	defer ticker.Stop()
 
 	for {
+		if s.UsageCheckEnabled() {
+			if data, err := client.Fetch(); err == nil {
+				_ = s.db.RecordUsageSample(&db.UsageSample{
+					SampledAt: time.Now(),
+					FiveHour:  data.FiveHour.Utilization,
+					SevenDay:  data.SevenDay.Utilization,
+				})
+			}
+		}
+
 		select {
 		case <-s.stopSync:
 			return
 		case <-ticker.C:
-			s.SyncTasks()
 		}
 	}
 }
@@ -355,10 +580,7 @@ func (s *Scheduler) SyncTasks() {
 	// Remove one-off timers for tasks that no longer exist
 	for taskID := range s.oneOffTimers {
 		if !dbTaskIDs[taskID] {
-			if timer, ok := s.oneOffTimers[taskID]; ok {
-				timer.Stop()
-				delete(s.oneOffTimers, taskID)
-			}
+			s.cancelOneOffLocked(taskID)
 		}
 	}
 
@@ -379,10 +601,7 @@ func (s *Scheduler) SyncTasks() {
 				delete(s.jobs, task.ID)
 				delete(s.cronExprs, task.ID)
 			}
-			if timer, ok := s.oneOffTimers[task.ID]; ok {
-				timer.Stop()
-				delete(s.oneOffTimers, task.ID)
-			}
+			s.cancelOneOffLocked(task.ID)
 		} else if task.Enabled && hasCronJob && task.IsOneOff() {
 			// Task was converted from recurring to one-off, reschedule
 			s.cron.Remove(s.jobs[task.ID])
@@ -391,8 +610,7 @@ func (s *Scheduler) SyncTasks() {
 			_ = s.scheduleTaskLocked(task)
 		} else if task.Enabled && hasOneOffTimer && !task.IsOneOff() {
 			// Task was converted from one-off to recurring, reschedule
-			s.oneOffTimers[task.ID].Stop()
-			delete(s.oneOffTimers, task.ID)
+			s.cancelOneOffLocked(task.ID)
 			_ = s.scheduleTaskLocked(task)
 		} else if task.Enabled && hasCronJob && task.CronExpr != oldCronExpr {
 			// Cron expression changed, reschedule
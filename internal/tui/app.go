@@ -1,9 +1,12 @@
 package tui
 
 import (
+	"encoding/json"
 	"fmt"
 	"os"
-	"sort"
+	"path/filepath"
+	"regexp"
+	"strconv"
 	"strings"
 	"time"
 
@@ -18,11 +21,15 @@ import (
 	tea "github.com/charmbracelet/bubbletea"
 	"github.com/charmbracelet/glamour"
 	"github.com/charmbracelet/lipgloss"
+	"github.com/charmbracelet/x/ansi"
+	"github.com/kylemclaren/claude-tasks/internal/api"
+	"github.com/kylemclaren/claude-tasks/internal/cronexpr"
 	"github.com/kylemclaren/claude-tasks/internal/db"
+	"github.com/kylemclaren/claude-tasks/internal/diff"
 	"github.com/kylemclaren/claude-tasks/internal/executor"
 	"github.com/kylemclaren/claude-tasks/internal/scheduler"
 	"github.com/kylemclaren/claude-tasks/internal/usage"
-	"github.com/robfig/cron/v3"
+	"github.com/kylemclaren/claude-tasks/internal/webhook"
 )
 
 // View represents the current view
@@ -34,6 +41,7 @@ const (
 	ViewOutput
 	ViewEdit
 	ViewSettings
+	ViewMetrics
 )
 
 // KeyMap defines keybindings
@@ -42,6 +50,7 @@ type KeyMap struct {
 	Down     key.Binding
 	Add      key.Binding
 	Edit     key.Binding
+	Schedule key.Binding
 	Delete   key.Binding
 	Toggle   key.Binding
 	Run      key.Binding
@@ -53,6 +62,11 @@ type KeyMap struct {
 	Tab      key.Binding
 	Help     key.Binding
 	Settings key.Binding
+	Metrics  key.Binding
+	Running  key.Binding
+	Views    key.Binding
+	Jump     key.Binding
+	API      key.Binding
 }
 
 var keys = KeyMap{
@@ -60,6 +74,7 @@ var keys = KeyMap{
 	Down:     key.NewBinding(key.WithKeys("down", "j"), key.WithHelp("↓/j", "down")),
 	Add:      key.NewBinding(key.WithKeys("a"), key.WithHelp("a", "add")),
 	Edit:     key.NewBinding(key.WithKeys("e"), key.WithHelp("e", "edit")),
+	Schedule: key.NewBinding(key.WithKeys("c"), key.WithHelp("c", "edit schedule")),
 	Delete:   key.NewBinding(key.WithKeys("d"), key.WithHelp("d", "delete")),
 	Toggle:   key.NewBinding(key.WithKeys("t"), key.WithHelp("t", "toggle")),
 	Run:      key.NewBinding(key.WithKeys("r"), key.WithHelp("r", "run now")),
@@ -71,6 +86,11 @@ var keys = KeyMap{
 	Tab:      key.NewBinding(key.WithKeys("tab"), key.WithHelp("tab", "next field")),
 	Help:     key.NewBinding(key.WithKeys("?"), key.WithHelp("?", "help")),
 	Settings: key.NewBinding(key.WithKeys("s"), key.WithHelp("s", "settings")),
+	Metrics:  key.NewBinding(key.WithKeys("m"), key.WithHelp("m", "metrics")),
+	Running:  key.NewBinding(key.WithKeys("x"), key.WithHelp("x", "running runs")),
+	Views:    key.NewBinding(key.WithKeys("1", "2", "3"), key.WithHelp("1/2/3", "list/settings/metrics")),
+	Jump:     key.NewBinding(key.WithKeys(":"), key.WithHelp(":", "jump to task")),
+	API:      key.NewBinding(key.WithKeys("A"), key.WithHelp("A", "show as curl")),
 }
 
 func (k KeyMap) ShortHelp() []key.Binding {
@@ -79,9 +99,9 @@ func (k KeyMap) ShortHelp() []key.Binding {
 
 func (k KeyMap) FullHelp() [][]key.Binding {
 	return [][]key.Binding{
-		{k.Up, k.Down, k.Enter},
-		{k.Add, k.Edit, k.Delete},
-		{k.Toggle, k.Run, k.Quit},
+		{k.Up, k.Down, k.Enter, k.Jump},
+		{k.Add, k.Edit, k.Schedule, k.Delete, k.Views, k.API},
+		{k.Toggle, k.Run, k.Running, k.Settings, k.Metrics, k.Quit},
 	}
 }
 
@@ -92,6 +112,12 @@ type Model struct {
 	executor   *executor.Executor
 	daemonMode bool // true if external daemon is handling scheduling
 
+	// Daemon liveness (client mode only)
+	daemonAlive     func() bool // Reports whether the daemon process is still running
+	daemonDead      bool
+	daemonCheckedAt time.Time
+	ownsScheduler   bool // Scheduler was started here after taking over from a dead daemon
+
 	// View state
 	currentView View
 	width       int
@@ -103,6 +129,7 @@ type Model struct {
 	runningTasks    map[int64]bool
 	nextRuns        map[int64]time.Time
 	lastRunStatuses map[int64]db.RunStatus // Track last run status for each task
+	runCounts       map[int64]db.RunCounts // Run count and failure streak for the Runs column
 
 	// Delete confirmation
 	confirmDelete      bool
@@ -110,11 +137,46 @@ type Model struct {
 	deleteTaskName     string
 	deleteConfirmFocus int // 0 = Yes, 1 = No
 
+	// Run confirmation (when the confirm_before_run setting is on)
+	confirmRun      bool
+	runTask         *db.Task
+	runConfirmFocus int // 0 = Yes, 1 = No
+
+	// Running runs overlay, with cancellation of the selected run
+	showRunning        bool
+	runningRuns        []*db.TaskRun
+	runningCursor      int
+	confirmCancel      bool
+	cancelConfirmFocus int // 0 = Yes, 1 = No
+
+	// curl commands equivalent to the selected task; empty = hidden
+	apiEquivalent string
+
+	// Run note edit (output view)
+	noteEditMode bool
+	noteInput    textinput.Model
+
+	// Search within the shown run's output
+	outputSearchMode  bool
+	outputSearchInput textinput.Model
+	outputQuery       string // Active search; n/p move between its matches
+	outputMatch       int    // Index of the current match
+	outputMatchLines  []int  // Content line of each match, for scrolling
+
+	// Inline schedule edit (list view)
+	cronEditMode  bool
+	cronEditTask  *db.Task
+	cronEditInput textinput.Model
+
 	// Search/filter
 	searchMode    bool
 	searchInput   textinput.Model
 	filteredTasks []*db.Task
 
+	// Jump-to-task prompt
+	jumpMode  bool
+	jumpInput textinput.Model
+
 	// Spinners for running tasks
 	spinner spinner.Model
 
@@ -125,14 +187,30 @@ type Model struct {
 	// Add/Edit form
 	formInputs     []textinput.Model
 	promptInput    textarea.Model
+	systemPrompt   textarea.Model
 	formFocus      int
 	editingTask    *db.Task
 	formValidation map[int]string // Validation errors per field
 
 	// Task type (0 = recurring, 1 = one-off)
-	isOneOff     bool
-	runNow       bool // For one-off: true = run immediately, false = schedule for later
-	scheduledAt  textinput.Model
+	isOneOff    bool
+	runNow      bool // For one-off: true = run immediately, false = schedule for later
+	runInQuiet  bool // For recurring: exempt from quiet hours
+	scheduledAt textinput.Model
+
+	// Output format selector (one of outputFormats)
+	outputFormat string
+	keepAnsi     bool // Store output with ANSI escape codes intact
+	includePrev  bool // Append the last completed run's output to the prompt
+	templated    bool // Expand {{.Date}}-style template actions in the prompt
+	resume       bool // Continue the same Claude session on every run
+	askPerms     bool // Don't pass --dangerously-skip-permissions
+
+	// Webhook detail selector (one of webhookDetails; "" uses the global setting)
+	webhookDetail string
+
+	// Average cost of the edited task's recent runs; nil when none reported usage
+	costEstimate *db.CostEstimate
 
 	// Cron helper
 	showCronHelper  bool
@@ -141,18 +219,41 @@ type Model struct {
 
 	// Output view
 	selectedTask *db.Task
-	taskRuns     []*db.TaskRun
+	taskRuns     []*db.TaskRun // Loaded runs, newest first; extended a page at a time
+	runIndex     int           // Run currently shown in the output view
+	runTotal     int           // Total runs for the task in the DB
+	loadingRuns  bool
+	taskStats    *db.RunStats // Summary over the selected task's recent runs
 	viewport     viewport.Model
 	mdRenderer   *glamour.TermRenderer
+	showDiff     bool // Show latest run's output diffed against the previous run
+	rawOutput    bool // Show run output as plain text instead of rendered markdown
 
 	// Usage tracking
 	usageClient    *usage.Client
+	usageClientErr error // Why usageClient is nil, e.g. credentials not found
 	usageData      *usage.Response
 	usageThreshold float64
 	usageErr       error
+	usageHistory   []*db.UsageSample // Recent samples for the header sparkline
+	historyFetched time.Time
 
 	// Settings view
-	thresholdInput textinput.Model
+	thresholdInput   textinput.Model
+	settingsFocus    int
+	confirmBeforeRun bool // Saved setting
+	confirmRunToggle bool // Pending value while editing settings
+	renderMarkdown   bool // Saved setting; false opens the output view in raw mode
+	renderMdToggle   bool
+	usageCheck       bool // Saved setting; false hides the usage bar
+	usageCheckToggle bool
+	quietHours       db.QuietHours
+	quietStartInput  textinput.Model
+	quietEndInput    textinput.Model
+
+	// Metrics view
+	runSummary *db.RunSummary // Runs across all tasks in the last metricsWindow
+	summaryErr error
 
 	// Status
 	statusMsg   string
@@ -167,15 +268,46 @@ type cronPreset struct {
 	desc string
 }
 
+// Settings view field indices
+const (
+	settingThreshold = iota
+	settingUsageCheck
+	settingConfirmRun
+	settingRenderMarkdown
+	settingQuietStart
+	settingQuietEnd
+	settingCount
+)
+
 // Form field indices
 const (
 	fieldName = iota
 	fieldPrompt
-	fieldTaskType      // "Recurring" or "One-off"
-	fieldCron          // Only shown for recurring tasks
-	fieldScheduleMode  // "Run Now" or "Schedule for" - only for one-off
-	fieldScheduledAt   // Datetime input - only for scheduled one-off
+	fieldPromptFile // Alternative to an inline prompt
+	fieldStdinFile  // Piped to the CLI's stdin
+	fieldSystemPrompt
+	fieldOutputFormat // Cycles text / json / stream-json
+	fieldAnsi         // "Strip" or "Keep" ANSI escape codes in output
+	fieldPrevOutput   // "Off" or "Include" the previous run's output in the prompt
+	fieldTemplate     // "Off" or "Expand" template actions in the prompt
+	fieldSession      // "New each run" or "Resume" the task's Claude session
+	fieldPermissions  // "Skip" or "Enforce" permission prompts
+	fieldAllowedTools // --allowedTools list - only when enforcing permissions
+	fieldTaskType     // "Recurring" or "One-off"
+	fieldCron         // Only shown for recurring tasks
+	fieldScheduleMode // "Run Now" or "Schedule for" - only for one-off
+	fieldScheduledAt  // Datetime input - only for scheduled one-off
+	fieldQuietHours   // "Skip" or "Run anyway" - only for recurring
+	fieldJitter       // Random start delay in seconds - only for recurring
+	fieldActiveFrom   // Optional window start - only for recurring
+	fieldActiveUntil  // Optional window end - only for recurring
 	fieldWorkingDir
+	fieldContainer // Optional Docker image to run claude in
+	fieldTags
+	fieldUsageThreshold
+	fieldAlertAfter    // Seconds before a still-running alert; empty = off
+	fieldDisableAfter  // Consecutive failures before the task is disabled; empty = never
+	fieldWebhookDetail // Cycles default / full / summary / status-only
 	fieldDiscordWebhook
 	fieldSlackWebhook
 	fieldCount
@@ -210,6 +342,13 @@ func calculateTableColumns(width int) []table.Column {
 	statusWidth := 10
 	remaining := availableWidth - statusWidth - 8 // 8 for column separators
 
+	// The Runs column only fits on wide terminals
+	runsWidth := 0
+	if availableWidth >= 110 {
+		runsWidth = 9
+		remaining -= runsWidth + 2
+	}
+
 	nameWidth := remaining * 25 / 85
 	scheduleWidth := remaining * 20 / 85
 	nextWidth := remaining * 20 / 85
@@ -229,13 +368,17 @@ func calculateTableColumns(width int) []table.Column {
 		lastWidth = 14
 	}
 
-	return []table.Column{
+	columns := []table.Column{
 		{Title: "Name", Width: nameWidth},
 		{Title: "Schedule", Width: scheduleWidth},
 		{Title: "Status", Width: statusWidth},
 		{Title: "Next Run", Width: nextWidth},
 		{Title: "Last Run", Width: lastWidth},
 	}
+	if runsWidth > 0 {
+		columns = append(columns, table.Column{Title: "Runs", Width: runsWidth})
+	}
+	return columns
 }
 
 // NewModel creates a new TUI model
@@ -280,10 +423,14 @@ func NewModel(database *db.DB, sched *scheduler.Scheduler, daemonMode bool) Mode
 	)
 
 	// Usage client
-	usageClient, _ := usage.NewClient()
+	usageClient, usageClientErr := usage.NewClient()
 
-	// Load threshold from DB
+	// Load settings from DB
 	threshold, _ := database.GetUsageThreshold()
+	confirmBeforeRun, _ := database.GetConfirmBeforeRun()
+	renderMarkdown, _ := database.GetRenderMarkdown()
+	usageCheck, _ := database.GetUsageCheckEnabled()
+	quietHours, _ := database.GetQuietHours()
 
 	// Threshold input for settings
 	thresholdInput := textinput.New()
@@ -292,12 +439,43 @@ func NewModel(database *db.DB, sched *scheduler.Scheduler, daemonMode bool) Mode
 	thresholdInput.Width = 10
 	thresholdInput.SetValue(fmt.Sprintf("%.0f", threshold))
 
+	// Quiet hours inputs for settings
+	quietStartInput := textinput.New()
+	quietStartInput.Placeholder = "22:00"
+	quietStartInput.CharLimit = 5
+	quietStartInput.Width = 10
+	quietEndInput := textinput.New()
+	quietEndInput.Placeholder = "07:00"
+	quietEndInput.CharLimit = 5
+	quietEndInput.Width = 10
+
+	// Inline cron edit input
+	cronEditInput := textinput.New()
+	cronEditInput.Placeholder = "0 * * * * *"
+	cronEditInput.CharLimit = 50
+	cronEditInput.Width = 30
+
+	noteInput := textinput.New()
+	noteInput.Placeholder = "e.g. false positive, ignore"
+	noteInput.CharLimit = db.MaxRunNotesLength
+	noteInput.Width = 60
+
+	outputSearchInput := textinput.New()
+	outputSearchInput.Placeholder = "Search output"
+	outputSearchInput.CharLimit = 100
+	outputSearchInput.Width = 30
+
 	// Search input
 	searchInput := textinput.New()
-	searchInput.Placeholder = "Search tasks..."
+	searchInput.Placeholder = "Search tasks or tag:name"
 	searchInput.CharLimit = 100
 	searchInput.Width = 30
 
+	jumpInput := textinput.New()
+	jumpInput.Placeholder = "Task name"
+	jumpInput.CharLimit = 100
+	jumpInput.Width = 30
+
 	// Cron presets
 	cronPresets := []cronPreset{
 		{name: "Every minute", expr: "0 * * * * *", desc: "Runs at the start of every minute"},
@@ -318,24 +496,36 @@ func NewModel(database *db.DB, sched *scheduler.Scheduler, daemonMode bool) Mode
 	}
 
 	m := Model{
-		db:              database,
-		scheduler:       sched,
-		executor:        exec,
-		daemonMode:      daemonMode,
-		spinner:         s,
-		help:            h,
-		table:           t,
-		runningTasks:    make(map[int64]bool),
-		nextRuns:        make(map[int64]time.Time),
-		lastRunStatuses: make(map[int64]db.RunStatus),
-		searchInput:     searchInput,
-		cronPresets:     cronPresets,
-		formValidation:  make(map[int]string),
-		viewport:        viewport.New(80, 20),
-		mdRenderer:      renderer,
-		usageClient:     usageClient,
-		usageThreshold:  threshold,
-		thresholdInput:  thresholdInput,
+		db:                database,
+		scheduler:         sched,
+		executor:          exec,
+		daemonMode:        daemonMode,
+		spinner:           s,
+		help:              h,
+		table:             t,
+		runningTasks:      make(map[int64]bool),
+		nextRuns:          make(map[int64]time.Time),
+		lastRunStatuses:   make(map[int64]db.RunStatus),
+		runCounts:         make(map[int64]db.RunCounts),
+		searchInput:       searchInput,
+		jumpInput:         jumpInput,
+		cronEditInput:     cronEditInput,
+		noteInput:         noteInput,
+		outputSearchInput: outputSearchInput,
+		cronPresets:       cronPresets,
+		formValidation:    make(map[int]string),
+		viewport:          viewport.New(80, 20),
+		mdRenderer:        renderer,
+		usageClient:       usageClient,
+		usageClientErr:    usageClientErr,
+		usageThreshold:    threshold,
+		thresholdInput:    thresholdInput,
+		confirmBeforeRun:  confirmBeforeRun,
+		renderMarkdown:    renderMarkdown,
+		usageCheck:        usageCheck,
+		quietHours:        quietHours,
+		quietStartInput:   quietStartInput,
+		quietEndInput:     quietEndInput,
 	}
 
 	m.initFormInputs()
@@ -356,11 +546,56 @@ func (m *Model) initFormInputs() {
 	// Prompt uses textarea for multi-line input
 	m.promptInput = textarea.New()
 	m.promptInput.Placeholder = "Review recent changes and summarize..."
-	m.promptInput.CharLimit = 2000
+	m.promptInput.CharLimit, _ = m.db.GetMaxPromptLength()
 	m.promptInput.SetWidth(inputWidth + 2)
 	m.promptInput.SetHeight(m.getTextareaHeight())
 	m.promptInput.ShowLineNumbers = false
 
+	m.formInputs[fieldPromptFile] = textinput.New()
+	m.formInputs[fieldPromptFile].Placeholder = "prompts/review.md (instead of an inline prompt)"
+	m.formInputs[fieldPromptFile].CharLimit = 500
+	m.formInputs[fieldPromptFile].Width = inputWidth
+
+	m.formInputs[fieldStdinFile] = textinput.New()
+	m.formInputs[fieldStdinFile].Placeholder = "data/input.json"
+	m.formInputs[fieldStdinFile].CharLimit = 500
+	m.formInputs[fieldStdinFile].Width = inputWidth
+
+	// Optional system prompt, also multi-line
+	m.systemPrompt = textarea.New()
+	m.systemPrompt.Placeholder = "You are a careful code reviewer."
+	m.systemPrompt.CharLimit = 2000
+	m.systemPrompt.SetWidth(inputWidth + 2)
+	m.systemPrompt.SetHeight(3)
+	m.systemPrompt.ShowLineNumbers = false
+
+	// Placeholder so formInputs can be indexed by every field constant
+	m.formInputs[fieldSystemPrompt] = textinput.New()
+
+	// Output format placeholder (not a real input)
+	m.formInputs[fieldOutputFormat] = textinput.New()
+	m.formInputs[fieldOutputFormat].Width = inputWidth
+
+	// ANSI toggle placeholder (not a real input)
+	m.formInputs[fieldAnsi] = textinput.New()
+
+	// Previous output toggle placeholder (not a real input)
+	m.formInputs[fieldPrevOutput] = textinput.New()
+
+	// Prompt template toggle placeholder (not a real input)
+	m.formInputs[fieldTemplate] = textinput.New()
+
+	// Session toggle placeholder (not a real input)
+	m.formInputs[fieldSession] = textinput.New()
+
+	// Permissions toggle placeholder (not a real input)
+	m.formInputs[fieldPermissions] = textinput.New()
+
+	m.formInputs[fieldAllowedTools] = textinput.New()
+	m.formInputs[fieldAllowedTools].Placeholder = "Read,Grep,Bash(git log:*)"
+	m.formInputs[fieldAllowedTools].CharLimit = 500
+	m.formInputs[fieldAllowedTools].Width = inputWidth
+
 	// Task type placeholder (not a real input, just for indexing)
 	m.formInputs[fieldTaskType] = textinput.New()
 	m.formInputs[fieldTaskType].Width = inputWidth
@@ -374,6 +609,25 @@ func (m *Model) initFormInputs() {
 	m.formInputs[fieldScheduleMode] = textinput.New()
 	m.formInputs[fieldScheduleMode].Width = inputWidth
 
+	// Quiet hours placeholder (not a real input)
+	m.formInputs[fieldQuietHours] = textinput.New()
+	m.formInputs[fieldQuietHours].Width = inputWidth
+
+	m.formInputs[fieldJitter] = textinput.New()
+	m.formInputs[fieldJitter].Placeholder = "0"
+	m.formInputs[fieldJitter].CharLimit = 4
+	m.formInputs[fieldJitter].Width = inputWidth
+
+	m.formInputs[fieldActiveFrom] = textinput.New()
+	m.formInputs[fieldActiveFrom].Placeholder = "2024-01-01 00:00 (optional)"
+	m.formInputs[fieldActiveFrom].CharLimit = 20
+	m.formInputs[fieldActiveFrom].Width = inputWidth
+
+	m.formInputs[fieldActiveUntil] = textinput.New()
+	m.formInputs[fieldActiveUntil].Placeholder = "2024-01-31 23:59 (optional)"
+	m.formInputs[fieldActiveUntil].CharLimit = 20
+	m.formInputs[fieldActiveUntil].Width = inputWidth
+
 	// Scheduled at datetime input
 	m.formInputs[fieldScheduledAt] = textinput.New()
 	m.formInputs[fieldScheduledAt].Placeholder = "2024-01-15 09:00"
@@ -393,6 +647,34 @@ func (m *Model) initFormInputs() {
 	wd, _ := os.Getwd()
 	m.formInputs[fieldWorkingDir].SetValue(wd)
 
+	m.formInputs[fieldContainer] = textinput.New()
+	m.formInputs[fieldContainer].Placeholder = "ghcr.io/org/claude:latest"
+	m.formInputs[fieldContainer].CharLimit = 255
+	m.formInputs[fieldContainer].Width = inputWidth
+
+	m.formInputs[fieldTags] = textinput.New()
+	m.formInputs[fieldTags].Placeholder = "backend, nightly"
+	m.formInputs[fieldTags].CharLimit = 200
+	m.formInputs[fieldTags].Width = inputWidth
+
+	m.formInputs[fieldUsageThreshold] = textinput.New()
+	m.formInputs[fieldUsageThreshold].Placeholder = "Leave empty to use the global threshold"
+	m.formInputs[fieldUsageThreshold].CharLimit = 5
+	m.formInputs[fieldUsageThreshold].Width = inputWidth
+
+	m.formInputs[fieldAlertAfter] = textinput.New()
+	m.formInputs[fieldAlertAfter].Placeholder = "Leave empty for no alert"
+	m.formInputs[fieldAlertAfter].CharLimit = 5
+	m.formInputs[fieldAlertAfter].Width = inputWidth
+
+	m.formInputs[fieldDisableAfter] = textinput.New()
+	m.formInputs[fieldDisableAfter].Placeholder = "Leave empty to never disable"
+	m.formInputs[fieldDisableAfter].CharLimit = 4
+	m.formInputs[fieldDisableAfter].Width = inputWidth
+
+	// Webhook detail placeholder (not a real input)
+	m.formInputs[fieldWebhookDetail] = textinput.New()
+
 	m.formInputs[fieldDiscordWebhook] = textinput.New()
 	m.formInputs[fieldDiscordWebhook].Placeholder = "https://discord.com/api/webhooks/..."
 	m.formInputs[fieldDiscordWebhook].CharLimit = 500
@@ -405,7 +687,16 @@ func (m *Model) initFormInputs() {
 
 	// Reset task type state
 	m.isOneOff = false
+	m.runInQuiet = false
+	m.outputFormat = db.OutputFormatText
+	m.keepAnsi = false
+	m.includePrev = false
+	m.templated = false
+	m.resume = false
+	m.webhookDetail = ""
+	m.askPerms = false
 	m.runNow = true
+	m.costEstimate = nil
 }
 
 // getFormInputWidth calculates responsive input width
@@ -451,6 +742,7 @@ func (m *Model) updateFormWidths(width int) {
 	}
 	m.promptInput.SetWidth(inputWidth + 2)
 	m.promptInput.SetHeight(m.getTextareaHeight())
+	m.systemPrompt.SetWidth(inputWidth + 2)
 }
 
 func (m *Model) resetForm() {
@@ -460,6 +752,66 @@ func (m *Model) resetForm() {
 	m.editingTask = nil
 	m.isOneOff = false
 	m.runNow = true
+
+	// Pre-fill the configured defaults for new tasks
+	if expr, _ := m.db.GetDefaultCron(); expr != "" {
+		m.formInputs[fieldCron].SetValue(expr)
+	}
+	if dir, _ := m.db.GetConfiguredWorkingDir(); dir != "" {
+		m.formInputs[fieldWorkingDir].SetValue(dir)
+	}
+}
+
+// fillForm loads a task's settings into the add/edit form
+func (m *Model) fillForm(task *db.Task) {
+	m.formInputs[fieldName].SetValue(task.Name)
+	m.promptInput.SetValue(task.Prompt)
+	m.formInputs[fieldPromptFile].SetValue(task.PromptFile)
+	m.formInputs[fieldStdinFile].SetValue(task.StdinFile)
+	m.systemPrompt.SetValue(task.SystemPrompt)
+	if task.OutputFormat != "" {
+		m.outputFormat = task.OutputFormat
+	}
+	m.keepAnsi = !task.StripAnsi
+	m.webhookDetail = task.WebhookDetail
+	m.includePrev = task.IncludePreviousOutput
+	m.templated = task.TemplatePrompt
+	m.resume = task.ResumeSession
+	m.askPerms = !task.SkipPermissions
+	m.formInputs[fieldAllowedTools].SetValue(task.AllowedTools)
+	m.formInputs[fieldCron].SetValue(task.CronExpr)
+	m.formInputs[fieldWorkingDir].SetValue(task.WorkingDir)
+	m.formInputs[fieldContainer].SetValue(task.Container)
+	m.formInputs[fieldTags].SetValue(strings.Join(task.Tags, ", "))
+	if task.UsageThresholdOverride != nil {
+		m.formInputs[fieldUsageThreshold].SetValue(fmt.Sprintf("%g", *task.UsageThresholdOverride))
+	}
+	if task.AlertAfterSeconds > 0 {
+		m.formInputs[fieldAlertAfter].SetValue(strconv.Itoa(task.AlertAfterSeconds))
+	}
+	if task.DisableAfterFailures > 0 {
+		m.formInputs[fieldDisableAfter].SetValue(strconv.Itoa(task.DisableAfterFailures))
+	}
+	m.formInputs[fieldDiscordWebhook].SetValue(task.DiscordWebhook)
+	m.formInputs[fieldSlackWebhook].SetValue(task.SlackWebhook)
+	// Set task type state from existing task
+	m.isOneOff = task.IsOneOff()
+	m.runInQuiet = task.RunDuringQuietHours
+	if task.JitterSeconds > 0 {
+		m.formInputs[fieldJitter].SetValue(strconv.Itoa(task.JitterSeconds))
+	}
+	if task.ActiveFrom != nil {
+		m.formInputs[fieldActiveFrom].SetValue(task.ActiveFrom.Local().Format("2006-01-02 15:04"))
+	}
+	if task.ActiveUntil != nil {
+		m.formInputs[fieldActiveUntil].SetValue(task.ActiveUntil.Local().Format("2006-01-02 15:04"))
+	}
+	if m.isOneOff && task.ScheduledAt != nil {
+		m.runNow = false
+		m.scheduledAt.SetValue(task.ScheduledAt.Format("2006-01-02 15:04"))
+	} else {
+		m.runNow = true
+	}
 }
 
 func (m *Model) focusFormField(field int) {
@@ -468,12 +820,15 @@ func (m *Model) focusFormField(field int) {
 		m.formInputs[i].Blur()
 	}
 	m.promptInput.Blur()
+	m.systemPrompt.Blur()
 	m.scheduledAt.Blur()
 
 	// Focus the target field
 	m.formFocus = field
 	if field == fieldPrompt {
 		m.promptInput.Focus()
+	} else if field == fieldSystemPrompt {
+		m.systemPrompt.Focus()
 	} else if field == fieldScheduledAt {
 		m.scheduledAt.Focus()
 	} else {
@@ -524,10 +879,12 @@ func (m *Model) getPrevFormField(current int) int {
 // shouldShowField returns true if the field should be shown based on current task type
 func (m *Model) shouldShowField(field int) bool {
 	switch field {
-	case fieldName, fieldPrompt, fieldTaskType, fieldWorkingDir, fieldDiscordWebhook, fieldSlackWebhook:
+	case fieldName, fieldPrompt, fieldPromptFile, fieldStdinFile, fieldSystemPrompt, fieldOutputFormat, fieldAnsi, fieldPrevOutput, fieldTemplate, fieldSession, fieldPermissions, fieldTaskType, fieldWorkingDir, fieldContainer, fieldTags, fieldUsageThreshold, fieldAlertAfter, fieldDisableAfter, fieldWebhookDetail, fieldDiscordWebhook, fieldSlackWebhook:
 		return true
-	case fieldCron:
+	case fieldCron, fieldQuietHours, fieldJitter, fieldActiveFrom, fieldActiveUntil:
 		return !m.isOneOff // Only for recurring tasks
+	case fieldAllowedTools:
+		return m.askPerms // Only when permission prompts are enforced
 	case fieldScheduleMode:
 		return m.isOneOff // Only for one-off tasks
 	case fieldScheduledAt:
@@ -572,6 +929,8 @@ func (m *Model) updateTable() {
 				statusParts = append(statusParts, "✓")
 			case db.RunStatusFailed:
 				statusParts = append(statusParts, "✗")
+			case db.RunStatusSkipped:
+				statusParts = append(statusParts, "⊘")
 			case db.RunStatusRunning:
 				statusParts = append(statusParts, "●")
 			}
@@ -617,10 +976,21 @@ func (m *Model) updateTable() {
 			nextRun,
 			lastRun,
 		}
+		if len(columns) > 5 {
+			rows[i] = append(rows[i], formatRunCounts(m.runCounts[task.ID]))
+		}
 	}
 	m.table.SetRows(rows)
 }
 
+// formatRunCounts renders the Runs column, e.g. "42" or "42 ✗3" during a failure streak
+func formatRunCounts(c db.RunCounts) string {
+	if c.FailureStreak > 0 {
+		return fmt.Sprintf("%d ✗%d", c.RunCount, c.FailureStreak)
+	}
+	return strconv.Itoa(c.RunCount)
+}
+
 func formatTime(t time.Time) string {
 	now := time.Now()
 	if t.Before(now) {
@@ -655,14 +1025,42 @@ type taskToggledMsg struct {
 	id      int64
 	enabled bool
 }
-type taskRunsLoadedMsg struct{ runs []*db.TaskRun }
+type moreTaskRunsMsg struct {
+	taskID int64
+	runs   []*db.TaskRun
+}
+type taskRunsLoadedMsg struct {
+	runs  []*db.TaskRun
+	total int
+	stats *db.RunStats
+}
 type runningTasksMsg struct{ running map[int64]bool }
+type runningRunsMsg struct{ runs []*db.TaskRun }
+type runCancelRequestedMsg struct{ runID int64 }
+type runNoteSavedMsg struct {
+	runID int64
+	notes string
+}
 type usageUpdatedMsg struct {
 	data *usage.Response
 	err  error
 }
-type thresholdSavedMsg struct{ threshold float64 }
-type lastRunStatusesMsg struct{ statuses map[int64]db.RunStatus }
+type usageHistoryMsg struct{ samples []*db.UsageSample }
+type settingsSavedMsg struct {
+	threshold        float64
+	confirmBeforeRun bool
+	renderMarkdown   bool
+	usageCheck       bool
+	quietHours       db.QuietHours
+}
+type lastRunStatusesMsg struct {
+	statuses map[int64]db.RunStatus
+	counts   map[int64]db.RunCounts
+}
+type runSummaryMsg struct {
+	summary *db.RunSummary
+	err     error
+}
 type errMsg struct{ err error }
 type tickMsg time.Time
 
@@ -671,12 +1069,46 @@ func (m Model) Init() tea.Cmd {
 		m.loadTasks(),
 		m.spinner.Tick,
 		m.fetchUsage(),
+		m.fetchUsageHistory(),
 		tickCmd(),
 	)
 }
 
+// Sparkline settings: samples are recorded every few minutes by the scheduler
+const (
+	sparklineSamples     = 20
+	usageHistoryRefresh  = time.Minute
+	usageHistoryLookback = 24 * time.Hour
+)
+
+// metricsWindow is how far back the metrics view counts runs
+const metricsWindow = 24 * time.Hour
+
+func (m *Model) fetchRunSummary() tea.Cmd {
+	return func() tea.Msg {
+		summary, err := m.db.GetRunSummary(time.Now().Add(-metricsWindow))
+		return runSummaryMsg{summary: summary, err: err}
+	}
+}
+
+func (m *Model) fetchUsageHistory() tea.Cmd {
+	return func() tea.Msg {
+		samples, err := m.db.GetUsageHistory(time.Now().Add(-usageHistoryLookback))
+		if err != nil {
+			return usageHistoryMsg{}
+		}
+		if len(samples) > sparklineSamples {
+			samples = samples[len(samples)-sparklineSamples:]
+		}
+		return usageHistoryMsg{samples: samples}
+	}
+}
+
 func (m *Model) fetchUsage() tea.Cmd {
 	return func() tea.Msg {
+		if !m.usageCheck {
+			return usageUpdatedMsg{err: fmt.Errorf("usage checking disabled")}
+		}
 		if m.usageClient == nil {
 			return usageUpdatedMsg{err: fmt.Errorf("no credentials")}
 		}
@@ -701,6 +1133,36 @@ func (m *Model) loadTasks() tea.Cmd {
 	}
 }
 
+func (m *Model) loadRunningRuns() tea.Cmd {
+	return func() tea.Msg {
+		runs, err := m.db.GetRunningTaskRuns()
+		if err != nil {
+			return errMsg{err}
+		}
+		return runningRunsMsg{runs}
+	}
+}
+
+// saveRunNote replaces the notes on a run
+func (m *Model) saveRunNote(runID int64, notes string) tea.Cmd {
+	return func() tea.Msg {
+		if err := m.db.SetTaskRunNotes(runID, notes); err != nil {
+			return errMsg{err}
+		}
+		return runNoteSavedMsg{runID, notes}
+	}
+}
+
+// cancelRun asks the process executing the run to stop it
+func (m *Model) cancelRun(runID int64) tea.Cmd {
+	return func() tea.Msg {
+		if err := m.db.RequestRunCancel(runID); err != nil {
+			return errMsg{err}
+		}
+		return runCancelRequestedMsg{runID}
+	}
+}
+
 func (m *Model) checkRunningTasks() tea.Cmd {
 	return func() tea.Msg {
 		running := make(map[int64]bool)
@@ -718,9 +1180,13 @@ func (m *Model) fetchLastRunStatuses() tea.Cmd {
 	return func() tea.Msg {
 		statuses, err := m.db.GetLastRunStatuses()
 		if err != nil {
-			return lastRunStatusesMsg{statuses: make(map[int64]db.RunStatus)}
+			statuses = make(map[int64]db.RunStatus)
+		}
+		counts, err := m.db.GetRunCounts()
+		if err != nil {
+			counts = make(map[int64]db.RunCounts)
 		}
-		return lastRunStatusesMsg{statuses: statuses}
+		return lastRunStatusesMsg{statuses: statuses, counts: counts}
 	}
 }
 
@@ -742,14 +1208,19 @@ func (m Model) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 			return m.updateOutput(msg)
 		case ViewSettings:
 			return m.updateSettings(msg)
+		case ViewMetrics:
+			return m.updateMetrics(msg)
 		}
 
 	case tea.WindowSizeMsg:
 		m.width = msg.Width
 		m.height = msg.Height
 
-		// Update table columns and dimensions
+		// Update table columns and dimensions; rows are rebuilt to match, since
+		// the table can't render rows with more cells than it has columns
+		m.table.SetRows(nil)
 		m.table.SetColumns(calculateTableColumns(msg.Width))
+		m.updateTable()
 		tableWidth := msg.Width - 4
 		if tableWidth > maxTableWidth {
 			tableWidth = maxTableWidth
@@ -811,7 +1282,19 @@ func (m Model) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 			}
 		}
 
+		m.checkDaemon()
+
 		cmds = append(cmds, tickCmd(), m.checkRunningTasks(), m.fetchUsage(), m.fetchLastRunStatuses())
+		if m.currentView == ViewMetrics {
+			cmds = append(cmds, m.fetchRunSummary())
+		}
+		if m.showRunning {
+			cmds = append(cmds, m.loadRunningRuns())
+		}
+		if time.Since(m.historyFetched) >= usageHistoryRefresh {
+			m.historyFetched = time.Now()
+			cmds = append(cmds, m.fetchUsageHistory())
+		}
 
 	case tasksLoadedMsg:
 		m.tasks = msg.tasks
@@ -827,10 +1310,35 @@ func (m Model) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 		m.runningTasks = msg.running
 		m.updateTable()
 
+	case runningRunsMsg:
+		m.runningRuns = msg.runs
+		m.runningCursor = max(0, min(m.runningCursor, len(m.runningRuns)-1))
+
+	case runCancelRequestedMsg:
+		m.setStatus(fmt.Sprintf("Cancelling run #%d", msg.runID), false)
+		cmds = append(cmds, m.loadRunningRuns())
+
+	case runNoteSavedMsg:
+		for _, run := range m.taskRuns {
+			if run.ID == msg.runID {
+				run.Notes = msg.notes
+			}
+		}
+		if m.currentView == ViewOutput {
+			m.refreshOutput()
+		}
+
 	case lastRunStatusesMsg:
 		m.lastRunStatuses = msg.statuses
+		m.runCounts = msg.counts
 		m.updateTable()
 
+	case usageHistoryMsg:
+		m.usageHistory = msg.samples
+
+	case runSummaryMsg:
+		m.runSummary, m.summaryErr = msg.summary, msg.err
+
 	case usageUpdatedMsg:
 		if msg.err == nil {
 			m.usageData = msg.data
@@ -839,13 +1347,24 @@ func (m Model) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 			m.usageErr = msg.err
 		}
 
-	case thresholdSavedMsg:
+	case settingsSavedMsg:
 		m.usageThreshold = msg.threshold
-		m.setStatus(fmt.Sprintf("Threshold saved: %.0f%%", msg.threshold), false)
+		m.confirmBeforeRun = msg.confirmBeforeRun
+		m.renderMarkdown = msg.renderMarkdown
+		m.usageCheck = msg.usageCheck
+		if !m.usageCheck {
+			m.usageData = nil // Hide the usage bar
+		}
+		m.quietHours = msg.quietHours
+		m.setStatus(fmt.Sprintf("Settings saved (threshold %.0f%%)", msg.threshold), false)
 		m.currentView = ViewList
 
 	case taskCreatedMsg:
-		m.setStatus("Task saved: "+msg.task.Name, false)
+		if _, err := executor.LookupClaude(m.db); err != nil {
+			m.setStatus("Task saved, but "+err.Error(), true)
+		} else {
+			m.setStatus("Task saved: "+msg.task.Name, false)
+		}
 		m.currentView = ViewList
 		cmds = append(cmds, m.loadTasks())
 
@@ -867,9 +1386,26 @@ func (m Model) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 
 	case taskRunsLoadedMsg:
 		m.taskRuns = msg.runs
-		m.viewport.SetContent(m.renderOutputContent())
+		m.runTotal = msg.total
+		m.runIndex = 0
+		m.taskStats = msg.stats
+		m.loadRunOutputs()
+		m.outputMatch = 0
+		m.refreshOutput()
 		m.viewport.GotoTop()
 
+	case moreTaskRunsMsg:
+		m.loadingRuns = false
+		if m.selectedTask == nil || m.selectedTask.ID != msg.taskID {
+			break
+		}
+		if len(msg.runs) == 0 {
+			m.runTotal = len(m.taskRuns) // Runs were deleted since the count
+			break
+		}
+		m.taskRuns = append(m.taskRuns, msg.runs...)
+		m.showRun(m.runIndex + 1)
+
 	case errMsg:
 		m.setStatus("Error: "+msg.err.Error(), true)
 	}
@@ -925,6 +1461,88 @@ func (m *Model) updateList(msg tea.KeyMsg) (tea.Model, tea.Cmd) {
 		return m, nil
 	}
 
+	// Handle run confirmation mode
+	if m.confirmRun {
+		switch msg.String() {
+		case "left", "h":
+			m.runConfirmFocus = 0 // Yes
+		case "right", "l":
+			m.runConfirmFocus = 1 // No
+		case "tab":
+			m.runConfirmFocus = (m.runConfirmFocus + 1) % 2
+		case "y", "Y", "enter":
+			task := m.runTask
+			yes := msg.String() != "enter" || m.runConfirmFocus == 0
+			m.confirmRun = false
+			m.runTask = nil
+			m.runConfirmFocus = 1
+			if yes {
+				m.startTask(task)
+			}
+		case "n", "N", "esc":
+			m.confirmRun = false
+			m.runTask = nil
+			m.runConfirmFocus = 1
+		}
+		return m, nil
+	}
+
+	// Handle run cancellation confirmation
+	if m.confirmCancel {
+		switch msg.String() {
+		case "left", "h":
+			m.cancelConfirmFocus = 0 // Yes
+		case "right", "l":
+			m.cancelConfirmFocus = 1 // No
+		case "tab":
+			m.cancelConfirmFocus = (m.cancelConfirmFocus + 1) % 2
+		case "y", "Y", "enter":
+			yes := msg.String() != "enter" || m.cancelConfirmFocus == 0
+			m.confirmCancel = false
+			m.cancelConfirmFocus = 1
+			if yes && m.runningCursor < len(m.runningRuns) {
+				return m, m.cancelRun(m.runningRuns[m.runningCursor].ID)
+			}
+		case "n", "N", "esc":
+			m.confirmCancel = false
+			m.cancelConfirmFocus = 1
+		}
+		return m, nil
+	}
+
+	// Any key closes the API equivalent
+	if m.apiEquivalent != "" {
+		m.apiEquivalent = ""
+		return m, nil
+	}
+
+	// Handle the running runs overlay
+	if m.showRunning {
+		switch msg.String() {
+		case "up", "k":
+			if m.runningCursor > 0 {
+				m.runningCursor--
+			}
+		case "down", "j":
+			if m.runningCursor < len(m.runningRuns)-1 {
+				m.runningCursor++
+			}
+		case "x", "enter":
+			if m.runningCursor < len(m.runningRuns) {
+				m.confirmCancel = true
+				m.cancelConfirmFocus = 1 // Default to "No" for safety
+			}
+		case "esc", "q":
+			m.showRunning = false
+		}
+		return m, nil
+	}
+
+	// Handle inline schedule edit mode
+	if m.cronEditMode {
+		return m.updateCronEdit(msg)
+	}
+
 	// Handle search mode
 	if m.searchMode {
 		switch msg.String() {
@@ -948,17 +1566,52 @@ func (m *Model) updateList(msg tea.KeyMsg) (tea.Model, tea.Cmd) {
 		}
 	}
 
+	// Handle the jump-to-task prompt
+	if m.jumpMode {
+		switch msg.String() {
+		case "esc":
+			m.jumpMode = false
+			m.jumpInput.Blur()
+		case "enter":
+			m.jumpMode = false
+			m.jumpInput.Blur()
+			query := strings.TrimSpace(m.jumpInput.Value())
+			if i := m.findTask(query); i >= 0 {
+				m.table.SetCursor(i)
+			} else if query != "" {
+				m.setStatus(fmt.Sprintf("No task matches %q", query), true)
+			}
+		default:
+			m.jumpInput, cmd = m.jumpInput.Update(msg)
+		}
+		return m, cmd
+	}
+
+	if cmd, ok := m.switchView(msg.String()); ok {
+		return m, cmd
+	}
+
 	switch msg.String() {
 	case "q":
 		return m, tea.Quit
 	case "?":
 		m.showHelp = !m.showHelp
 		return m, nil
+	case "x":
+		m.showRunning = true
+		m.runningCursor = 0
+		return m, m.loadRunningRuns()
 	case "/":
 		// Enter search mode
 		m.searchMode = true
 		m.searchInput.Focus()
 		return m, textinput.Blink
+	case ":":
+		// Jump to a task by name
+		m.jumpMode = true
+		m.jumpInput.SetValue("")
+		m.jumpInput.Focus()
+		return m, textinput.Blink
 	case "a":
 		m.currentView = ViewAdd
 		m.resetForm()
@@ -991,21 +1644,13 @@ func (m *Model) updateList(msg tea.KeyMsg) (tea.Model, tea.Cmd) {
 			idx := m.table.Cursor()
 			if idx < len(tasksToUse) {
 				task := tasksToUse[idx]
-				if m.scheduler != nil {
-					if err := m.scheduler.RunTaskNow(task.ID); err != nil {
-						m.setStatus("Error: "+err.Error(), true)
-					} else {
-						m.runningTasks[task.ID] = true
-						m.updateTable()
-						m.setStatus("Started: "+task.Name, false)
-					}
-				} else if m.executor != nil {
-					// In daemon mode, run directly via executor
-					m.executor.ExecuteAsync(task)
-					m.runningTasks[task.ID] = true
-					m.updateTable()
-					m.setStatus("Started: "+task.Name, false)
+				if m.confirmBeforeRun {
+					m.confirmRun = true
+					m.runTask = task
+					m.runConfirmFocus = 1 // Default to "No" for safety
+					return m, nil
 				}
+				m.startTask(task)
 			}
 		}
 		return m, nil
@@ -1016,6 +1661,9 @@ func (m *Model) updateList(msg tea.KeyMsg) (tea.Model, tea.Cmd) {
 			if idx < len(tasksToUse) {
 				m.selectedTask = tasksToUse[idx]
 				m.currentView = ViewOutput
+				m.showDiff = false
+				m.rawOutput = !m.renderMarkdown
+				m.clearOutputSearch()
 				return m, m.loadTaskRuns(m.selectedTask.ID)
 			}
 		}
@@ -1027,29 +1675,56 @@ func (m *Model) updateList(msg tea.KeyMsg) (tea.Model, tea.Cmd) {
 				m.editingTask = tasksToUse[idx]
 				m.currentView = ViewEdit
 				m.initFormInputs() // Reset form first
-				m.formInputs[fieldName].SetValue(m.editingTask.Name)
-				m.promptInput.SetValue(m.editingTask.Prompt)
-				m.formInputs[fieldCron].SetValue(m.editingTask.CronExpr)
-				m.formInputs[fieldWorkingDir].SetValue(m.editingTask.WorkingDir)
-				m.formInputs[fieldDiscordWebhook].SetValue(m.editingTask.DiscordWebhook)
-				m.formInputs[fieldSlackWebhook].SetValue(m.editingTask.SlackWebhook)
-				// Set task type state from existing task
-				m.isOneOff = m.editingTask.IsOneOff()
-				if m.isOneOff && m.editingTask.ScheduledAt != nil {
-					m.runNow = false
-					m.scheduledAt.SetValue(m.editingTask.ScheduledAt.Format("2006-01-02 15:04"))
-				} else {
-					m.runNow = true
+				m.fillForm(m.editingTask)
+				if est, err := m.db.GetTaskCostEstimate(m.editingTask.ID, costEstimateRuns); err == nil && est.SampleSize > 0 {
+					m.costEstimate = est
 				}
 				m.focusFormField(fieldName)
 				return m, textinput.Blink
 			}
 		}
+	case "c":
+		tasksToUse := m.getDisplayTasks()
+		idx := m.table.Cursor()
+		if idx < len(tasksToUse) {
+			task := tasksToUse[idx]
+			if task.IsOneOff() {
+				m.setStatus("One-off tasks have no cron schedule; use 'e' to edit", true)
+				return m, nil
+			}
+			m.cronEditMode = true
+			m.cronEditTask = task
+			m.cronEditInput.SetValue(task.CronExpr)
+			m.cronEditInput.CursorEnd()
+			m.cronEditInput.Focus()
+			return m, textinput.Blink
+		}
+	case "A":
+		tasksToUse := m.getDisplayTasks()
+		idx := m.table.Cursor()
+		if idx < len(tasksToUse) {
+			baseURL, _ := m.db.GetPublicBaseURL()
+			text, err := apiEquivalent(tasksToUse[idx], baseURL)
+			if err != nil {
+				m.setStatus("Error: "+err.Error(), true)
+				return m, nil
+			}
+			m.apiEquivalent = text
+		}
+		return m, nil
+	case "S":
+		if m.daemonDead {
+			if err := m.takeOverScheduling(); err != nil {
+				m.setStatus("Error: "+err.Error(), true)
+			} else {
+				m.setStatus("Scheduling locally; this TUI now runs scheduled tasks", false)
+			}
+			return m, m.loadTasks()
+		}
 	case "s":
-		m.currentView = ViewSettings
-		m.thresholdInput.SetValue(fmt.Sprintf("%.0f", m.usageThreshold))
-		m.thresholdInput.Focus()
-		return m, textinput.Blink
+		return m, m.openSettings()
+	case "m":
+		return m, m.openMetrics()
 	default:
 		// Only forward to table if we have rows
 		tasksToUse := m.getDisplayTasks()
@@ -1061,6 +1736,63 @@ func (m *Model) updateList(msg tea.KeyMsg) (tea.Model, tea.Cmd) {
 	return m, cmd
 }
 
+// switchView handles the 1/2/3 keys that jump straight to the list, settings,
+// or metrics view, reporting whether key was one of them
+func (m *Model) switchView(key string) (tea.Cmd, bool) {
+	switch key {
+	case "1":
+		m.currentView = ViewList
+		return nil, true
+	case "2":
+		return m.openSettings(), true
+	case "3":
+		return m.openMetrics(), true
+	}
+	return nil, false
+}
+
+// openSettings shows the settings view with the saved values loaded
+func (m *Model) openSettings() tea.Cmd {
+	m.currentView = ViewSettings
+	m.thresholdInput.SetValue(fmt.Sprintf("%.0f", m.usageThreshold))
+	m.confirmRunToggle = m.confirmBeforeRun
+	m.renderMdToggle = m.renderMarkdown
+	m.usageCheckToggle = m.usageCheck
+	m.quietStartInput.SetValue(m.quietHours.Start)
+	m.quietEndInput.SetValue(m.quietHours.End)
+	m.focusSetting(settingThreshold)
+	return textinput.Blink
+}
+
+// openMetrics shows the metrics view and refreshes its run summary
+func (m *Model) openMetrics() tea.Cmd {
+	m.currentView = ViewMetrics
+	return m.fetchRunSummary()
+}
+
+// findTask returns the index among the displayed tasks of the best match for
+// name: an exact match, then a prefix, then a substring, ignoring case. It
+// returns -1 when nothing matches.
+func (m *Model) findTask(name string) int {
+	name = strings.ToLower(name)
+	if name == "" {
+		return -1
+	}
+	tasks := m.getDisplayTasks()
+	for _, match := range []func(string) bool{
+		func(s string) bool { return s == name },
+		func(s string) bool { return strings.HasPrefix(s, name) },
+		func(s string) bool { return strings.Contains(s, name) },
+	} {
+		for i, task := range tasks {
+			if match(strings.ToLower(task.Name)) {
+				return i
+			}
+		}
+	}
+	return -1
+}
+
 // getDisplayTasks returns the tasks currently being displayed (filtered or all)
 func (m *Model) getDisplayTasks() []*db.Task {
 	if m.searchMode && m.searchInput.Value() != "" {
@@ -1077,10 +1809,22 @@ func (m *Model) filterTasks() {
 		return
 	}
 
+	// "tag:foo" matches tasks tagged foo exactly
+	if tag, ok := strings.CutPrefix(query, "tag:"); ok {
+		m.filteredTasks = nil
+		for _, task := range m.tasks {
+			if task.HasTag(strings.TrimSpace(tag)) {
+				m.filteredTasks = append(m.filteredTasks, task)
+			}
+		}
+		return
+	}
+
 	m.filteredTasks = nil
 	for _, task := range m.tasks {
 		if strings.Contains(strings.ToLower(task.Name), query) ||
-			strings.Contains(strings.ToLower(task.Prompt), query) {
+			strings.Contains(strings.ToLower(task.Prompt), query) ||
+			strings.Contains(strings.Join(task.Tags, " "), query) {
 			m.filteredTasks = append(m.filteredTasks, task)
 		}
 	}
@@ -1098,10 +1842,14 @@ func (m *Model) validateForm() bool {
 		valid = false
 	}
 
-	// Validate prompt
+	// Validate prompt: exactly one of inline prompt or prompt file
 	prompt := strings.TrimSpace(m.promptInput.Value())
-	if prompt == "" {
-		m.formValidation[fieldPrompt] = "Prompt is required"
+	promptFile := strings.TrimSpace(m.formInputs[fieldPromptFile].Value())
+	if prompt == "" && promptFile == "" {
+		m.formValidation[fieldPrompt] = "Prompt or prompt file is required"
+		valid = false
+	} else if prompt != "" && promptFile != "" {
+		m.formValidation[fieldPromptFile] = "Use either a prompt or a prompt file, not both"
 		valid = false
 	}
 
@@ -1130,8 +1878,7 @@ func (m *Model) validateForm() bool {
 			valid = false
 		} else {
 			// Use cron parser with seconds support
-			parser := cron.NewParser(cron.Second | cron.Minute | cron.Hour | cron.Dom | cron.Month | cron.Dow)
-			if _, err := parser.Parse(cronExpr); err != nil {
+			if _, err := cronexpr.Parser.Parse(cronExpr); err != nil {
 				m.formValidation[fieldCron] = "Invalid cron format"
 				valid = false
 			}
@@ -1147,65 +1894,354 @@ func (m *Model) validateForm() bool {
 		}
 	}
 
-	return valid
-}
-
-func (m *Model) updateForm(msg tea.KeyMsg) (tea.Model, tea.Cmd) {
-	var cmd tea.Cmd
-
-	// Handle cron helper mode
-	if m.showCronHelper {
-		switch msg.String() {
-		case "up", "k":
-			if m.cronHelperIndex > 0 {
-				m.cronHelperIndex--
-			}
-			return m, nil
-		case "down", "j":
-			if m.cronHelperIndex < len(m.cronPresets)-1 {
-				m.cronHelperIndex++
-			}
-			return m, nil
-		case "enter":
-			// Apply selected preset
-			m.formInputs[fieldCron].SetValue(m.cronPresets[m.cronHelperIndex].expr)
-			m.showCronHelper = false
-			m.validateForm()
-			return m, nil
-		case "esc", "?":
-			m.showCronHelper = false
-			return m, nil
+	// Validate the container image and that docker can run it
+	if image := strings.TrimSpace(m.formInputs[fieldContainer].Value()); image != "" {
+		if !db.ValidContainer(image) {
+			m.formValidation[fieldContainer] = "Invalid image name"
+			valid = false
+		} else if _, err := executor.LookupDocker(); err != nil {
+			m.formValidation[fieldContainer] = "docker not found on PATH"
+			valid = false
 		}
-		return m, nil
 	}
 
-	switch msg.String() {
-	case "esc":
-		m.currentView = ViewList
-		m.resetForm()
-		return m, nil
-	case "?":
-		// Show cron helper when in cron field (only for recurring tasks)
-		if m.formFocus == fieldCron && !m.isOneOff {
-			m.showCronHelper = true
-			m.cronHelperIndex = 0
-			return m, nil
+	// Validate prompt file exists relative to the working directory
+	if promptFile != "" && m.formValidation[fieldPromptFile] == "" {
+		if workDir == "" {
+			workDir = "."
 		}
-	case "left", "right", "h", "l":
-		// Handle toggle fields
-		if m.formFocus == fieldTaskType {
-			m.isOneOff = !m.isOneOff
-			m.validateForm()
-			return m, nil
+		path := executor.ResolvePath(workDir, promptFile)
+		if info, err := os.Stat(path); err != nil || info.IsDir() {
+			m.formValidation[fieldPromptFile] = "File not found: " + path
+			valid = false
 		}
-		if m.formFocus == fieldScheduleMode && m.isOneOff {
-			m.runNow = !m.runNow
-			m.validateForm()
-			return m, nil
+	}
+
+	// Validate stdin file exists relative to the working directory
+	if stdinFile := strings.TrimSpace(m.formInputs[fieldStdinFile].Value()); stdinFile != "" {
+		if workDir == "" {
+			workDir = "."
 		}
-	case "tab":
-		nextField := m.getNextFormField(m.formFocus)
-		m.focusFormField(nextField)
+		path := executor.ResolvePath(workDir, stdinFile)
+		if info, err := os.Stat(path); err != nil || info.IsDir() {
+			m.formValidation[fieldStdinFile] = "File not found: " + path
+			valid = false
+		}
+	}
+
+	// Validate long-run alert (if provided)
+	if _, err := parseSeconds(m.formInputs[fieldAlertAfter].Value(), db.MaxAlertAfterSeconds); err != nil {
+		m.formValidation[fieldAlertAfter] = err.Error()
+		valid = false
+	}
+
+	// Validate failure limit (if provided)
+	if _, err := parseCount(m.formInputs[fieldDisableAfter].Value(), db.MaxDisableAfterFailures); err != nil {
+		m.formValidation[fieldDisableAfter] = err.Error()
+		valid = false
+	}
+
+	// Validate webhook URLs (if provided)
+	relaxed, _ := m.db.GetRelaxedWebhookURLs()
+	if u := strings.TrimSpace(m.formInputs[fieldDiscordWebhook].Value()); u != "" && u != db.WebhookOptOut {
+		if err := webhook.ValidateDiscordURL(u, relaxed); err != nil {
+			m.formValidation[fieldDiscordWebhook] = "URL " + err.Error()
+			valid = false
+		}
+	}
+	if u := strings.TrimSpace(m.formInputs[fieldSlackWebhook].Value()); u != "" && u != db.WebhookOptOut {
+		if err := webhook.ValidateSlackURL(u, relaxed); err != nil {
+			m.formValidation[fieldSlackWebhook] = "URL " + err.Error()
+			valid = false
+		}
+	}
+
+	// Validate tags
+	for _, tag := range parseTags(m.formInputs[fieldTags].Value()) {
+		if !db.ValidTag(tag) {
+			m.formValidation[fieldTags] = "Tags are comma-separated, max 32 chars, no spaces"
+			valid = false
+			break
+		}
+	}
+
+	// Validate usage threshold override (if provided)
+	if _, err := parseThresholdOverride(m.formInputs[fieldUsageThreshold].Value()); err != nil {
+		m.formValidation[fieldUsageThreshold] = err.Error()
+		valid = false
+	}
+
+	// Validate jitter (recurring only)
+	if !m.isOneOff {
+		if _, err := parseSeconds(m.formInputs[fieldJitter].Value(), db.MaxJitterSeconds); err != nil {
+			m.formValidation[fieldJitter] = err.Error()
+			valid = false
+		}
+		from, errFrom := parseFormTime(m.formInputs[fieldActiveFrom].Value())
+		if errFrom != nil {
+			m.formValidation[fieldActiveFrom] = errFrom.Error()
+			valid = false
+		}
+		until, errUntil := parseFormTime(m.formInputs[fieldActiveUntil].Value())
+		if errUntil != nil {
+			m.formValidation[fieldActiveUntil] = errUntil.Error()
+			valid = false
+		} else if from != nil && until != nil && !until.After(*from) {
+			m.formValidation[fieldActiveUntil] = "Must be after Active From"
+			valid = false
+		}
+	}
+
+	return valid
+}
+
+// outputFormats is the cycle order for the output format selector
+var outputFormats = []string{db.OutputFormatText, db.OutputFormatJSON, db.OutputFormatStreamJSON}
+
+// webhookDetails is the cycle order for the webhook detail selector
+var webhookDetails = []string{"", db.WebhookDetailFull, db.WebhookDetailSummary, db.WebhookDetailStatus}
+
+// cycleOption returns the option after (or before) current in options
+func cycleOption(options []string, current string, backward bool) string {
+	idx := 0
+	for i, option := range options {
+		if option == current {
+			idx = i
+		}
+	}
+	if backward {
+		idx += len(options) - 1
+	} else {
+		idx++
+	}
+	return options[idx%len(options)]
+}
+
+// parseTags splits the comma-separated tags input into normalized tags
+func parseTags(val string) []string {
+	return db.NormalizeTags(strings.Split(val, ","))
+}
+
+// parseThresholdOverride parses the optional per-task threshold; empty means use the global setting
+func parseThresholdOverride(val string) (*float64, error) {
+	val = strings.TrimSpace(val)
+	if val == "" {
+		return nil, nil
+	}
+	var threshold float64
+	if _, err := fmt.Sscanf(val, "%f", &threshold); err != nil {
+		return nil, fmt.Errorf("Must be a number")
+	}
+	if threshold < 0 || threshold > 100 {
+		return nil, fmt.Errorf("Must be between 0 and 100")
+	}
+	return &threshold, nil
+}
+
+// parseFormTime parses an optional "YYYY-MM-DD HH:MM" local time; empty means unset
+func parseFormTime(val string) (*time.Time, error) {
+	val = strings.TrimSpace(val)
+	if val == "" {
+		return nil, nil
+	}
+	t, err := time.ParseInLocation("2006-01-02 15:04", val, time.Local)
+	if err != nil {
+		return nil, fmt.Errorf("Use YYYY-MM-DD HH:MM")
+	}
+	return &t, nil
+}
+
+// parseSeconds parses an optional whole number of seconds up to limit; empty means 0
+func parseSeconds(val string, limit int) (int, error) {
+	val = strings.TrimSpace(val)
+	if val == "" {
+		return 0, nil
+	}
+	seconds, err := strconv.Atoi(val)
+	if err != nil {
+		return 0, fmt.Errorf("Must be a whole number of seconds")
+	}
+	if seconds < 0 || seconds > limit {
+		return 0, fmt.Errorf("Must be between 0 and %d", limit)
+	}
+	return seconds, nil
+}
+
+// parseCount parses an optional whole-number field; empty means 0
+func parseCount(val string, limit int) (int, error) {
+	val = strings.TrimSpace(val)
+	if val == "" {
+		return 0, nil
+	}
+	n, err := strconv.Atoi(val)
+	if err != nil {
+		return 0, fmt.Errorf("Must be a whole number")
+	}
+	if n < 0 || n > limit {
+		return 0, fmt.Errorf("Must be between 0 and %d", limit)
+	}
+	return n, nil
+}
+
+// updateCronEdit handles keys while editing the selected task's schedule from the list
+func (m *Model) updateCronEdit(msg tea.KeyMsg) (tea.Model, tea.Cmd) {
+	var cmd tea.Cmd
+
+	// Preset helper, shared with the task form
+	if m.showCronHelper {
+		switch msg.String() {
+		case "up", "k":
+			if m.cronHelperIndex > 0 {
+				m.cronHelperIndex--
+			}
+		case "down", "j":
+			if m.cronHelperIndex < len(m.cronPresets)-1 {
+				m.cronHelperIndex++
+			}
+		case "enter":
+			m.cronEditInput.SetValue(m.cronPresets[m.cronHelperIndex].expr)
+			m.cronEditInput.CursorEnd()
+			m.showCronHelper = false
+		case "esc", "?":
+			m.showCronHelper = false
+		}
+		return m, nil
+	}
+
+	switch msg.String() {
+	case "esc":
+		m.cronEditMode = false
+		m.cronEditTask = nil
+		m.cronEditInput.Blur()
+		return m, nil
+	case "?":
+		m.showCronHelper = true
+		m.cronHelperIndex = 0
+		return m, nil
+	case "enter":
+		expr := strings.TrimSpace(m.cronEditInput.Value())
+		if _, err := cronexpr.Parser.Parse(expr); err != nil {
+			m.setStatus("Invalid cron expression", true)
+			return m, nil
+		}
+		task := m.cronEditTask
+		m.cronEditMode = false
+		m.cronEditTask = nil
+		m.cronEditInput.Blur()
+		return m, m.saveSchedule(task, expr)
+	}
+
+	m.cronEditInput, cmd = m.cronEditInput.Update(msg)
+	return m, cmd
+}
+
+// saveSchedule updates a task's cron expression and reschedules it
+func (m *Model) saveSchedule(task *db.Task, expr string) tea.Cmd {
+	return func() tea.Msg {
+		// Re-read so fields changed elsewhere since the list loaded aren't overwritten
+		fresh, err := m.db.GetTask(task.ID)
+		if err != nil {
+			return errMsg{err}
+		}
+		fresh.CronExpr = expr
+		if err := m.db.UpdateTask(fresh); err != nil {
+			return errMsg{err}
+		}
+		if m.scheduler != nil {
+			_ = m.scheduler.UpdateTask(fresh)
+		}
+		return taskCreatedMsg{fresh}
+	}
+}
+
+func (m *Model) updateForm(msg tea.KeyMsg) (tea.Model, tea.Cmd) {
+	var cmd tea.Cmd
+
+	// Handle cron helper mode
+	if m.showCronHelper {
+		switch msg.String() {
+		case "up", "k":
+			if m.cronHelperIndex > 0 {
+				m.cronHelperIndex--
+			}
+			return m, nil
+		case "down", "j":
+			if m.cronHelperIndex < len(m.cronPresets)-1 {
+				m.cronHelperIndex++
+			}
+			return m, nil
+		case "enter":
+			// Apply selected preset
+			m.formInputs[fieldCron].SetValue(m.cronPresets[m.cronHelperIndex].expr)
+			m.showCronHelper = false
+			m.validateForm()
+			return m, nil
+		case "esc", "?":
+			m.showCronHelper = false
+			return m, nil
+		}
+		return m, nil
+	}
+
+	switch msg.String() {
+	case "esc":
+		m.currentView = ViewList
+		m.resetForm()
+		return m, nil
+	case "?":
+		// Show cron helper when in cron field (only for recurring tasks)
+		if m.formFocus == fieldCron && !m.isOneOff {
+			m.showCronHelper = true
+			m.cronHelperIndex = 0
+			return m, nil
+		}
+	case "left", "right", "h", "l":
+		// Handle toggle fields
+		if m.formFocus == fieldTaskType {
+			m.isOneOff = !m.isOneOff
+			m.validateForm()
+			return m, nil
+		}
+		if m.formFocus == fieldScheduleMode && m.isOneOff {
+			m.runNow = !m.runNow
+			m.validateForm()
+			return m, nil
+		}
+		if m.formFocus == fieldOutputFormat {
+			m.outputFormat = cycleOption(outputFormats, m.outputFormat, msg.String() == "left" || msg.String() == "h")
+			return m, nil
+		}
+		if m.formFocus == fieldWebhookDetail {
+			m.webhookDetail = cycleOption(webhookDetails, m.webhookDetail, msg.String() == "left" || msg.String() == "h")
+			return m, nil
+		}
+		if m.formFocus == fieldQuietHours && !m.isOneOff {
+			m.runInQuiet = !m.runInQuiet
+			return m, nil
+		}
+		if m.formFocus == fieldAnsi {
+			m.keepAnsi = !m.keepAnsi
+			return m, nil
+		}
+		if m.formFocus == fieldPrevOutput {
+			m.includePrev = !m.includePrev
+			return m, nil
+		}
+		if m.formFocus == fieldTemplate {
+			m.templated = !m.templated
+			return m, nil
+		}
+		if m.formFocus == fieldSession {
+			m.resume = !m.resume
+			return m, nil
+		}
+		if m.formFocus == fieldPermissions {
+			m.askPerms = !m.askPerms
+			return m, nil
+		}
+	case "tab":
+		nextField := m.getNextFormField(m.formFocus)
+		m.focusFormField(nextField)
 		m.validateForm()
 		return m, textinput.Blink
 	case "shift+tab":
@@ -1219,12 +2255,16 @@ func (m *Model) updateForm(msg tea.KeyMsg) (tea.Model, tea.Cmd) {
 		}
 		return m, nil
 	case "enter":
-		// In textarea (prompt), enter adds newline - don't navigate
+		// In textareas (prompt, system prompt), enter adds newline - don't navigate
 		if m.formFocus == fieldPrompt {
 			m.promptInput, cmd = m.promptInput.Update(msg)
 			m.validateForm()
 			return m, cmd
 		}
+		if m.formFocus == fieldSystemPrompt {
+			m.systemPrompt, cmd = m.systemPrompt.Update(msg)
+			return m, cmd
+		}
 		// On last visible field, submit if valid
 		if m.formFocus == fieldSlackWebhook {
 			if m.validateForm() {
@@ -1242,9 +2282,11 @@ func (m *Model) updateForm(msg tea.KeyMsg) (tea.Model, tea.Cmd) {
 	// Update the focused input
 	if m.formFocus == fieldPrompt {
 		m.promptInput, cmd = m.promptInput.Update(msg)
+	} else if m.formFocus == fieldSystemPrompt {
+		m.systemPrompt, cmd = m.systemPrompt.Update(msg)
 	} else if m.formFocus == fieldScheduledAt {
 		m.scheduledAt, cmd = m.scheduledAt.Update(msg)
-	} else if m.formFocus != fieldTaskType && m.formFocus != fieldScheduleMode {
+	} else if m.formFocus != fieldTaskType && m.formFocus != fieldScheduleMode && m.formFocus != fieldQuietHours && m.formFocus != fieldOutputFormat && m.formFocus != fieldWebhookDetail && m.formFocus != fieldAnsi && m.formFocus != fieldPrevOutput && m.formFocus != fieldTemplate && m.formFocus != fieldSession && m.formFocus != fieldPermissions {
 		// Don't update toggle fields as text inputs
 		m.formInputs[m.formFocus], cmd = m.formInputs[m.formFocus].Update(msg)
 	}
@@ -1258,7 +2300,44 @@ func (m *Model) updateForm(msg tea.KeyMsg) (tea.Model, tea.Cmd) {
 func (m *Model) updateOutput(msg tea.KeyMsg) (tea.Model, tea.Cmd) {
 	var cmd tea.Cmd
 
+	if m.noteEditMode {
+		return m.updateNoteEdit(msg)
+	}
+	if m.outputSearchMode {
+		return m.updateOutputSearch(msg)
+	}
+	if cmd, ok := m.switchView(msg.String()); ok {
+		return m, cmd
+	}
+
+	// While a search is active, n/p move between matches and esc clears it
+	if m.outputQuery != "" {
+		switch msg.String() {
+		case "n", "p":
+			if len(m.outputMatchLines) > 0 {
+				step := 1
+				if msg.String() == "p" {
+					step = -1
+				}
+				m.outputMatch = (m.outputMatch + step + len(m.outputMatchLines)) % len(m.outputMatchLines)
+				m.refreshOutput()
+				m.scrollToMatch()
+			}
+			return m, nil
+		case "esc":
+			m.clearOutputSearch()
+			m.refreshOutput()
+			return m, nil
+		}
+	}
+
 	switch msg.String() {
+	case "/":
+		m.outputSearchMode = true
+		m.outputSearchInput.SetValue(m.outputQuery)
+		m.outputSearchInput.CursorEnd()
+		m.outputSearchInput.Focus()
+		return m, textinput.Blink
 	case "esc", "q":
 		m.currentView = ViewList
 		return m, nil
@@ -1266,12 +2345,200 @@ func (m *Model) updateOutput(msg tea.KeyMsg) (tea.Model, tea.Cmd) {
 		return m, m.loadTaskRuns(m.selectedTask.ID)
 	case "t":
 		return m, m.toggleTask(m.selectedTask.ID)
+	case "R":
+		// Rerun as a new one-off task so the original schedule is untouched
+		m.currentView = ViewAdd
+		m.resetForm()
+		m.fillForm(m.selectedTask)
+		m.formInputs[fieldName].SetValue(m.selectedTask.Name + " (rerun)")
+		m.isOneOff = true
+		m.runNow = true
+		m.scheduledAt.SetValue("")
+		m.focusFormField(fieldPrompt)
+		return m, textinput.Blink
+	case "d":
+		m.showDiff = !m.showDiff
+		m.outputMatch = 0
+		m.refreshOutput()
+		m.viewport.GotoTop()
+		return m, nil
+	case "m":
+		// Switch between rendered markdown and the raw output
+		m.rawOutput = !m.rawOutput
+		m.refreshOutput()
+		return m, nil
+	case "N":
+		// Add or edit a note on the shown run
+		if m.runIndex < len(m.taskRuns) {
+			m.noteEditMode = true
+			m.noteInput.SetValue(m.taskRuns[m.runIndex].Notes)
+			m.noteInput.CursorEnd()
+			m.noteInput.Focus()
+			return m, textinput.Blink
+		}
+		return m, nil
+	case "n":
+		// Older run; fetch the next page once the loaded runs are exhausted
+		if m.runIndex+1 < len(m.taskRuns) {
+			m.showRun(m.runIndex + 1)
+		} else if len(m.taskRuns) < m.runTotal && !m.loadingRuns {
+			m.loadingRuns = true
+			return m, m.loadMoreTaskRuns(m.selectedTask.ID, len(m.taskRuns))
+		}
+		return m, nil
+	case "p":
+		// Newer run
+		if m.runIndex > 0 {
+			m.showRun(m.runIndex - 1)
+		}
+		return m, nil
 	}
 
 	m.viewport, cmd = m.viewport.Update(msg)
 	return m, cmd
 }
 
+// updateOutputSearch handles keys while the output search query is typed,
+// jumping to the first match as it changes
+func (m *Model) updateOutputSearch(msg tea.KeyMsg) (tea.Model, tea.Cmd) {
+	var cmd tea.Cmd
+	switch msg.String() {
+	case "esc":
+		m.clearOutputSearch()
+		m.refreshOutput()
+		return m, nil
+	case "enter":
+		// Keep the query so n/p can move between matches
+		m.outputSearchMode = false
+		m.outputSearchInput.Blur()
+		return m, nil
+	}
+	m.outputSearchInput, cmd = m.outputSearchInput.Update(msg)
+	if query := m.outputSearchInput.Value(); query != m.outputQuery {
+		m.outputQuery = query
+		m.outputMatch = 0
+		m.refreshOutput()
+		m.scrollToMatch()
+	}
+	return m, cmd
+}
+
+// clearOutputSearch ends any output search
+func (m *Model) clearOutputSearch() {
+	m.outputSearchMode = false
+	m.outputSearchInput.Blur()
+	m.outputSearchInput.SetValue("")
+	m.outputQuery = ""
+	m.outputMatch = 0
+	m.outputMatchLines = nil
+}
+
+// refreshOutput renders the output view content, highlighting matches of the
+// active search
+func (m *Model) refreshOutput() {
+	content := m.renderOutputContent()
+	m.outputMatchLines = nil
+	if m.outputQuery != "" {
+		content, m.outputMatchLines = highlightMatches(content, m.outputQuery, m.outputMatch)
+	}
+	m.viewport.SetContent(content)
+}
+
+// scrollToMatch centers the current search match in the viewport
+func (m *Model) scrollToMatch() {
+	if m.outputMatch < len(m.outputMatchLines) {
+		m.viewport.SetYOffset(m.outputMatchLines[m.outputMatch] - m.viewport.Height/2)
+	}
+}
+
+// highlightMatches marks the case-insensitive occurrences of query in content,
+// styling the one at index current apart, and returns the line of each match.
+// Lines with a match lose their other styling.
+func highlightMatches(content, query string, current int) (string, []int) {
+	re := regexp.MustCompile("(?i)" + regexp.QuoteMeta(query))
+	lines := strings.Split(content, "\n")
+	var matchLines []int
+	for i, line := range lines {
+		plain := ansi.Strip(line)
+		locs := re.FindAllStringIndex(plain, -1)
+		if len(locs) == 0 {
+			continue
+		}
+		var b strings.Builder
+		prev := 0
+		for _, loc := range locs {
+			style := searchMatchStyle
+			if len(matchLines) == current {
+				style = searchCurrentStyle
+			}
+			b.WriteString(plain[prev:loc[0]])
+			b.WriteString(style.Render(plain[loc[0]:loc[1]]))
+			prev = loc[1]
+			matchLines = append(matchLines, i)
+		}
+		b.WriteString(plain[prev:])
+		lines[i] = b.String()
+	}
+	return strings.Join(lines, "\n"), matchLines
+}
+
+// updateNoteEdit handles keys while a run note is being edited
+func (m *Model) updateNoteEdit(msg tea.KeyMsg) (tea.Model, tea.Cmd) {
+	var cmd tea.Cmd
+	switch msg.String() {
+	case "esc":
+		m.noteEditMode = false
+		m.noteInput.Blur()
+		return m, nil
+	case "enter":
+		m.noteEditMode = false
+		m.noteInput.Blur()
+		if m.runIndex >= len(m.taskRuns) {
+			return m, nil
+		}
+		return m, m.saveRunNote(m.taskRuns[m.runIndex].ID, strings.TrimSpace(m.noteInput.Value()))
+	}
+	m.noteInput, cmd = m.noteInput.Update(msg)
+	return m, cmd
+}
+
+// showRun displays the loaded run at index in the output view
+func (m *Model) showRun(index int) {
+	if index < 0 || index >= len(m.taskRuns) {
+		return
+	}
+	m.runIndex = index
+	m.loadRunOutputs()
+	m.outputMatch = 0
+	m.refreshOutput()
+	m.viewport.GotoTop()
+}
+
+// loadRunOutputs reads the full output of the shown run and the one it is
+// diffed against; file-backed runs are loaded with only a preview
+func (m *Model) loadRunOutputs() {
+	if m.runIndex >= len(m.taskRuns) {
+		return
+	}
+	for _, run := range m.taskRuns[m.runIndex:min(m.runIndex+2, len(m.taskRuns))] {
+		_ = m.db.LoadRunOutput(run)
+	}
+}
+
+func (m *Model) updateMetrics(msg tea.KeyMsg) (tea.Model, tea.Cmd) {
+	if cmd, ok := m.switchView(msg.String()); ok {
+		return m, cmd
+	}
+
+	switch msg.String() {
+	case "esc", "q", "m":
+		m.currentView = ViewList
+	case "r":
+		return m, m.fetchRunSummary()
+	}
+	return m, nil
+}
+
 func (m *Model) updateSettings(msg tea.KeyMsg) (tea.Model, tea.Cmd) {
 	var cmd tea.Cmd
 
@@ -1280,14 +2547,65 @@ func (m *Model) updateSettings(msg tea.KeyMsg) (tea.Model, tea.Cmd) {
 		m.currentView = ViewList
 		return m, nil
 	case "enter", "ctrl+s":
-		return m, m.saveThreshold()
+		return m, m.saveSettings()
+	case "tab", "down":
+		m.focusSetting((m.settingsFocus + 1) % settingCount)
+		return m, nil
+	case "shift+tab", "up":
+		m.focusSetting((m.settingsFocus + settingCount - 1) % settingCount)
+		return m, nil
+	}
+
+	if m.settingsFocus == settingConfirmRun || m.settingsFocus == settingUsageCheck || m.settingsFocus == settingRenderMarkdown {
+		switch msg.String() {
+		case " ", "left", "right", "h", "l":
+			switch m.settingsFocus {
+			case settingConfirmRun:
+				m.confirmRunToggle = !m.confirmRunToggle
+			case settingRenderMarkdown:
+				m.renderMdToggle = !m.renderMdToggle
+			default:
+				m.usageCheckToggle = !m.usageCheckToggle
+			}
+		}
+		return m, nil
 	}
 
-	m.thresholdInput, cmd = m.thresholdInput.Update(msg)
+	switch m.settingsFocus {
+	case settingQuietStart:
+		m.quietStartInput, cmd = m.quietStartInput.Update(msg)
+	case settingQuietEnd:
+		m.quietEndInput, cmd = m.quietEndInput.Update(msg)
+	default:
+		m.thresholdInput, cmd = m.thresholdInput.Update(msg)
+	}
 	return m, cmd
 }
 
-func (m *Model) saveThreshold() tea.Cmd {
+// focusSetting moves focus to a settings field, blurring the other text inputs
+func (m *Model) focusSetting(field int) {
+	m.settingsFocus = field
+	m.thresholdInput.Blur()
+	m.quietStartInput.Blur()
+	m.quietEndInput.Blur()
+	switch field {
+	case settingThreshold:
+		m.thresholdInput.Focus()
+	case settingQuietStart:
+		m.quietStartInput.Focus()
+	case settingQuietEnd:
+		m.quietEndInput.Focus()
+	}
+}
+
+func (m *Model) saveSettings() tea.Cmd {
+	confirmBeforeRun := m.confirmRunToggle
+	renderMarkdown := m.renderMdToggle
+	usageCheck := m.usageCheckToggle
+	quiet := db.QuietHours{
+		Start: strings.TrimSpace(m.quietStartInput.Value()),
+		End:   strings.TrimSpace(m.quietEndInput.Value()),
+	}
 	return func() tea.Msg {
 		val := strings.TrimSpace(m.thresholdInput.Value())
 		var threshold float64
@@ -1297,36 +2615,145 @@ func (m *Model) saveThreshold() tea.Cmd {
 		if threshold < 0 || threshold > 100 {
 			return errMsg{fmt.Errorf("threshold must be between 0 and 100")}
 		}
+		if (quiet.Start == "") != (quiet.End == "") ||
+			(quiet.Start != "" && (!db.ValidClock(quiet.Start) || !db.ValidClock(quiet.End))) {
+			return errMsg{fmt.Errorf("quiet hours must both be HH:MM or both empty")}
+		}
 		if err := m.db.SetUsageThreshold(threshold); err != nil {
 			return errMsg{err}
 		}
-		return thresholdSavedMsg{threshold: threshold}
+		if err := m.db.SetUsageCheckEnabled(usageCheck); err != nil {
+			return errMsg{err}
+		}
+		if err := m.db.SetConfirmBeforeRun(confirmBeforeRun); err != nil {
+			return errMsg{err}
+		}
+		if err := m.db.SetRenderMarkdown(renderMarkdown); err != nil {
+			return errMsg{err}
+		}
+		if err := m.db.SetQuietHours(quiet); err != nil {
+			return errMsg{err}
+		}
+		return settingsSavedMsg{threshold: threshold, confirmBeforeRun: confirmBeforeRun, renderMarkdown: renderMarkdown, usageCheck: usageCheck, quietHours: quiet}
+	}
+}
+
+// daemonCheckInterval is how often client mode confirms the daemon is still running
+const daemonCheckInterval = 5 * time.Second
+
+// checkDaemon re-checks daemon liveness in client mode and flags a dead daemon
+func (m *Model) checkDaemon() {
+	if !m.daemonMode || m.daemonAlive == nil || time.Since(m.daemonCheckedAt) < daemonCheckInterval {
+		return
+	}
+	m.daemonCheckedAt = time.Now()
+	alive := m.daemonAlive()
+	if !alive && !m.daemonDead {
+		m.setStatus("Daemon stopped; scheduled tasks are not running", true)
+	}
+	m.daemonDead = !alive
+}
+
+// takeOverScheduling starts a local scheduler after the daemon died
+func (m *Model) takeOverScheduling() error {
+	sched := scheduler.New(m.db)
+	if err := sched.Start(); err != nil {
+		return fmt.Errorf("starting scheduler: %w", err)
 	}
+	m.scheduler = sched
+	m.ownsScheduler = true
+	m.daemonMode = false
+	m.daemonDead = false
+	return nil
+}
+
+// startTask runs a task immediately via the scheduler, or the executor in daemon mode
+func (m *Model) startTask(task *db.Task) {
+	if m.scheduler != nil {
+		if err := m.scheduler.RunTaskNow(task.ID); err != nil {
+			m.setStatus("Error: "+err.Error(), true)
+			return
+		}
+	} else if m.executor != nil {
+		// In daemon mode, run directly via executor
+		m.executor.ExecuteAsync(task, db.TriggerManual)
+	} else {
+		return
+	}
+	m.runningTasks[task.ID] = true
+	m.updateTable()
+	m.setStatus("Started: "+task.Name, false)
 }
 
 func (m *Model) saveTask() tea.Cmd {
 	return func() tea.Msg {
 		name := strings.TrimSpace(m.formInputs[fieldName].Value())
 		prompt := strings.TrimSpace(m.promptInput.Value())
+		promptFile := strings.TrimSpace(m.formInputs[fieldPromptFile].Value())
+		systemPrompt := strings.TrimSpace(m.systemPrompt.Value())
 		workingDir := strings.TrimSpace(m.formInputs[fieldWorkingDir].Value())
 		discordWebhook := strings.TrimSpace(m.formInputs[fieldDiscordWebhook].Value())
 		slackWebhook := strings.TrimSpace(m.formInputs[fieldSlackWebhook].Value())
 
-		if name == "" || prompt == "" {
-			return errMsg{fmt.Errorf("name and prompt are required")}
+		if name == "" || (prompt == "" && promptFile == "") {
+			return errMsg{fmt.Errorf("name and prompt (or prompt file) are required")}
 		}
 
+		// Store an absolute path so the daemon runs the task in the same place
 		if workingDir == "" {
-			workingDir = "."
+			dir, err := m.db.GetDefaultWorkingDir()
+			if err != nil {
+				return errMsg{err}
+			}
+			workingDir = dir
+		} else if abs, err := filepath.Abs(workingDir); err == nil {
+			workingDir = abs
+		}
+
+		thresholdOverride, err := parseThresholdOverride(m.formInputs[fieldUsageThreshold].Value())
+		if err != nil {
+			return errMsg{fmt.Errorf("usage threshold override: %w", err)}
+		}
+
+		jitter, err := parseSeconds(m.formInputs[fieldJitter].Value(), db.MaxJitterSeconds)
+		if err != nil {
+			return errMsg{fmt.Errorf("jitter: %w", err)}
+		}
+
+		alertAfter, err := parseSeconds(m.formInputs[fieldAlertAfter].Value(), db.MaxAlertAfterSeconds)
+		if err != nil {
+			return errMsg{fmt.Errorf("alert after: %w", err)}
+		}
+
+		disableAfter, err := parseCount(m.formInputs[fieldDisableAfter].Value(), db.MaxDisableAfterFailures)
+		if err != nil {
+			return errMsg{fmt.Errorf("disable after failures: %w", err)}
 		}
 
 		task := &db.Task{
-			Name:           name,
-			Prompt:         prompt,
-			WorkingDir:     workingDir,
-			DiscordWebhook: discordWebhook,
-			SlackWebhook:   slackWebhook,
-			Enabled:        true,
+			Name:                   name,
+			Prompt:                 prompt,
+			PromptFile:             promptFile,
+			StdinFile:              strings.TrimSpace(m.formInputs[fieldStdinFile].Value()),
+			SystemPrompt:           systemPrompt,
+			OutputFormat:           m.outputFormat,
+			WorkingDir:             workingDir,
+			Tags:                   parseTags(m.formInputs[fieldTags].Value()),
+			DiscordWebhook:         discordWebhook,
+			SlackWebhook:           slackWebhook,
+			UsageThresholdOverride: thresholdOverride,
+			RunDuringQuietHours:    m.runInQuiet && !m.isOneOff,
+			AlertAfterSeconds:      alertAfter,
+			DisableAfterFailures:   disableAfter,
+			StripAnsi:              !m.keepAnsi,
+			WebhookDetail:          m.webhookDetail,
+			IncludePreviousOutput:  m.includePrev,
+			TemplatePrompt:         m.templated,
+			ResumeSession:          m.resume,
+			SkipPermissions:        !m.askPerms,
+			AllowedTools:           strings.TrimSpace(m.formInputs[fieldAllowedTools].Value()),
+			Container:              strings.TrimSpace(m.formInputs[fieldContainer].Value()),
+			Enabled:                true,
 		}
 
 		// Handle task type
@@ -1352,6 +2779,13 @@ func (m *Model) saveTask() tea.Cmd {
 				return errMsg{fmt.Errorf("cron expression is required for recurring tasks")}
 			}
 			task.CronExpr = cronExpr
+			task.JitterSeconds = jitter
+			if task.ActiveFrom, err = parseFormTime(m.formInputs[fieldActiveFrom].Value()); err != nil {
+				return errMsg{fmt.Errorf("active from: %w", err)}
+			}
+			if task.ActiveUntil, err = parseFormTime(m.formInputs[fieldActiveUntil].Value()); err != nil {
+				return errMsg{fmt.Errorf("active until: %w", err)}
+			}
 		}
 
 		if m.editingTask != nil {
@@ -1361,6 +2795,12 @@ func (m *Model) saveTask() tea.Cmd {
 			if err := m.db.UpdateTask(task); err != nil {
 				return errMsg{err}
 			}
+			if !task.ResumeSession {
+				// Turning resume off forgets the session
+				if err := m.db.SetTaskSessionID(task.ID, ""); err != nil {
+					return errMsg{err}
+				}
+			}
 			if m.scheduler != nil {
 				_ = m.scheduler.UpdateTask(task)
 			}
@@ -1405,13 +2845,32 @@ func (m *Model) toggleTask(id int64) tea.Cmd {
 	}
 }
 
+// runPageSize is how many runs the output view fetches at a time
+const runPageSize = 20
+
 func (m *Model) loadTaskRuns(taskID int64) tea.Cmd {
 	return func() tea.Msg {
-		runs, err := m.db.GetTaskRuns(taskID, 20)
+		runs, err := m.db.GetTaskRuns(taskID, runPageSize)
+		if err != nil {
+			return errMsg{err}
+		}
+		total, err := m.db.CountTaskRuns(taskID)
+		if err != nil {
+			total = len(runs)
+		}
+		stats, _ := m.db.GetTaskRunStats(taskID, 50) // Header summary only; nil hides it
+		return taskRunsLoadedMsg{runs: runs, total: total, stats: stats}
+	}
+}
+
+// loadMoreTaskRuns fetches the next page of older runs starting at offset
+func (m *Model) loadMoreTaskRuns(taskID int64, offset int) tea.Cmd {
+	return func() tea.Msg {
+		runs, err := m.db.GetTaskRunsPage(taskID, runPageSize, offset)
 		if err != nil {
 			return errMsg{err}
 		}
-		return taskRunsLoadedMsg{runs}
+		return moreTaskRunsMsg{taskID: taskID, runs: runs}
 	}
 }
 
@@ -1435,21 +2894,36 @@ func (m Model) View() string {
 		content = m.renderOutput()
 	case ViewSettings:
 		content = m.renderSettings()
+	case ViewMetrics:
+		content = m.renderMetrics()
 	}
 
 	// Render the base content
 	baseView := appStyle.Render(content)
 
-	// Overlay delete confirmation modal if active
+	// Overlay confirmation modals if active
 	if m.confirmDelete {
-		return m.renderDeleteModal(baseView)
+		return m.renderConfirmModal(fmt.Sprintf("Delete task '%s'?", m.deleteTaskName), m.deleteConfirmFocus, lipgloss.Color("#FF6B6B"))
+	}
+	if m.confirmRun && m.runTask != nil {
+		return m.renderConfirmModal(fmt.Sprintf("Run task '%s' now?", m.runTask.Name), m.runConfirmFocus, primaryColor)
+	}
+	if m.confirmCancel && m.runningCursor < len(m.runningRuns) {
+		name := m.taskName(m.runningRuns[m.runningCursor].TaskID)
+		return m.renderConfirmModal(fmt.Sprintf("Cancel the running '%s'?", name), m.cancelConfirmFocus, lipgloss.Color("#FF6B6B"))
+	}
+	if m.showRunning {
+		return m.renderRunningRuns()
+	}
+	if m.apiEquivalent != "" {
+		return m.renderAPIEquivalent()
 	}
 
 	return baseView
 }
 
-// renderDeleteModal renders a centered modal overlay on top of the base view
-func (m Model) renderDeleteModal(baseView string) string {
+// renderConfirmModal renders a centered Yes/No confirmation modal; focus 0 = Yes, 1 = No
+func (m Model) renderConfirmModal(prompt string, focus int, border lipgloss.TerminalColor) string {
 	// Button styles
 	activeButtonStyle := lipgloss.NewStyle().
 		Foreground(lipgloss.Color("#FFFFFF")).
@@ -1466,7 +2940,7 @@ func (m Model) renderDeleteModal(baseView string) string {
 
 	// Modal content
 	var yesBtn, noBtn string
-	if m.deleteConfirmFocus == 0 {
+	if focus == 0 {
 		yesBtn = activeButtonStyle.Render("Yes")
 		noBtn = inactiveButtonStyle.Render("No")
 	} else {
@@ -1480,7 +2954,7 @@ func (m Model) renderDeleteModal(baseView string) string {
 		Bold(true).
 		Foreground(lipgloss.Color("#FFFFFF")).
 		MarginBottom(1).
-		Render(fmt.Sprintf("Delete task '%s'?", m.deleteTaskName))
+		Render(prompt)
 
 	hint := subtitleStyle.Render("←/→ to select • enter to confirm • esc to cancel")
 
@@ -1495,7 +2969,7 @@ func (m Model) renderDeleteModal(baseView string) string {
 	// Modal box style
 	modalStyle := lipgloss.NewStyle().
 		Border(lipgloss.RoundedBorder()).
-		BorderForeground(lipgloss.Color("#FF6B6B")).
+		BorderForeground(border).
 		Padding(1, 4).
 		Background(lipgloss.Color("#1a1a2e")).
 		Align(lipgloss.Center)
@@ -1514,14 +2988,121 @@ func (m Model) renderDeleteModal(baseView string) string {
 	)
 }
 
+// renderRunningRuns renders a centered overlay listing in-progress runs
+// defaultAPIBaseURL is shown in curl commands when public_base_url is unset
+const defaultAPIBaseURL = "http://localhost:8080"
+
+// apiEquivalent returns curl commands that create task through the API and
+// run it, using the same request body the API would accept
+func apiEquivalent(task *db.Task, baseURL string) (string, error) {
+	if baseURL == "" {
+		baseURL = defaultAPIBaseURL
+	}
+	baseURL = strings.TrimRight(baseURL, "/")
+	body, err := json.MarshalIndent(api.TaskToRequest(task), "  ", "  ")
+	if err != nil {
+		return "", err
+	}
+
+	var b strings.Builder
+	b.WriteString("# Create it (the response includes the new task's ID)\n")
+	fmt.Fprintf(&b, "curl -X POST %s/api/v1/tasks \\\n", baseURL)
+	b.WriteString("  -H 'Content-Type: application/json' \\\n")
+	fmt.Fprintf(&b, "  -d '%s'\n\n", shellQuoteBody(string(body)))
+	b.WriteString("# Run this task now\n")
+	fmt.Fprintf(&b, "curl -X POST %s/api/v1/tasks/%d/run\n", baseURL, task.ID)
+	return b.String(), nil
+}
+
+// shellQuoteBody escapes s for use inside single quotes
+func shellQuoteBody(s string) string {
+	return strings.ReplaceAll(s, "'", `'\''`)
+}
+
+// renderAPIEquivalent shows the curl commands without a border so they can be
+// selected and copied from the terminal as-is
+func (m Model) renderAPIEquivalent() string {
+	var b strings.Builder
+	b.WriteString(inputLabelStyle.Render("API equivalent"))
+	b.WriteString("  ")
+	b.WriteString(subtitleStyle.Render("Set public_base_url to change the server URL"))
+	b.WriteString("\n\n")
+	b.WriteString(m.apiEquivalent)
+	b.WriteString("\n")
+	b.WriteString(helpDescStyle.Render("press any key to close"))
+	return appStyle.Render(b.String())
+}
+
+func (m Model) renderRunningRuns() string {
+	var b strings.Builder
+	b.WriteString(inputLabelStyle.Render("Running runs"))
+	b.WriteString("\n\n")
+	if len(m.runningRuns) == 0 {
+		b.WriteString(subtitleStyle.Render("No runs in progress"))
+		b.WriteString("\n")
+	}
+	for i, run := range m.runningRuns {
+		elapsed := time.Since(run.StartedAt).Round(time.Second)
+		line := fmt.Sprintf("#%-5d %-30s %s", run.ID, truncate(m.taskName(run.TaskID), 30), elapsed)
+		if i == m.runningCursor {
+			b.WriteString(lipgloss.NewStyle().
+				Background(primaryColor).
+				Foreground(lipgloss.Color("#FFFFFF")).
+				Bold(true).
+				Render("> " + line))
+		} else {
+			b.WriteString("  " + line)
+		}
+		b.WriteString("\n")
+	}
+	b.WriteString("\n")
+	b.WriteString(helpKeyStyle.Render("↑/↓"))
+	b.WriteString(helpDescStyle.Render(" select • "))
+	b.WriteString(helpKeyStyle.Render("x"))
+	b.WriteString(helpDescStyle.Render(" cancel run • "))
+	b.WriteString(helpKeyStyle.Render("esc"))
+	b.WriteString(helpDescStyle.Render(" close"))
+
+	modal := lipgloss.NewStyle().
+		Border(lipgloss.RoundedBorder()).
+		BorderForeground(primaryColor).
+		Padding(1, 4).
+		Background(lipgloss.Color("#1a1a2e")).
+		Render(b.String())
+
+	return lipgloss.Place(
+		m.width,
+		m.height,
+		lipgloss.Center,
+		lipgloss.Center,
+		modal,
+		lipgloss.WithWhitespaceChars(" "),
+		lipgloss.WithWhitespaceForeground(lipgloss.Color("#333333")),
+	)
+}
+
+// taskName returns the name of a loaded task, or its ID if it isn't loaded
+func (m Model) taskName(id int64) string {
+	for _, task := range m.tasks {
+		if task.ID == id {
+			return task.Name
+		}
+	}
+	return fmt.Sprintf("task %d", id)
+}
+
 func (m Model) renderList() string {
 	var b strings.Builder
 
 	// Header with usage status (right-justified)
 	logo := spriteIcon + " " + logoStyle.Render("Claude Tasks")
 	if m.usageData != nil && m.width > 0 {
-		usageBar := m.renderUsageBar()
+		usageBar := m.renderUsageBar(true)
 		logoWidth := lipgloss.Width(logo)
+		if logoWidth+lipgloss.Width(usageBar)+6 > m.width {
+			// Not enough room for sparklines, fall back to just the bars
+			usageBar = m.renderUsageBar(false)
+		}
 		usageWidth := lipgloss.Width(usageBar)
 		padding := m.width - logoWidth - usageWidth - 4 // account for app padding
 		if padding < 2 {
@@ -1533,7 +3114,42 @@ func (m Model) renderList() string {
 	} else {
 		b.WriteString(logo)
 	}
-	b.WriteString("\n\n")
+	b.WriteString("\n\n")
+
+	// Warn when the daemon this TUI relies on for scheduling has died
+	if m.daemonDead {
+		b.WriteString(statusFail.Render("⚠ Daemon is not running; scheduled tasks won't fire."))
+		b.WriteString(" ")
+		b.WriteString(helpKeyStyle.Render("S"))
+		b.WriteString(helpDescStyle.Render(" take over scheduling here"))
+		b.WriteString("\n\n")
+	}
+
+	// Show the preset helper or inline schedule editor when editing a schedule
+	if m.cronEditMode && m.showCronHelper {
+		b.WriteString(m.renderCronHelper())
+		return b.String()
+	}
+	if m.cronEditMode {
+		hint := "enter save • ? presets • esc cancel"
+		if expr := strings.TrimSpace(m.cronEditInput.Value()); expr != "" {
+			if _, err := cronexpr.Parser.Parse(expr); err == nil {
+				hint = cronexpr.Describe(expr) + " · " + hint
+			} else {
+				hint = "invalid expression · " + hint
+			}
+		}
+		editStyle := lipgloss.NewStyle().
+			Border(lipgloss.RoundedBorder()).
+			BorderForeground(accentColor).
+			Padding(0, 1)
+		b.WriteString(inputLabelStyle.Render("Schedule for " + m.cronEditTask.Name))
+		b.WriteString("  ")
+		b.WriteString(subtitleStyle.Render(hint))
+		b.WriteString("\n")
+		b.WriteString(editStyle.Render(m.cronEditInput.View()))
+		b.WriteString("\n\n")
+	}
 
 	// Show search bar if in search mode
 	if m.searchMode {
@@ -1545,6 +3161,20 @@ func (m Model) renderList() string {
 		b.WriteString("\n\n")
 	}
 
+	// Show the jump prompt with the task enter would select
+	if m.jumpMode {
+		jumpStyle := lipgloss.NewStyle().
+			Border(lipgloss.RoundedBorder()).
+			BorderForeground(accentColor).
+			Padding(0, 1)
+		b.WriteString(jumpStyle.Render(": " + m.jumpInput.View()))
+		if i := m.findTask(strings.TrimSpace(m.jumpInput.Value())); i >= 0 {
+			b.WriteString("  ")
+			b.WriteString(subtitleStyle.Render("→ " + m.getDisplayTasks()[i].Name))
+		}
+		b.WriteString("\n\n")
+	}
+
 	// Show running indicator if any tasks are running
 	hasRunning := len(m.runningTasks) > 0
 	if hasRunning {
@@ -1585,14 +3215,15 @@ func (m Model) renderList() string {
 	} else {
 		helpText := m.help.ShortHelpView(keys.ShortHelp())
 		// Add search hint
-		helpText += "  " + helpKeyStyle.Render("/") + helpDescStyle.Render(" search")
+		helpText += "  " + helpKeyStyle.Render("/") + helpDescStyle.Render(" search") +
+			"  " + helpKeyStyle.Render(":") + helpDescStyle.Render(" jump")
 		b.WriteString(helpText)
 	}
 
 	return b.String()
 }
 
-func (m Model) renderUsageBar() string {
+func (m Model) renderUsageBar(withSparklines bool) string {
 	if m.usageData == nil {
 		return subtitleStyle.Render("(loading usage...)")
 	}
@@ -1608,6 +3239,12 @@ func (m Model) renderUsageBar() string {
 	fiveHourPct := m.formatUsagePct(fiveHour)
 	sevenDayPct := m.formatUsagePct(sevenDay)
 
+	// Sparklines of recent history (empty if there isn't enough yet)
+	if withSparklines {
+		fiveHourPct += m.renderSparkline(func(s *db.UsageSample) float64 { return s.FiveHour })
+		sevenDayPct += m.renderSparkline(func(s *db.UsageSample) float64 { return s.SevenDay })
+	}
+
 	// Time until reset
 	resetTime := m.usageData.FormatTimeUntilReset()
 
@@ -1646,6 +3283,33 @@ func (m Model) createUsageProgress(pct float64) string {
 	return prog.ViewAs(pct / 100)
 }
 
+// sparkBlocks are the glyphs used for sparklines, lowest to highest
+var sparkBlocks = []rune("▁▂▃▄▅▆▇█")
+
+// renderSparkline renders recent usage samples as a compact sparkline, prefixed
+// with a space. Returns "" when there are too few samples to show a trend.
+func (m Model) renderSparkline(value func(*db.UsageSample) float64) string {
+	if len(m.usageHistory) < 2 {
+		return ""
+	}
+
+	var b strings.Builder
+	b.WriteString(" ")
+	for _, sample := range m.usageHistory {
+		pct := value(sample)
+		if pct > 100 {
+			pct = 100
+		}
+		if pct < 0 {
+			pct = 0
+		}
+		idx := int(pct / 100 * float64(len(sparkBlocks)-1))
+		style := lipgloss.NewStyle().Foreground(lipgloss.Color(m.getGradientColor(pct)))
+		b.WriteString(style.Render(string(sparkBlocks[idx])))
+	}
+	return b.String()
+}
+
 func (m Model) getGradientColor(pct float64) string {
 	t := pct / 100
 	r := int(255 * t)
@@ -1673,32 +3337,159 @@ func (m Model) renderSettings() string {
 	b.WriteString(logoStyle.Render("Settings"))
 	b.WriteString("\n\n")
 
-	// Current usage display
-	if m.usageData != nil {
-		b.WriteString(inputLabelStyle.Render("Current Usage"))
-		b.WriteString("\n")
+	// Current usage display, or why there is none
+	b.WriteString(inputLabelStyle.Render("Current Usage"))
+	b.WriteString("\n")
+	switch {
+	case !m.usageCheck:
+		b.WriteString("  " + subtitleStyle.Render("Usage checking is off") + "\n")
+	case m.usageClient == nil:
+		b.WriteString("  " + statusFail.Render("Unavailable: ") + m.usageClientErr.Error() + "\n")
+	case m.usageData != nil:
 		b.WriteString(fmt.Sprintf("  5-hour:  %s\n", m.formatUsagePct(m.usageData.FiveHour.Utilization)))
 		b.WriteString(fmt.Sprintf("  7-day:   %s\n", m.formatUsagePct(m.usageData.SevenDay.Utilization)))
 		b.WriteString(fmt.Sprintf("  Resets:  %s\n", m.usageData.FormatTimeUntilReset()))
-		b.WriteString("\n")
+	case m.usageErr == nil:
+		b.WriteString("  " + subtitleStyle.Render("Loading...") + "\n")
 	}
+	if m.usageCheck && m.usageClient != nil && m.usageErr != nil {
+		b.WriteString("  " + statusFail.Render("Last fetch failed: ") + m.usageErr.Error() + "\n")
+	}
+	b.WriteString("\n")
 
 	// Threshold input
 	b.WriteString(inputLabelStyle.Render("Usage Threshold (%)"))
 	b.WriteString("  ")
 	b.WriteString(subtitleStyle.Render("Tasks skip when usage exceeds this"))
 	b.WriteString("\n")
-	b.WriteString(focusedInputStyle.Render(m.thresholdInput.View()))
+	b.WriteString(settingsInputStyle(m.settingsFocus == settingThreshold).Render(m.thresholdInput.View()))
+	b.WriteString("\n\n")
+
+	// Usage check toggle
+	b.WriteString(inputLabelStyle.Render("Usage Check"))
+	b.WriteString("  ")
+	b.WriteString(subtitleStyle.Render("Off disables usage fetching and the threshold"))
+	b.WriteString("\n")
+	b.WriteString(settingsInputStyle(m.settingsFocus == settingUsageCheck).Render(renderToggle(m.usageCheckToggle)))
+	b.WriteString("\n\n")
+
+	// Confirm before run toggle
+	b.WriteString(inputLabelStyle.Render("Confirm Before Run"))
+	b.WriteString("  ")
+	b.WriteString(subtitleStyle.Render("Ask before 'r' starts a task"))
+	b.WriteString("\n")
+	b.WriteString(settingsInputStyle(m.settingsFocus == settingConfirmRun).Render(renderToggle(m.confirmRunToggle)))
+	b.WriteString("\n\n")
+
+	// Markdown rendering toggle
+	b.WriteString(inputLabelStyle.Render("Render Markdown"))
+	b.WriteString("  ")
+	b.WriteString(subtitleStyle.Render("Off shows run output as raw text ('m' switches in the output view)"))
+	b.WriteString("\n")
+	b.WriteString(settingsInputStyle(m.settingsFocus == settingRenderMarkdown).Render(renderToggle(m.renderMdToggle)))
+	b.WriteString("\n\n")
+
+	// Quiet hours window
+	b.WriteString(inputLabelStyle.Render("Quiet Hours"))
+	b.WriteString("  ")
+	b.WriteString(subtitleStyle.Render("Scheduled runs are skipped between these times (HH:MM, empty = off)"))
+	b.WriteString("\n")
+	b.WriteString(lipgloss.JoinHorizontal(lipgloss.Center,
+		settingsInputStyle(m.settingsFocus == settingQuietStart).Render(m.quietStartInput.View()),
+		subtitleStyle.Render("  to  "),
+		settingsInputStyle(m.settingsFocus == settingQuietEnd).Render(m.quietEndInput.View()),
+	))
 	b.WriteString("\n\n")
 
 	// Help text
-	helpText := helpKeyStyle.Render("enter") + helpDescStyle.Render(" save • ") +
+	helpText := helpKeyStyle.Render("tab") + helpDescStyle.Render(" next • ") +
+		helpKeyStyle.Render("space") + helpDescStyle.Render(" toggle • ") +
+		helpKeyStyle.Render("enter") + helpDescStyle.Render(" save • ") +
 		helpKeyStyle.Render("esc") + helpDescStyle.Render(" cancel")
 	b.WriteString(helpText)
 
 	return b.String()
 }
 
+func (m Model) renderMetrics() string {
+	var b strings.Builder
+
+	b.WriteString(spriteIcon)
+	b.WriteString(" ")
+	b.WriteString(logoStyle.Render("Metrics"))
+	b.WriteString("\n\n")
+
+	enabled := 0
+	for _, task := range m.tasks {
+		if task.Enabled {
+			enabled++
+		}
+	}
+	b.WriteString(inputLabelStyle.Render("Tasks"))
+	b.WriteString("\n")
+	b.WriteString(fmt.Sprintf("  Total:     %d\n", len(m.tasks)))
+	b.WriteString(fmt.Sprintf("  Enabled:   %d\n", enabled))
+	b.WriteString(fmt.Sprintf("  Running:   %d\n", len(m.runningTasks)))
+	b.WriteString("\n")
+
+	b.WriteString(inputLabelStyle.Render("Runs (last 24h)"))
+	b.WriteString("\n")
+	switch {
+	case m.summaryErr != nil:
+		b.WriteString("  " + statusFail.Render("Error: "+m.summaryErr.Error()) + "\n")
+	case m.runSummary == nil:
+		b.WriteString("  " + subtitleStyle.Render("Loading...") + "\n")
+	default:
+		s := m.runSummary
+		b.WriteString(fmt.Sprintf("  Total:     %d\n", s.Total))
+		b.WriteString(fmt.Sprintf("  Completed: %s\n", statusOK.Render(fmt.Sprintf("%d", s.Completed))))
+		b.WriteString(fmt.Sprintf("  Failed:    %s\n", statusFail.Render(fmt.Sprintf("%d", s.Failed))))
+		b.WriteString(fmt.Sprintf("  Skipped:   %d\n", s.Skipped))
+		if s.Completed+s.Failed > 0 {
+			b.WriteString(fmt.Sprintf("  Success:   %.0f%%\n", s.SuccessRate()))
+		}
+	}
+	b.WriteString("\n")
+
+	b.WriteString(inputLabelStyle.Render("Usage"))
+	b.WriteString("\n")
+	switch {
+	case m.usageData != nil:
+		b.WriteString(fmt.Sprintf("  5-hour:    %s\n", m.formatUsagePct(m.usageData.FiveHour.Utilization)))
+		b.WriteString(fmt.Sprintf("  7-day:     %s\n", m.formatUsagePct(m.usageData.SevenDay.Utilization)))
+		b.WriteString(fmt.Sprintf("  Threshold: %.0f%%\n", m.usageThreshold))
+		b.WriteString(fmt.Sprintf("  Resets:    %s\n", m.usageData.FormatTimeUntilReset()))
+	case !m.usageCheck:
+		b.WriteString("  " + subtitleStyle.Render("Usage checking is off") + "\n")
+	default:
+		b.WriteString("  " + subtitleStyle.Render("Unavailable") + "\n")
+	}
+	b.WriteString("\n")
+
+	helpText := helpKeyStyle.Render("r") + helpDescStyle.Render(" refresh • ") +
+		helpKeyStyle.Render("1/2/3") + helpDescStyle.Render(" list/settings/metrics • ") +
+		helpKeyStyle.Render("esc") + helpDescStyle.Render(" back")
+	b.WriteString(helpText)
+
+	return b.String()
+}
+
+// settingsInputStyle returns the input style for a settings field
+func settingsInputStyle(focused bool) lipgloss.Style {
+	if focused {
+		return focusedInputStyle
+	}
+	return blurredInputStyle
+}
+
+// renderToggle renders an On/Off toggle with the active option bracketed
+func renderToggle(on bool) string {
+	if on {
+		return "[On]  Off"
+	}
+	return "On  [Off]"
+}
+
 func (m Model) renderForm(title string) string {
 	var b strings.Builder
 
@@ -1713,8 +3504,19 @@ func (m Model) renderForm(title string) string {
 		return b.String()
 	}
 
+	// Track where the fields start and which line the focused field is on,
+	// so the form can scroll when it's taller than the terminal
+	fieldsStart := strings.Count(b.String(), "\n")
+	focusLine := fieldsStart
+	markField := func(field int) {
+		if field == m.formFocus {
+			focusLine = strings.Count(b.String(), "\n")
+		}
+	}
+
 	// Helper to render a field label with validation
 	renderLabel := func(field int, label, hint string) {
+		markField(field)
 		b.WriteString(inputLabelStyle.Render(label))
 		if hint != "" {
 			b.WriteString("  ")
@@ -1750,7 +3552,126 @@ func (m Model) renderForm(title string) string {
 	}
 	b.WriteString("\n\n")
 
+	// Prompt file (alternative to the inline prompt)
+	renderLabel(fieldPromptFile, "Prompt File (optional)", "(read at run time, relative to working dir)")
+	renderFocused(m.formInputs[fieldPromptFile].View(), m.formFocus == fieldPromptFile)
+
+	// Stdin file
+	renderLabel(fieldStdinFile, "Stdin File (optional)", "(piped to claude at run time, relative to working dir)")
+	renderFocused(m.formInputs[fieldStdinFile].View(), m.formFocus == fieldStdinFile)
+
+	// System prompt field (textarea)
+	renderLabel(fieldSystemPrompt, "System Prompt (optional)", "(appended via --append-system-prompt)")
+	renderFocused(m.systemPrompt.View(), m.formFocus == fieldSystemPrompt)
+
+	// Output format selector
+	markField(fieldOutputFormat)
+	b.WriteString(inputLabelStyle.Render("Output Format"))
+	b.WriteString("  ")
+	b.WriteString(subtitleStyle.Render("(←/→ to change; json formats store raw JSON)"))
+	b.WriteString("\n")
+	{
+		var labels []string
+		for _, format := range outputFormats {
+			if format == m.outputFormat {
+				format = "[" + format + "]"
+			}
+			labels = append(labels, format)
+		}
+		renderFocused(strings.Join(labels, "  "), m.formFocus == fieldOutputFormat)
+	}
+
+	// ANSI escape code handling
+	markField(fieldAnsi)
+	b.WriteString(inputLabelStyle.Render("ANSI Colors"))
+	b.WriteString("  ")
+	b.WriteString(subtitleStyle.Render("(←/→ to change; keep to view colored CLI output as-is)"))
+	b.WriteString("\n")
+	{
+		stripLabel := "Strip"
+		keepLabel := "Keep"
+		if m.keepAnsi {
+			keepLabel = "[" + keepLabel + "]"
+		} else {
+			stripLabel = "[" + stripLabel + "]"
+		}
+		renderFocused(stripLabel+"  "+keepLabel, m.formFocus == fieldAnsi)
+	}
+
+	// Previous run output in prompt
+	markField(fieldPrevOutput)
+	b.WriteString(inputLabelStyle.Render("Previous Output"))
+	b.WriteString("  ")
+	b.WriteString(subtitleStyle.Render("(←/→ to change; appends the last successful run's output to the prompt)"))
+	b.WriteString("\n")
+	{
+		offLabel := "Off"
+		includeLabel := "Include"
+		if m.includePrev {
+			includeLabel = "[" + includeLabel + "]"
+		} else {
+			offLabel = "[" + offLabel + "]"
+		}
+		renderFocused(offLabel+"  "+includeLabel, m.formFocus == fieldPrevOutput)
+	}
+
+	// Template variables in the prompt
+	markField(fieldTemplate)
+	b.WriteString(inputLabelStyle.Render("Prompt Template"))
+	b.WriteString("  ")
+	b.WriteString(subtitleStyle.Render("(←/→ to change; expand expands {{.Date}}, {{.TaskName}}, ... in the prompt)"))
+	b.WriteString("\n")
+	{
+		offLabel := "Off"
+		expandLabel := "Expand"
+		if m.templated {
+			expandLabel = "[" + expandLabel + "]"
+		} else {
+			offLabel = "[" + offLabel + "]"
+		}
+		renderFocused(offLabel+"  "+expandLabel, m.formFocus == fieldTemplate)
+	}
+
+	// Session continuity across runs
+	markField(fieldSession)
+	b.WriteString(inputLabelStyle.Render("Session"))
+	b.WriteString("  ")
+	b.WriteString(subtitleStyle.Render("(←/→ to change; resume continues the previous run's conversation)"))
+	b.WriteString("\n")
+	{
+		newLabel := "New each run"
+		resumeLabel := "Resume"
+		if m.resume {
+			resumeLabel = "[" + resumeLabel + "]"
+		} else {
+			newLabel = "[" + newLabel + "]"
+		}
+		renderFocused(newLabel+"  "+resumeLabel, m.formFocus == fieldSession)
+	}
+
+	// Permission prompts
+	markField(fieldPermissions)
+	b.WriteString(inputLabelStyle.Render("Permissions"))
+	b.WriteString("  ")
+	b.WriteString(subtitleStyle.Render("(←/→ to change; enforce to deny tools that aren't allowed below)"))
+	b.WriteString("\n")
+	{
+		skipLabel := "Skip"
+		enforceLabel := "Enforce"
+		if m.askPerms {
+			enforceLabel = "[" + enforceLabel + "]"
+		} else {
+			skipLabel = "[" + skipLabel + "]"
+		}
+		renderFocused(skipLabel+"  "+enforceLabel, m.formFocus == fieldPermissions)
+	}
+	if m.askPerms {
+		renderLabel(fieldAllowedTools, "Allowed Tools (optional)", "(passed to claude as --allowedTools)")
+		renderFocused(m.formInputs[fieldAllowedTools].View(), m.formFocus == fieldAllowedTools)
+	}
+
 	// Task Type toggle
+	markField(fieldTaskType)
 	b.WriteString(inputLabelStyle.Render("Task Type"))
 	b.WriteString("  ")
 	b.WriteString(subtitleStyle.Render("(←/→ to change)"))
@@ -1770,6 +3691,7 @@ func (m Model) renderForm(title string) string {
 	// Conditional fields based on task type
 	if m.isOneOff {
 		// Schedule Mode toggle for one-off tasks
+		markField(fieldScheduleMode)
 		b.WriteString(inputLabelStyle.Render("When to Run"))
 		b.WriteString("  ")
 		b.WriteString(subtitleStyle.Render("(←/→ to change)"))
@@ -1793,21 +3715,103 @@ func (m Model) renderForm(title string) string {
 		}
 	} else {
 		// Cron Expression for recurring tasks
-		renderLabel(fieldCron, "Cron Expression", "Press ? for presets")
+		// Show the plain-English schedule once the expression parses
+		cronHint := "Press ? for presets"
+		if expr := strings.TrimSpace(m.formInputs[fieldCron].Value()); expr != "" {
+			if _, err := cronexpr.Parser.Parse(expr); err == nil {
+				cronHint = cronexpr.Describe(expr) + " · ? for presets"
+			}
+		}
+		renderLabel(fieldCron, "Cron Expression", cronHint)
 		renderFocused(m.formInputs[fieldCron].View(), m.formFocus == fieldCron)
+		if m.formFocus == fieldCron {
+			if estimate := m.renderCostEstimate(); estimate != "" {
+				b.WriteString(subtitleStyle.Render(estimate))
+				b.WriteString("\n\n")
+			}
+		}
+
+		// Quiet hours behavior toggle
+		markField(fieldQuietHours)
+		b.WriteString(inputLabelStyle.Render("During Quiet Hours"))
+		b.WriteString("  ")
+		b.WriteString(subtitleStyle.Render("(←/→ to change)"))
+		b.WriteString("\n")
+		{
+			skipLabel := "Skip"
+			runLabel := "Run anyway"
+			if m.runInQuiet {
+				runLabel = "[" + runLabel + "]"
+			} else {
+				skipLabel = "[" + skipLabel + "]"
+			}
+			renderFocused(skipLabel+"  "+runLabel, m.formFocus == fieldQuietHours)
+		}
+
+		// Jitter
+		renderLabel(fieldJitter, "Jitter (seconds)", "(optional, random delay before each run)")
+		renderFocused(m.formInputs[fieldJitter].View(), m.formFocus == fieldJitter)
+
+		// Active window
+		renderLabel(fieldActiveFrom, "Active From", "(optional, cron runs before this are skipped)")
+		renderFocused(m.formInputs[fieldActiveFrom].View(), m.formFocus == fieldActiveFrom)
+		renderLabel(fieldActiveUntil, "Active Until", "(optional, task is disabled after this)")
+		renderFocused(m.formInputs[fieldActiveUntil].View(), m.formFocus == fieldActiveUntil)
 	}
 
 	// Working Directory
-	renderLabel(fieldWorkingDir, "Working Directory", "")
+	renderLabel(fieldWorkingDir, "Working Directory", "(empty uses the default directory)")
 	renderFocused(m.formInputs[fieldWorkingDir].View(), m.formFocus == fieldWorkingDir)
 
+	// Container
+	renderLabel(fieldContainer, "Container (optional)", "(Docker image; claude runs inside it)")
+	renderFocused(m.formInputs[fieldContainer].View(), m.formFocus == fieldContainer)
+
+	// Tags
+	renderLabel(fieldTags, "Tags (optional)", "(comma-separated; search with tag:name)")
+	renderFocused(m.formInputs[fieldTags].View(), m.formFocus == fieldTags)
+
+	// Usage threshold override
+	renderLabel(fieldUsageThreshold, "Usage Threshold Override (%)", "(optional, overrides the global threshold)")
+	renderFocused(m.formInputs[fieldUsageThreshold].View(), m.formFocus == fieldUsageThreshold)
+
+	// Long-run alert
+	renderLabel(fieldAlertAfter, "Alert After (seconds)", "(optional, notify webhooks once if a run is still going)")
+	renderFocused(m.formInputs[fieldAlertAfter].View(), m.formFocus == fieldAlertAfter)
+
+	// Failure limit
+	renderLabel(fieldDisableAfter, "Disable After Failures", "(optional, turn the task off after this many failures in a row)")
+	renderFocused(m.formInputs[fieldDisableAfter].View(), m.formFocus == fieldDisableAfter)
+
+	// Webhook detail selector
+	markField(fieldWebhookDetail)
+	b.WriteString(inputLabelStyle.Render("Webhook Detail"))
+	b.WriteString("  ")
+	b.WriteString(subtitleStyle.Render("(←/→ to change; default uses the global setting)"))
+	b.WriteString("\n")
+	{
+		var labels []string
+		for _, detail := range webhookDetails {
+			label := detail
+			if label == "" {
+				label = "default"
+			}
+			if detail == m.webhookDetail {
+				label = "[" + label + "]"
+			}
+			labels = append(labels, label)
+		}
+		renderFocused(strings.Join(labels, "  "), m.formFocus == fieldWebhookDetail)
+	}
+
 	// Discord Webhook
-	renderLabel(fieldDiscordWebhook, "Discord Webhook (optional)", "")
+	renderLabel(fieldDiscordWebhook, "Discord Webhook (optional)", "(empty uses the default, - for none)")
 	renderFocused(m.formInputs[fieldDiscordWebhook].View(), m.formFocus == fieldDiscordWebhook)
 
 	// Slack Webhook
-	renderLabel(fieldSlackWebhook, "Slack Webhook (optional)", "")
+	renderLabel(fieldSlackWebhook, "Slack Webhook (optional)", "(empty uses the default, - for none)")
 	renderFocused(m.formInputs[fieldSlackWebhook].View(), m.formFocus == fieldSlackWebhook)
+	fieldsEnd := strings.Count(b.String(), "\n")
 
 	// Status
 	if m.statusMsg != "" {
@@ -1828,10 +3832,67 @@ func (m Model) renderForm(title string) string {
 	if !m.isOneOff {
 		b.WriteString("\n\n")
 		b.WriteString(subtitleStyle.Render("Cron format: "))
-		b.WriteString(dimRowStyle.Render("sec min hour day month weekday"))
+		b.WriteString(dimRowStyle.Render("sec min hour day month weekday (sec optional)"))
 	}
 
-	return b.String()
+	return m.scrollForm(b.String(), fieldsStart, fieldsEnd, focusLine)
+}
+
+// scrollForm clips the field section of a rendered form to the terminal height,
+// keeping the focused field in view. Title and footer lines are always shown.
+func (m Model) scrollForm(form string, fieldsStart, fieldsEnd, focusLine int) string {
+	if m.height == 0 {
+		return form
+	}
+
+	lines := strings.Split(form, "\n")
+	if fieldsEnd > len(lines) {
+		fieldsEnd = len(lines)
+	}
+	header := lines[:fieldsStart]
+	fields := lines[fieldsStart:fieldsEnd]
+	footer := lines[fieldsEnd:]
+
+	available := m.height - 2 - len(header) - len(footer) // 2 for app padding
+	if available < 5 {
+		available = 5
+	}
+	if len(fields) <= available {
+		return form
+	}
+
+	// Keep the focused field roughly a third of the way down
+	offset := focusLine - fieldsStart - available/3
+	if offset < 0 {
+		offset = 0
+	}
+	if offset > len(fields)-available {
+		offset = len(fields) - available
+	}
+
+	visible := make([]string, 0, len(header)+available+len(footer))
+	visible = append(visible, header...)
+	visible = append(visible, fields[offset:offset+available]...)
+	visible = append(visible, footer...)
+	return strings.Join(visible, "\n")
+}
+
+// costEstimateRuns is how many recent runs the form's cost estimate averages
+const costEstimateRuns = 20
+
+// renderCostEstimate projects daily spend from the edited task's average run
+// cost and the cron expression being typed; empty when either is unavailable
+func (m Model) renderCostEstimate() string {
+	if m.costEstimate == nil {
+		return ""
+	}
+	expr := strings.TrimSpace(m.formInputs[fieldCron].Value())
+	runs, err := cronexpr.RunsPerDay(expr, time.Now())
+	if err != nil {
+		return ""
+	}
+	return fmt.Sprintf("≈ $%.2f/day at %d runs/day (avg $%.4f over last %d runs)",
+		m.costEstimate.AvgCostUSD*float64(runs), runs, m.costEstimate.AvgCostUSD, m.costEstimate.SampleSize)
 }
 
 func (m Model) renderCronHelper() string {
@@ -1888,17 +3949,71 @@ func (m Model) renderOutput() string {
 	} else {
 		b.WriteString(statusFail.Render("○ disabled"))
 	}
+	for _, tag := range m.selectedTask.Tags {
+		b.WriteString("  ")
+		b.WriteString(subtitleStyle.Render("#" + tag))
+	}
+	if st := m.taskStats; st != nil && st.Completed+st.Failed > 0 {
+		b.WriteString("  ")
+		b.WriteString(subtitleStyle.Render(fmt.Sprintf("avg %s · %.0f%% success (last %d runs)",
+			st.AvgDuration.Round(time.Second), st.SuccessRate, st.SampleSize)))
+	}
 	b.WriteString("\n")
-	b.WriteString(subtitleStyle.Render(m.selectedTask.Prompt))
+	if m.selectedTask.PromptFile != "" {
+		b.WriteString(subtitleStyle.Render("Prompt file: " + m.selectedTask.PromptFile))
+	} else {
+		b.WriteString(subtitleStyle.Render(m.selectedTask.Prompt))
+	}
 	b.WriteString("\n\n")
 
 	b.WriteString(m.viewport.View())
 	b.WriteString("\n\n")
 
+	if m.noteEditMode {
+		b.WriteString(inputLabelStyle.Render("Note: "))
+		b.WriteString(m.noteInput.View())
+		b.WriteString("\n")
+		b.WriteString(helpKeyStyle.Render("enter") + helpDescStyle.Render(" save (empty clears) • ") +
+			helpKeyStyle.Render("esc") + helpDescStyle.Render(" cancel"))
+		return b.String()
+	}
+
+	if m.outputSearchMode || m.outputQuery != "" {
+		if m.outputSearchMode {
+			b.WriteString(inputLabelStyle.Render("/ "))
+			b.WriteString(m.outputSearchInput.View())
+		} else {
+			b.WriteString(inputLabelStyle.Render("/ " + m.outputQuery))
+		}
+		b.WriteString("  ")
+		if len(m.outputMatchLines) == 0 {
+			b.WriteString(subtitleStyle.Render("no matches"))
+		} else {
+			b.WriteString(subtitleStyle.Render(fmt.Sprintf("match %d of %d", m.outputMatch+1, len(m.outputMatchLines))))
+		}
+		b.WriteString("\n")
+		if m.outputSearchMode {
+			b.WriteString(helpKeyStyle.Render("enter") + helpDescStyle.Render(" done • ") +
+				helpKeyStyle.Render("esc") + helpDescStyle.Render(" clear"))
+		} else {
+			b.WriteString(helpKeyStyle.Render("n/p") + helpDescStyle.Render(" next/previous match • ") +
+				helpKeyStyle.Render("/") + helpDescStyle.Render(" edit search • ") +
+				helpKeyStyle.Render("esc") + helpDescStyle.Render(" clear search"))
+		}
+		return b.String()
+	}
+
 	// Help
 	helpText := helpKeyStyle.Render("↑/↓") + helpDescStyle.Render(" scroll • ") +
+		helpKeyStyle.Render("n/p") + helpDescStyle.Render(" older/newer run • ") +
+		helpKeyStyle.Render("/") + helpDescStyle.Render(" search • ") +
 		helpKeyStyle.Render("t") + helpDescStyle.Render(" toggle • ") +
+		helpKeyStyle.Render("d") + helpDescStyle.Render(" diff • ") +
+		helpKeyStyle.Render("m") + helpDescStyle.Render(" raw/markdown • ") +
+		helpKeyStyle.Render("N") + helpDescStyle.Render(" note • ") +
+		helpKeyStyle.Render("R") + helpDescStyle.Render(" rerun as one-off • ") +
 		helpKeyStyle.Render("r") + helpDescStyle.Render(" refresh • ") +
+		helpKeyStyle.Render("1/2/3") + helpDescStyle.Render(" list/settings/metrics • ") +
 		helpKeyStyle.Render("esc") + helpDescStyle.Render(" back")
 	b.WriteString(helpText)
 
@@ -1910,76 +4025,149 @@ func (m Model) renderOutputContent() string {
 		return emptyBoxStyle.Render("No runs yet for this task")
 	}
 
-	// Sort runs: running first, then by start time descending
-	runs := make([]*db.TaskRun, len(m.taskRuns))
-	copy(runs, m.taskRuns)
-	sort.Slice(runs, func(i, j int) bool {
-		// Running tasks first
-		if runs[i].Status == db.RunStatusRunning && runs[j].Status != db.RunStatusRunning {
-			return true
-		}
-		if runs[j].Status == db.RunStatusRunning && runs[i].Status != db.RunStatusRunning {
-			return false
-		}
-		// Then by start time descending
-		return runs[i].StartedAt.After(runs[j].StartedAt)
-	})
+	if m.showDiff {
+		return m.renderRunDiff()
+	}
+
+	// Show one run at a time so only its markdown is rendered
+	run := m.taskRuns[m.runIndex]
 
 	var b strings.Builder
+	position := fmt.Sprintf("%d of %d", m.runIndex+1, max(m.runTotal, len(m.taskRuns)))
+	if run.Seq > 0 {
+		b.WriteString(subtitleStyle.Render(fmt.Sprintf("Run #%d (%s)", run.Seq, position)))
+	} else {
+		b.WriteString(subtitleStyle.Render("Run " + position))
+	}
+	b.WriteString("\n")
 
-	for i, run := range runs {
-		// Status icon and time
-		var statusIcon string
-		switch run.Status {
-		case db.RunStatusCompleted:
-			statusIcon = statusOK.Render("✓ COMPLETED")
-		case db.RunStatusFailed:
-			statusIcon = statusFail.Render("✗ FAILED")
-		case db.RunStatusRunning:
-			statusIcon = statusRunning.Render("● RUNNING")
-		default:
-			statusIcon = statusPending.Render("○ PENDING")
-		}
+	// Status icon and time
+	var statusIcon string
+	switch run.Status {
+	case db.RunStatusCompleted:
+		statusIcon = statusOK.Render("✓ COMPLETED")
+	case db.RunStatusFailed:
+		statusIcon = statusFail.Render("✗ FAILED")
+	case db.RunStatusSkipped:
+		statusIcon = statusPending.Render("⊘ SKIPPED")
+	case db.RunStatusRunning:
+		statusIcon = statusRunning.Render("● RUNNING")
+	default:
+		statusIcon = statusPending.Render("○ PENDING")
+	}
 
-		duration := "..."
-		if run.EndedAt != nil {
-			duration = run.EndedAt.Sub(run.StartedAt).Round(time.Millisecond).String()
-		}
+	duration := "..."
+	if run.EndedAt != nil {
+		duration = run.EndedAt.Sub(run.StartedAt).Round(time.Millisecond).String()
+	}
 
-		header := fmt.Sprintf("%s  %s  (%s)",
-			statusIcon,
-			run.StartedAt.Format("2006-01-02 15:04:05"),
-			duration)
-		b.WriteString(header)
-		b.WriteString("\n")
-		b.WriteString(dividerStyle.Render(strings.Repeat("─", 60)))
+	header := fmt.Sprintf("%s  %s  (%s)",
+		statusIcon,
+		run.StartedAt.Format("2006-01-02 15:04:05"),
+		duration)
+	if run.Trigger != "" {
+		header += "  " + subtitleStyle.Render(string(run.Trigger))
+	}
+	b.WriteString(header)
+	b.WriteString("\n")
+	if run.Notes != "" {
+		b.WriteString(inputLabelStyle.Render("Note: "))
+		b.WriteString(run.Notes)
 		b.WriteString("\n")
+	}
+	b.WriteString(dividerStyle.Render(strings.Repeat("─", 60)))
+	b.WriteString("\n")
 
-		if run.Output != "" {
-			// Render markdown
-			if m.mdRenderer != nil {
-				rendered, err := m.mdRenderer.Render(run.Output)
-				if err == nil {
-					b.WriteString(rendered)
-				} else {
-					b.WriteString(run.Output)
-					b.WriteString("\n")
-				}
+	if run.Output != "" && (m.rawOutput || m.outputQuery != "" || !m.selectedTask.StripAnsi && strings.Contains(run.Output, "\x1b[")) {
+		// Raw mode (also used while searching), or preserved colors glamour
+		// would mangle; the viewport renders them directly
+		b.WriteString(ansi.Wrap(run.Output, m.viewport.Width, ""))
+		b.WriteString("\n")
+	} else if run.Output != "" {
+		// Render markdown; JSON output is shown as a fenced code block
+		output := run.Output
+		if m.selectedTask.OutputFormat == db.OutputFormatJSON || m.selectedTask.OutputFormat == db.OutputFormatStreamJSON {
+			output = "```json\n" + strings.TrimRight(output, "\n") + "\n```"
+		}
+		if m.mdRenderer != nil {
+			rendered, err := m.mdRenderer.Render(output)
+			if err == nil {
+				b.WriteString(rendered)
 			} else {
 				b.WriteString(run.Output)
 				b.WriteString("\n")
 			}
+		} else {
+			b.WriteString(run.Output)
+			b.WriteString("\n")
 		}
+	}
 
-		if run.Error != "" {
+	if run.Error != "" {
+		if run.Status == db.RunStatusSkipped {
+			b.WriteString(statusPending.Render("Skipped: "))
+		} else {
 			b.WriteString(statusFail.Render("Error: "))
-			b.WriteString(run.Error)
-			b.WriteString("\n")
 		}
+		b.WriteString(run.Error)
+		b.WriteString("\n")
+	}
 
-		if i < len(runs)-1 {
-			b.WriteString("\n")
+	if run.Warning != "" {
+		b.WriteString(statusPending.Render("Warning: "))
+		b.WriteString(run.Warning)
+		b.WriteString("\n")
+	}
+
+	if run.WebhookError != "" {
+		b.WriteString(statusPending.Render("Notification failed: "))
+		b.WriteString(run.WebhookError)
+		b.WriteString("\n")
+	}
+
+	return b.String()
+}
+
+// renderRunDiff renders the latest run's output as a diff against the previous run
+func (m Model) renderRunDiff() string {
+	// taskRuns is ordered by start time, newest first; diff the shown run against the one before it
+	if m.runIndex+1 >= len(m.taskRuns) {
+		return emptyBoxStyle.Render("Need an earlier run to diff against\n\nPress 'd' to return to output")
+	}
+	latest, previous := m.taskRuns[m.runIndex], m.taskRuns[m.runIndex+1]
+
+	unified, err := diff.Runs(previous, latest)
+	if err != nil {
+		return statusFail.Render("Error: ") + err.Error()
+	}
+
+	var b strings.Builder
+	b.WriteString(inputLabelStyle.Render(fmt.Sprintf("Diff: %s → %s",
+		previous.StartedAt.Format("2006-01-02 15:04:05"),
+		latest.StartedAt.Format("2006-01-02 15:04:05"))))
+	b.WriteString("\n")
+	b.WriteString(dividerStyle.Render(strings.Repeat("─", 60)))
+	b.WriteString("\n")
+
+	if unified == "" {
+		b.WriteString(subtitleStyle.Render("Output unchanged between runs"))
+		return b.String()
+	}
+
+	for _, line := range strings.Split(strings.TrimRight(unified, "\n"), "\n") {
+		switch {
+		case strings.HasPrefix(line, "+++"), strings.HasPrefix(line, "---"):
+			b.WriteString(subtitleStyle.Render(line))
+		case strings.HasPrefix(line, "@@"):
+			b.WriteString(helpKeyStyle.Render(line))
+		case strings.HasPrefix(line, "+"):
+			b.WriteString(statusOK.Render(line))
+		case strings.HasPrefix(line, "-"):
+			b.WriteString(statusFail.Render(line))
+		default:
+			b.WriteString(line)
 		}
+		b.WriteString("\n")
 	}
 
 	return b.String()
@@ -1987,10 +4175,24 @@ func (m Model) renderOutputContent() string {
 
 // Run starts the TUI application
 // If daemonMode is true, scheduler can be nil (external daemon handles scheduling)
-func Run(database *db.DB, sched *scheduler.Scheduler, daemonMode bool) error {
+// and daemonAlive is polled so a dead daemon is noticed.
+func Run(database *db.DB, sched *scheduler.Scheduler, daemonMode bool, daemonAlive func() bool) error {
 	m := NewModel(database, sched, daemonMode)
+	m.daemonAlive = daemonAlive
 	p := tea.NewProgram(m, tea.WithAltScreen())
-	_, err := p.Run()
+	final, err := p.Run()
+
+	// Stop a scheduler started after taking over from a dead daemon
+	var fm *Model
+	switch v := final.(type) {
+	case Model:
+		fm = &v
+	case *Model:
+		fm = v
+	}
+	if fm != nil && fm.ownsScheduler {
+		fm.scheduler.Stop()
+	}
 	return err
 }
 
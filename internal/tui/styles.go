@@ -101,4 +101,13 @@ var (
 	// Divider
 	dividerStyle = lipgloss.NewStyle().
 			Foreground(dimTextColor)
+
+	// Output search matches; the current one stands out
+	searchMatchStyle = lipgloss.NewStyle().
+				Reverse(true)
+
+	searchCurrentStyle = lipgloss.NewStyle().
+				Background(warningColor).
+				Foreground(lipgloss.Color("#141413")).
+				Bold(true)
 )
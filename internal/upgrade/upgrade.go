@@ -4,12 +4,14 @@ import (
 	"archive/tar"
 	"compress/gzip"
 	"encoding/json"
+	"errors"
 	"fmt"
 	"io"
 	"net/http"
 	"os"
 	"path/filepath"
 	"runtime"
+	"strconv"
 	"strings"
 	"time"
 
@@ -21,6 +23,9 @@ const (
 	repoName  = "claude-tasks"
 )
 
+// DefaultRetries is how many times a failed download is resumed before giving up
+const DefaultRetries = 3
+
 // GitHubRelease represents a GitHub release
 type GitHubRelease struct {
 	TagName string  `json:"tag_name"`
@@ -54,8 +59,9 @@ func CheckForUpdate() (*GitHubRelease, bool, error) {
 	return release, false, nil
 }
 
-// Upgrade downloads and installs the latest version
-func Upgrade() error {
+// Upgrade downloads and installs the latest version, resuming the download up
+// to retries times if the connection fails
+func Upgrade(retries int) error {
 	release, hasUpdate, err := CheckForUpdate()
 	if err != nil {
 		return fmt.Errorf("failed to check for updates: %w", err)
@@ -94,7 +100,7 @@ func Upgrade() error {
 
 	// Download the new version
 	fmt.Printf("Downloading %s...\n", assetName)
-	tmpFile, err := downloadAsset(downloadURL)
+	tmpFile, err := downloadAsset(downloadURL, retries)
 	if err != nil {
 		return fmt.Errorf("failed to download: %w", err)
 	}
@@ -174,37 +180,172 @@ func getAssetName() string {
 	return fmt.Sprintf("claude-tasks_%s_%s.tar.gz", os, arch)
 }
 
-func downloadAsset(url string) (string, error) {
-	client := &http.Client{Timeout: 5 * time.Minute}
-	req, err := http.NewRequest("GET", url, nil)
+// downloadAsset downloads url to a temp file, printing progress. After a failed
+// attempt it waits with exponential backoff and resumes with a Range request.
+func downloadAsset(url string, retries int) (string, error) {
+	tmpFile, err := os.CreateTemp("", "claude-tasks-*")
 	if err != nil {
 		return "", err
 	}
+	defer tmpFile.Close()
+
+	dl := &download{url: url, file: tmpFile, total: -1}
+	for attempt := 0; ; attempt++ {
+		err = dl.fetch()
+		if err == nil {
+			return tmpFile.Name(), nil
+		}
+		var status statusError
+		if attempt >= retries || (errors.As(err, &status) && !status.retryable()) {
+			os.Remove(tmpFile.Name())
+			return "", err
+		}
+		backoff := time.Second << attempt
+		fmt.Printf("Download interrupted (%v); retrying in %s (%d/%d)...\n", err, backoff, attempt+1, retries)
+		time.Sleep(backoff)
+	}
+}
+
+// download tracks a possibly partial asset download across attempts
+type download struct {
+	url        string
+	file       *os.File
+	written    int64 // Bytes in file so far
+	total      int64 // Full size from Content-Length/Content-Range; -1 if unknown
+	lastReport time.Time
+}
+
+// statusError is an unexpected HTTP status from the download server
+type statusError int
+
+func (e statusError) Error() string {
+	return fmt.Sprintf("download returned status %d", int(e))
+}
+
+// retryable reports whether the status may succeed on a later attempt
+func (e statusError) retryable() bool {
+	return e >= 500 || e == http.StatusTooManyRequests || e == http.StatusRequestTimeout
+}
+
+// fetch makes one attempt, continuing from d.written when the server allows it
+func (d *download) fetch() error {
+	client := &http.Client{Timeout: 5 * time.Minute}
+	req, err := http.NewRequest("GET", d.url, nil)
+	if err != nil {
+		return err
+	}
 	req.Header.Set("User-Agent", version.UserAgent())
+	if d.written > 0 {
+		req.Header.Set("Range", fmt.Sprintf("bytes=%d-", d.written))
+	}
 
 	resp, err := client.Do(req)
 	if err != nil {
-		return "", err
+		return err
 	}
 	defer resp.Body.Close()
 
-	if resp.StatusCode != http.StatusOK {
-		return "", fmt.Errorf("download returned status %d", resp.StatusCode)
+	switch resp.StatusCode {
+	case http.StatusOK:
+		// Full body: either the first attempt or the server ignored Range
+		if err := d.restart(); err != nil {
+			return err
+		}
+		d.total = resp.ContentLength
+	case http.StatusPartialContent:
+		start, total, err := parseContentRange(resp.Header.Get("Content-Range"))
+		if err != nil || start != d.written {
+			// Appending a range that doesn't begin where the file ends would corrupt it
+			if err := d.restart(); err != nil {
+				return err
+			}
+			return fmt.Errorf("server resumed at the wrong offset (Content-Range %q); restarting download", resp.Header.Get("Content-Range"))
+		}
+		d.total = total
+	case http.StatusRequestedRangeNotSatisfiable:
+		if d.total >= 0 && d.written == d.total {
+			return nil // Already complete
+		}
+		// The partial file is stale; the next attempt starts over without a Range header
+		if err := d.restart(); err != nil {
+			return err
+		}
+		return errors.New("server rejected the resume range; restarting download")
+	default:
+		return statusError(resp.StatusCode)
 	}
 
-	tmpFile, err := os.CreateTemp("", "claude-tasks-*")
+	_, err = io.Copy(d, resp.Body)
+	d.report(true)
 	if err != nil {
-		return "", err
+		return err
 	}
-	defer tmpFile.Close()
+	if d.total >= 0 && d.written != d.total {
+		return fmt.Errorf("incomplete download: got %d of %d bytes", d.written, d.total)
+	}
+	return nil
+}
 
-	_, err = io.Copy(tmpFile, resp.Body)
-	if err != nil {
-		os.Remove(tmpFile.Name())
-		return "", err
+// parseContentRange returns the first byte and full size from a
+// "bytes start-end/size" header; size is -1 when the server sends "*"
+func parseContentRange(header string) (start, total int64, err error) {
+	spec, ok := strings.CutPrefix(header, "bytes ")
+	if !ok {
+		return 0, 0, fmt.Errorf("invalid Content-Range %q", header)
+	}
+	rng, size, ok := strings.Cut(spec, "/")
+	if !ok {
+		return 0, 0, fmt.Errorf("invalid Content-Range %q", header)
+	}
+	first, _, ok := strings.Cut(rng, "-")
+	if !ok {
+		return 0, 0, fmt.Errorf("invalid Content-Range %q", header)
 	}
+	if start, err = strconv.ParseInt(first, 10, 64); err != nil {
+		return 0, 0, fmt.Errorf("invalid Content-Range %q", header)
+	}
+	total = -1
+	if size != "*" {
+		if total, err = strconv.ParseInt(size, 10, 64); err != nil {
+			return 0, 0, fmt.Errorf("invalid Content-Range %q", header)
+		}
+	}
+	return start, total, nil
+}
+
+// restart discards anything downloaded so far
+func (d *download) restart() error {
+	d.written = 0
+	if err := d.file.Truncate(0); err != nil {
+		return err
+	}
+	_, err := d.file.Seek(0, io.SeekStart)
+	return err
+}
+
+// Write appends to the temp file and reports progress
+func (d *download) Write(p []byte) (int, error) {
+	n, err := d.file.Write(p)
+	d.written += int64(n)
+	d.report(false)
+	return n, err
+}
 
-	return tmpFile.Name(), nil
+// report prints progress at most a few times a second; final ends the line
+func (d *download) report(final bool) {
+	if !final && time.Since(d.lastReport) < 200*time.Millisecond {
+		return
+	}
+	d.lastReport = time.Now()
+	const mb = 1 << 20
+	if d.total > 0 {
+		fmt.Printf("\r  %.1f / %.1f MB (%d%%)", float64(d.written)/mb, float64(d.total)/mb, d.written*100/d.total)
+	} else {
+		fmt.Printf("\r  %.1f MB", float64(d.written)/mb)
+	}
+	if final {
+		fmt.Println()
+	}
 }
 
 func extractTarGz(tarPath string) (string, error) {
@@ -0,0 +1,108 @@
+package upgrade
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"testing"
+)
+
+// newDownload returns a download whose temp file already holds partial
+func newDownload(t *testing.T, url, partial string) *download {
+	t.Helper()
+	f, err := os.CreateTemp(t.TempDir(), "asset-*")
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { f.Close() })
+	d := &download{url: url, file: f, total: -1}
+	if _, err := d.Write([]byte(partial)); err != nil {
+		t.Fatal(err)
+	}
+	return d
+}
+
+func contents(t *testing.T, d *download) string {
+	t.Helper()
+	data, err := os.ReadFile(d.file.Name())
+	if err != nil {
+		t.Fatal(err)
+	}
+	return string(data)
+}
+
+func TestFetchResumesAtOffset(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if got := r.Header.Get("Range"); got != "bytes=5-" {
+			t.Errorf("Range = %q, want bytes=5-", got)
+		}
+		w.Header().Set("Content-Range", "bytes 5-9/10")
+		w.WriteHeader(http.StatusPartialContent)
+		fmt.Fprint(w, "56789")
+	}))
+	defer srv.Close()
+
+	d := newDownload(t, srv.URL, "01234")
+	if err := d.fetch(); err != nil {
+		t.Fatalf("fetch: %v", err)
+	}
+	if got := contents(t, d); got != "0123456789" {
+		t.Errorf("file = %q, want 0123456789", got)
+	}
+}
+
+func TestFetchRejectsMisalignedRange(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Range", "bytes 0-9/10")
+		w.WriteHeader(http.StatusPartialContent)
+		fmt.Fprint(w, "0123456789")
+	}))
+	defer srv.Close()
+
+	d := newDownload(t, srv.URL, "01234")
+	err := d.fetch()
+	if err == nil {
+		t.Fatal("fetch appended a range that starts before the end of the file")
+	}
+	var status statusError
+	if errors.As(err, &status) && !status.retryable() {
+		t.Errorf("misaligned range should be retryable, got %v", err)
+	}
+	if d.written != 0 || contents(t, d) != "" {
+		t.Errorf("partial file was not reset: written=%d", d.written)
+	}
+}
+
+func TestFetchRetriesAfterUnsatisfiableRange(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Header.Get("Range") != "" {
+			w.WriteHeader(http.StatusRequestedRangeNotSatisfiable)
+			return
+		}
+		fmt.Fprint(w, "0123456789")
+	}))
+	defer srv.Close()
+
+	d := newDownload(t, srv.URL, "stale")
+	err := d.fetch()
+	if err == nil {
+		t.Fatal("fetch succeeded on a 416")
+	}
+	var status statusError
+	if errors.As(err, &status) && !status.retryable() {
+		t.Fatalf("416 after a reset should be retryable, got %v", err)
+	}
+	if d.written != 0 {
+		t.Fatalf("partial file was not reset: written=%d", d.written)
+	}
+
+	// The next attempt starts over without a Range header
+	if err := d.fetch(); err != nil {
+		t.Fatalf("second fetch: %v", err)
+	}
+	if got := contents(t, d); got != "0123456789" {
+		t.Errorf("file = %q, want 0123456789", got)
+	}
+}
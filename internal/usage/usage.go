@@ -1,6 +1,7 @@
 package usage
 
 import (
+	"context"
 	"encoding/json"
 	"fmt"
 	"io"
@@ -13,6 +14,14 @@ import (
 
 const usageAPIURL = "https://api.anthropic.com/api/oauth/usage"
 
+// Retry policy for transient usage API failures. The total budget keeps a
+// struggling API from stalling callers that hold the client lock.
+const (
+	maxFetchAttempts = 3
+	retryBaseDelay   = 250 * time.Millisecond
+	fetchTimeout     = 15 * time.Second
+)
+
 // Bucket represents a usage time bucket (five_hour or seven_day)
 type Bucket struct {
 	Utilization float64 `json:"utilization"`
@@ -50,19 +59,50 @@ func NewClient() (*Client, error) {
 	}
 
 	return &Client{
-		httpClient: &http.Client{Timeout: 10 * time.Second},
+		httpClient: &http.Client{Timeout: 5 * time.Second},
 		token:      token,
 		cacheTTL:   30 * time.Second, // Cache for 30 seconds
 	}, nil
 }
 
-func readCredentials() (string, error) {
+// lastFetch records the outcome of this process's most recent usage API request
+var lastFetch struct {
+	sync.Mutex
+	at  time.Time
+	err error
+}
+
+// LastFetch returns when this process last queried the usage API and the error
+// that request ended with, if any. The time is zero before the first request;
+// cached responses don't count.
+func LastFetch() (time.Time, error) {
+	lastFetch.Lock()
+	defer lastFetch.Unlock()
+	return lastFetch.at, lastFetch.err
+}
+
+func recordFetch(err error) {
+	lastFetch.Lock()
+	defer lastFetch.Unlock()
+	lastFetch.at = time.Now()
+	lastFetch.err = err
+}
+
+// CredentialsPath returns where NewClient looks for the Claude CLI's OAuth credentials
+func CredentialsPath() (string, error) {
 	home, err := os.UserHomeDir()
 	if err != nil {
 		return "", fmt.Errorf("could not determine home directory: %w", err)
 	}
+	return filepath.Join(home, ".claude", ".credentials.json"), nil
+}
+
+func readCredentials() (string, error) {
+	credPath, err := CredentialsPath()
+	if err != nil {
+		return "", err
+	}
 
-	credPath := filepath.Join(home, ".claude", ".credentials.json")
 	data, err := os.ReadFile(credPath)
 	if err != nil {
 		return "", fmt.Errorf("credentials not found at %s: %w", credPath, err)
@@ -98,9 +138,52 @@ func (c *Client) Fetch() (*Response, error) {
 		return c.cache, nil
 	}
 
-	req, err := http.NewRequest("GET", usageAPIURL, nil)
+	ctx, cancel := context.WithTimeout(context.Background(), fetchTimeout)
+	defer cancel()
+
+	usage, err := c.fetchWithRetry(ctx)
+	recordFetch(err)
 	if err != nil {
-		return nil, fmt.Errorf("failed to create request: %w", err)
+		return nil, err
+	}
+	c.cache = usage
+	c.cacheTime = time.Now()
+	return usage, nil
+}
+
+// fetchWithRetry requests usage, retrying transient failures within ctx
+func (c *Client) fetchWithRetry(ctx context.Context) (*Response, error) {
+	var lastErr error
+	for attempt := 0; attempt < maxFetchAttempts; attempt++ {
+		if attempt > 0 {
+			// Exponential backoff: 250ms, 500ms, ...
+			delay := retryBaseDelay << (attempt - 1)
+			select {
+			case <-ctx.Done():
+				return nil, lastErr
+			case <-time.After(delay):
+			}
+		}
+
+		usage, retryable, err := c.fetchOnce(ctx)
+		if err == nil {
+			return usage, nil
+		}
+		lastErr = err
+		if !retryable || ctx.Err() != nil {
+			break
+		}
+	}
+
+	return nil, lastErr
+}
+
+// fetchOnce performs a single usage API request. The bool result reports
+// whether a failure is transient and worth retrying.
+func (c *Client) fetchOnce(ctx context.Context) (*Response, bool, error) {
+	req, err := http.NewRequestWithContext(ctx, "GET", usageAPIURL, nil)
+	if err != nil {
+		return nil, false, fmt.Errorf("failed to create request: %w", err)
 	}
 
 	req.Header.Set("Accept", "application/json")
@@ -109,29 +192,28 @@ func (c *Client) Fetch() (*Response, error) {
 
 	resp, err := c.httpClient.Do(req)
 	if err != nil {
-		return nil, fmt.Errorf("failed to fetch usage: %w", err)
+		// Network errors are treated as transient
+		return nil, true, fmt.Errorf("failed to fetch usage: %w", err)
 	}
 	defer resp.Body.Close()
 
 	if resp.StatusCode != http.StatusOK {
 		body, _ := io.ReadAll(resp.Body)
-		return nil, fmt.Errorf("API returned status %d: %s", resp.StatusCode, string(body))
+		retryable := resp.StatusCode == http.StatusTooManyRequests || resp.StatusCode >= 500
+		return nil, retryable, fmt.Errorf("API returned status %d: %s", resp.StatusCode, string(body))
 	}
 
 	body, err := io.ReadAll(resp.Body)
 	if err != nil {
-		return nil, fmt.Errorf("failed to read response body: %w", err)
+		return nil, true, fmt.Errorf("failed to read response body: %w", err)
 	}
 
 	var usage Response
 	if err := json.Unmarshal(body, &usage); err != nil {
-		return nil, fmt.Errorf("failed to parse usage response: %w", err)
+		return nil, false, fmt.Errorf("failed to parse usage response: %w", err)
 	}
 
-	c.cache = &usage
-	c.cacheTime = time.Now()
-
-	return &usage, nil
+	return &usage, false, nil
 }
 
 // CheckThreshold returns true if usage is below the threshold, false if above
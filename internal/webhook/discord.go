@@ -1,31 +1,40 @@
 package webhook
 
 import (
-	"bytes"
-	"encoding/json"
 	"fmt"
 	"net/http"
+	"sync/atomic"
 	"time"
 
+	"github.com/charmbracelet/x/ansi"
 	"github.com/kylemclaren/claude-tasks/internal/db"
 )
 
 // Discord handles Discord webhook notifications
 type Discord struct {
 	client *http.Client
+	config atomic.Pointer[Config] // Swappable while sends are in flight
 }
 
 // NewDiscord creates a new Discord webhook handler
 func NewDiscord() *Discord {
-	return &Discord{
-		client: &http.Client{Timeout: 10 * time.Second},
+	d := &Discord{
+		client: &http.Client{}, // Attempts are bounded by Config.Timeout
 	}
+	d.config.Store(DefaultConfig())
+	return d
+}
+
+// SetConfig replaces the notification options used by subsequent sends
+func (d *Discord) SetConfig(cfg *Config) {
+	d.config.Store(cfg)
 }
 
 // DiscordEmbed represents a Discord embed object
 type DiscordEmbed struct {
 	Title       string       `json:"title"`
-	Description string       `json:"description"`
+	URL         string       `json:"url,omitempty"`
+	Description string       `json:"description,omitempty"`
 	Color       int          `json:"color"`
 	Fields      []EmbedField `json:"fields,omitempty"`
 	Timestamp   string       `json:"timestamp,omitempty"`
@@ -62,6 +71,9 @@ func (d *Discord) SendResult(webhookURL string, task *db.Task, run *db.TaskRun)
 	case db.RunStatusFailed:
 		color = 0xFF0000 // Red
 		statusEmoji = "❌"
+	case db.RunStatusSkipped:
+		color = 0x808080 // Gray
+		statusEmoji = "⏭️"
 	default:
 		color = 0xFFFF00 // Yellow
 		statusEmoji = "⏳"
@@ -69,11 +81,18 @@ func (d *Discord) SendResult(webhookURL string, task *db.Task, run *db.TaskRun)
 
 	// Truncate output if too long (Discord has 4096 char limit for embed description)
 	// Keep markdown formatting - Discord embeds support bold, italic, links, lists, etc.
-	output := run.Output
-	if len(output) > 3500 {
-		output = output[:3500] + "\n\n*... (truncated)*"
+	cfg := d.config.Load()
+	detail := cfg.detail(task)
+	preview := orDefault(cfg.PreviewLength, db.DefaultListPreviewLength)
+	output := ansi.Strip(run.Output) // Colors kept for the TUI are noise in chat
+	switch detail {
+	case db.WebhookDetailSummary:
+		output = firstLine(output, preview)
+	case db.WebhookDetailStatus:
+		output = ""
 	}
-	if output == "" {
+	output = truncateOutput(output, orDefault(cfg.DiscordOutputLimit, db.DefaultDiscordOutputLimit), "\n\n*... (truncated)*")
+	if output == "" && detail != db.WebhookDetailStatus {
 		output = "*No output*"
 	}
 
@@ -87,7 +106,7 @@ func (d *Discord) SendResult(webhookURL string, task *db.Task, run *db.TaskRun)
 	}
 
 	embed := DiscordEmbed{
-		Title:       fmt.Sprintf("%s Task: %s", statusEmoji, task.Name),
+		Title:       truncateTitle(fmt.Sprintf("%s Task: %s", statusEmoji, task.Name), discordTitleLimit),
 		Description: output,
 		Color:       color,
 		Fields: []EmbedField{
@@ -99,12 +118,19 @@ func (d *Discord) SendResult(webhookURL string, task *db.Task, run *db.TaskRun)
 		Footer:    &EmbedFooter{Text: "Claude Tasks Scheduler"},
 	}
 
+	// Link to the full, untruncated run (footers are plain text, so use the embed URL too)
+	if runURL := d.config.Load().RunURL(run); runURL != "" {
+		embed.URL = runURL
+		embed.Footer.Text = "Claude Tasks Scheduler • " + runURL
+	}
+
 	// Add error field if present - errors still use code block for readability
-	if run.Error != "" {
+	if run.Error != "" && detail != db.WebhookDetailStatus {
 		errMsg := run.Error
-		if len(errMsg) > 500 {
-			errMsg = errMsg[:500] + "..."
+		if detail == db.WebhookDetailSummary {
+			errMsg = firstLine(errMsg, preview)
 		}
+		errMsg = truncateOutput(errMsg, 500, "...")
 		embed.Fields = append(embed.Fields, EmbedField{
 			Name:   "⚠️ Error",
 			Value:  fmt.Sprintf("```\n%s\n```", errMsg),
@@ -119,27 +145,56 @@ func (d *Discord) SendResult(webhookURL string, task *db.Task, run *db.TaskRun)
 	return d.send(webhookURL, payload)
 }
 
-func (d *Discord) send(webhookURL string, payload DiscordPayload) error {
-	data, err := json.Marshal(payload)
-	if err != nil {
-		return fmt.Errorf("failed to marshal payload: %w", err)
+// SendAlert sends a one-line warning about a run, e.g. one that hasn't finished yet
+func (d *Discord) SendAlert(webhookURL string, task *db.Task, run *db.TaskRun, message string) error {
+	embed := DiscordEmbed{
+		Title:       truncateTitle(fmt.Sprintf("⏰ Task: %s", task.Name), discordTitleLimit),
+		Description: message,
+		Color:       0xFFA500, // Orange
+		Fields: []EmbedField{
+			{Name: "Working Dir", Value: fmt.Sprintf("`%s`", task.WorkingDir), Inline: true},
+		},
+		Timestamp: run.StartedAt.Format(time.RFC3339),
+		Footer:    &EmbedFooter{Text: "Claude Tasks Scheduler"},
 	}
-
-	req, err := http.NewRequest("POST", webhookURL, bytes.NewBuffer(data))
-	if err != nil {
-		return fmt.Errorf("failed to create request: %w", err)
+	if runURL := d.config.Load().RunURL(run); runURL != "" {
+		embed.URL = runURL
 	}
-	req.Header.Set("Content-Type", "application/json")
+	return d.send(webhookURL, DiscordPayload{Embeds: []DiscordEmbed{embed}})
+}
 
-	resp, err := d.client.Do(req)
-	if err != nil {
-		return fmt.Errorf("failed to send webhook: %w", err)
+// SendDigest sends a report of the task's recent runs: counts, success rate, and the last error
+func (d *Discord) SendDigest(webhookURL string, task *db.Task, digest *Digest) error {
+	stats := digest.Stats
+	cfg := d.config.Load()
+	embed := DiscordEmbed{
+		Title:       truncateTitle(fmt.Sprintf("📊 Report: %s", task.Name), discordTitleLimit),
+		Description: fmt.Sprintf("Last %d of %d runs", stats.SampleSize, stats.TotalRuns),
+		Color:       0x5865F2, // Blurple
+		Fields: []EmbedField{
+			{Name: "Completed", Value: fmt.Sprintf("%d", stats.Completed), Inline: true},
+			{Name: "Failed", Value: fmt.Sprintf("%d", stats.Failed), Inline: true},
+			{Name: "Skipped", Value: fmt.Sprintf("%d", stats.Skipped), Inline: true},
+			{Name: "Success Rate", Value: fmt.Sprintf("%.0f%%", stats.SuccessRate), Inline: true},
+			{Name: "Working Dir", Value: fmt.Sprintf("`%s`", task.WorkingDir), Inline: true},
+		},
+		Timestamp: time.Now().Format(time.RFC3339),
+		Footer:    &EmbedFooter{Text: "Claude Tasks Scheduler"},
 	}
-	defer resp.Body.Close()
-
-	if resp.StatusCode < 200 || resp.StatusCode >= 300 {
-		return fmt.Errorf("webhook returned status %d", resp.StatusCode)
+	if run := digest.LastFailure; run != nil {
+		errMsg := firstLine(ansi.Strip(run.Error), orDefault(cfg.PreviewLength, db.DefaultListPreviewLength))
+		if errMsg == "" {
+			errMsg = "(no error message)"
+		}
+		value := fmt.Sprintf("%s\n```\n%s\n```", run.StartedAt.Format("2006-01-02 15:04"), errMsg)
+		if runURL := cfg.RunURL(run); runURL != "" {
+			value += "\n" + runURL
+		}
+		embed.Fields = append(embed.Fields, EmbedField{Name: "⚠️ Last Error", Value: value, Inline: false})
 	}
+	return d.send(webhookURL, DiscordPayload{Embeds: []DiscordEmbed{embed}})
+}
 
-	return nil
+func (d *Discord) send(webhookURL string, payload DiscordPayload) error {
+	return postJSON(d.client, webhookURL, ValidateDiscordURL, payload, d.config.Load())
 }
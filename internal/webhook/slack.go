@@ -1,26 +1,34 @@
 package webhook
 
 import (
-	"bytes"
-	"encoding/json"
 	"fmt"
 	"net/http"
 	"strings"
+	"sync/atomic"
 	"time"
 
+	"github.com/charmbracelet/x/ansi"
 	"github.com/kylemclaren/claude-tasks/internal/db"
 )
 
 // Slack handles Slack webhook notifications
 type Slack struct {
 	client *http.Client
+	config atomic.Pointer[Config] // Swappable while sends are in flight
 }
 
 // NewSlack creates a new Slack webhook handler
 func NewSlack() *Slack {
-	return &Slack{
-		client: &http.Client{Timeout: 10 * time.Second},
+	s := &Slack{
+		client: &http.Client{}, // Attempts are bounded by Config.Timeout
 	}
+	s.config.Store(DefaultConfig())
+	return s
+}
+
+// SetConfig replaces the notification options used by subsequent sends
+func (s *Slack) SetConfig(cfg *Config) {
+	s.config.Store(cfg)
 }
 
 // SlackBlock represents a Slack Block Kit block
@@ -69,6 +77,10 @@ func (s *Slack) SendResult(webhookURL string, task *db.Task, run *db.TaskRun) er
 		color = "#FF0000" // Red
 		statusEmoji = ":x:"
 		statusText = "Failed"
+	case db.RunStatusSkipped:
+		color = "#808080" // Gray
+		statusEmoji = ":fast_forward:"
+		statusText = "Skipped"
 	default:
 		color = "#FFFF00" // Yellow
 		statusEmoji = ":hourglass:"
@@ -85,10 +97,14 @@ func (s *Slack) SendResult(webhookURL string, task *db.Task, run *db.TaskRun) er
 	}
 
 	// Convert markdown to Slack mrkdwn format
-	output := convertToSlackMarkdown(run.Output)
-	if len(output) > 2500 {
-		output = output[:2500] + "\n... _(truncated)_"
+	cfg := s.config.Load()
+	detail := cfg.detail(task)
+	preview := orDefault(cfg.PreviewLength, db.DefaultListPreviewLength)
+	output := convertToSlackMarkdown(ansi.Strip(run.Output))
+	if detail == db.WebhookDetailSummary {
+		output = firstLine(output, preview)
 	}
+	output = truncateOutput(output, orDefault(cfg.SlackOutputLimit, db.DefaultSlackOutputLimit), "\n... _(truncated)_")
 	if output == "" {
 		output = "_No output_"
 	}
@@ -99,7 +115,7 @@ func (s *Slack) SendResult(webhookURL string, task *db.Task, run *db.TaskRun) er
 			Type: "header",
 			Text: &SlackTextObj{
 				Type:  "plain_text",
-				Text:  fmt.Sprintf("%s Task: %s", statusEmoji, task.Name),
+				Text:  truncateTitle(fmt.Sprintf("%s Task: %s", statusEmoji, task.Name), slackHeaderLimit),
 				Emoji: true,
 			},
 		},
@@ -112,24 +128,29 @@ func (s *Slack) SendResult(webhookURL string, task *db.Task, run *db.TaskRun) er
 				{Type: "mrkdwn", Text: fmt.Sprintf("*Started:*\n<!date^%d^{date_short} {time}|%s>", run.StartedAt.Unix(), run.StartedAt.Format(time.RFC3339))},
 			},
 		},
-		{
-			Type: "divider",
-		},
-		{
-			Type: "section",
-			Text: &SlackTextObj{
-				Type: "mrkdwn",
-				Text: output,
+	}
+	if detail != db.WebhookDetailStatus {
+		blocks = append(blocks,
+			SlackBlock{
+				Type: "divider",
 			},
-		},
+			SlackBlock{
+				Type: "section",
+				Text: &SlackTextObj{
+					Type: "mrkdwn",
+					Text: output,
+				},
+			},
+		)
 	}
 
 	// Add error block if present
-	if run.Error != "" {
+	if run.Error != "" && detail != db.WebhookDetailStatus {
 		errMsg := run.Error
-		if len(errMsg) > 500 {
-			errMsg = errMsg[:500] + "..."
+		if detail == db.WebhookDetailSummary {
+			errMsg = firstLine(errMsg, preview)
 		}
+		errMsg = truncateOutput(errMsg, 500, "...")
 		blocks = append(blocks, SlackBlock{
 			Type: "section",
 			Text: &SlackTextObj{
@@ -139,11 +160,15 @@ func (s *Slack) SendResult(webhookURL string, task *db.Task, run *db.TaskRun) er
 		})
 	}
 
-	// Add footer context
+	// Add footer context, linking to the full run when a public base URL is set
+	footer := "Claude Tasks Scheduler"
+	if runURL := s.config.Load().RunURL(run); runURL != "" {
+		footer += fmt.Sprintf(" • <%s|View full run>", runURL)
+	}
 	blocks = append(blocks, SlackBlock{
 		Type: "context",
 		Elements: []SlackElement{
-			{Type: "mrkdwn", Text: "Claude Tasks Scheduler"},
+			{Type: "mrkdwn", Text: footer},
 		},
 	})
 
@@ -223,27 +248,62 @@ func convertToSlackMarkdown(text string) string {
 	return strings.Join(lines, "\n")
 }
 
-func (s *Slack) send(webhookURL string, payload SlackPayload) error {
-	data, err := json.Marshal(payload)
-	if err != nil {
-		return fmt.Errorf("failed to marshal payload: %w", err)
+// SendAlert sends a one-line warning about a run, e.g. one that hasn't finished yet
+func (s *Slack) SendAlert(webhookURL string, task *db.Task, run *db.TaskRun, message string) error {
+	text := fmt.Sprintf(":alarm_clock: *Task: %s* %s", task.Name, message)
+	if runURL := s.config.Load().RunURL(run); runURL != "" {
+		text += fmt.Sprintf(" (<%s|view run>)", runURL)
 	}
+	return s.send(webhookURL, SlackPayload{Text: text})
+}
 
-	req, err := http.NewRequest("POST", webhookURL, bytes.NewBuffer(data))
-	if err != nil {
-		return fmt.Errorf("failed to create request: %w", err)
+// SendDigest sends a report of the task's recent runs: counts, success rate, and the last error
+func (s *Slack) SendDigest(webhookURL string, task *db.Task, digest *Digest) error {
+	stats := digest.Stats
+	cfg := s.config.Load()
+	blocks := []SlackBlock{
+		{
+			Type: "header",
+			Text: &SlackTextObj{
+				Type:  "plain_text",
+				Text:  truncateTitle(fmt.Sprintf(":bar_chart: Report: %s", task.Name), slackHeaderLimit),
+				Emoji: true,
+			},
+		},
+		{
+			Type: "section",
+			Fields: []SlackTextObj{
+				{Type: "mrkdwn", Text: fmt.Sprintf("*Runs:*\nLast %d of %d", stats.SampleSize, stats.TotalRuns)},
+				{Type: "mrkdwn", Text: fmt.Sprintf("*Success Rate:*\n%.0f%%", stats.SuccessRate)},
+				{Type: "mrkdwn", Text: fmt.Sprintf("*Completed / Failed / Skipped:*\n%d / %d / %d", stats.Completed, stats.Failed, stats.Skipped)},
+				{Type: "mrkdwn", Text: fmt.Sprintf("*Working Dir:*\n`%s`", task.WorkingDir)},
+			},
+		},
 	}
-	req.Header.Set("Content-Type", "application/json")
-
-	resp, err := s.client.Do(req)
-	if err != nil {
-		return fmt.Errorf("failed to send webhook: %w", err)
+	if run := digest.LastFailure; run != nil {
+		errMsg := firstLine(ansi.Strip(run.Error), orDefault(cfg.PreviewLength, db.DefaultListPreviewLength))
+		if errMsg == "" {
+			errMsg = "(no error message)"
+		}
+		text := fmt.Sprintf(":warning: *Last Error* <!date^%d^{date_short} {time}|%s>", run.StartedAt.Unix(), run.StartedAt.Format(time.RFC3339))
+		if runURL := cfg.RunURL(run); runURL != "" {
+			text += fmt.Sprintf(" (<%s|view run>)", runURL)
+		}
+		blocks = append(blocks, SlackBlock{
+			Type: "section",
+			Text: &SlackTextObj{Type: "mrkdwn", Text: fmt.Sprintf("%s\n```%s```", text, errMsg)},
+		})
 	}
-	defer resp.Body.Close()
+	blocks = append(blocks, SlackBlock{
+		Type:     "context",
+		Elements: []SlackElement{{Type: "mrkdwn", Text: "Claude Tasks Scheduler"}},
+	})
 
-	if resp.StatusCode < 200 || resp.StatusCode >= 300 {
-		return fmt.Errorf("webhook returned status %d", resp.StatusCode)
-	}
+	return s.send(webhookURL, SlackPayload{
+		Attachments: []SlackAttachment{{Color: "#5865F2", Blocks: blocks}},
+	})
+}
 
-	return nil
+func (s *Slack) send(webhookURL string, payload SlackPayload) error {
+	return postJSON(s.client, webhookURL, ValidateSlackURL, payload, s.config.Load())
 }
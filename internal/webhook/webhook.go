@@ -0,0 +1,284 @@
+package webhook
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/url"
+	"os"
+	"regexp"
+	"strings"
+	"time"
+	"unicode/utf8"
+
+	"github.com/kylemclaren/claude-tasks/internal/db"
+)
+
+// DefaultAttempts is how many times a webhook send is tried before giving up
+const DefaultAttempts = 3
+
+// DefaultTimeout bounds each delivery attempt until settings are applied
+const DefaultTimeout = db.DefaultWebhookTimeoutSeconds * time.Second
+
+// Config holds settings-driven notification options
+type Config struct {
+	Attempts      int           // Delivery attempts per send
+	Timeout       time.Duration // Per attempt, including connecting and reading the response
+	PublicBaseURL string        // When set, messages link back to the run via the API
+	Detail        string        // Default result message detail for tasks without their own
+
+	// Output lengths; zero values use the db defaults
+	DiscordOutputLimit int
+	SlackOutputLimit   int
+	PreviewLength      int // First-line previews in summary messages
+
+	// Accept any http(s) URL when a ${NAME} reference is expanded, like the
+	// relaxed_webhook_urls setting does when a URL is saved
+	RelaxedURLs bool
+}
+
+// Title limits imposed by the chat APIs; longer payloads are rejected outright
+const (
+	discordTitleLimit = 256 // Embed title
+	slackHeaderLimit  = 150 // Header block plain_text
+)
+
+// truncateTitle shortens s to at most limit characters, ending in an ellipsis when cut
+func truncateTitle(s string, limit int) string {
+	runes := []rune(s)
+	if len(runes) <= limit {
+		return s
+	}
+	return string(runes[:limit-1]) + "…"
+}
+
+// DefaultConfig returns the configuration used until settings are applied
+func DefaultConfig() *Config {
+	return &Config{Attempts: DefaultAttempts, Timeout: DefaultTimeout, Detail: db.WebhookDetailFull}
+}
+
+// detail returns how much of a run task's result messages include
+func (c *Config) detail(task *db.Task) string {
+	if task.WebhookDetail != "" {
+		return task.WebhookDetail
+	}
+	if c.Detail != "" {
+		return c.Detail
+	}
+	return db.WebhookDetailFull
+}
+
+// orDefault returns n, or def when n is unset
+func orDefault(n, def int) int {
+	if n > 0 {
+		return n
+	}
+	return def
+}
+
+// truncateOutput cuts s to at most limit bytes on a UTF-8 boundary, appending marker when cut
+func truncateOutput(s string, limit int, marker string) string {
+	if len(s) <= limit {
+		return s
+	}
+	cut := limit
+	for cut > 0 && !utf8.RuneStart(s[cut]) {
+		cut--
+	}
+	return s[:cut] + marker
+}
+
+// firstLine returns the first non-blank line of s, shortened to limit characters
+func firstLine(s string, limit int) string {
+	for _, line := range strings.Split(s, "\n") {
+		if line = strings.TrimSpace(line); line != "" {
+			return truncateTitle(line, limit)
+		}
+	}
+	return ""
+}
+
+// Digest summarizes a task's recent runs for an on-demand report
+type Digest struct {
+	Stats       *db.RunStats
+	LastFailure *db.TaskRun // Most recent failed run in the sample; nil when none failed
+}
+
+// RunURL returns the API URL for a run, or "" when no public base URL is configured
+func (c *Config) RunURL(run *db.TaskRun) string {
+	if c.PublicBaseURL == "" {
+		return ""
+	}
+	return fmt.Sprintf("%s/api/v1/tasks/%d/runs/%d", strings.TrimRight(c.PublicBaseURL, "/"), run.TaskID, run.ID)
+}
+
+// ValidateDiscordURL checks that webhookURL is a Discord webhook URL. In relaxed
+// mode any absolute http(s) URL is accepted, e.g. for relays or proxies.
+func ValidateDiscordURL(webhookURL string, relaxed bool) error {
+	return validateURL(webhookURL, relaxed, "/api/webhooks/", "discord.com", "discordapp.com", "ptb.discord.com", "canary.discord.com")
+}
+
+// ValidateSlackURL checks that webhookURL is a Slack incoming webhook URL; see ValidateDiscordURL for relaxed
+func ValidateSlackURL(webhookURL string, relaxed bool) error {
+	return validateURL(webhookURL, relaxed, "/services/", "hooks.slack.com")
+}
+
+// envRef matches a ${NAME} reference to a process environment variable
+var envRef = regexp.MustCompile(`\$\{([A-Za-z_][A-Za-z0-9_]*)\}`)
+
+// ExpandEnv replaces ${NAME} references in s with the named environment
+// variables. A reference to an unset or empty variable is an error rather
+// than an empty string, so a missing secret never yields a truncated URL.
+func ExpandEnv(s string) (string, error) {
+	var missing []string
+	expanded := envRef.ReplaceAllStringFunc(s, func(ref string) string {
+		name := envRef.FindStringSubmatch(ref)[1]
+		value := os.Getenv(name)
+		if value == "" {
+			missing = append(missing, name)
+		}
+		return value
+	})
+	switch len(missing) {
+	case 0:
+		return expanded, nil
+	case 1:
+		return "", fmt.Errorf("environment variable %s is not set", missing[0])
+	default:
+		return "", fmt.Errorf("environment variables %s are not set", strings.Join(missing, ", "))
+	}
+}
+
+// envPlaceholder stands in for ${NAME} references so the literal rest of a URL can be checked
+const envPlaceholder = "envref"
+
+// validateURL checks webhookURL is https on one of hosts under pathPrefix, or just http(s) when relaxed.
+// ${NAME} references are replaced by a placeholder first, so a literal scheme and host are still
+// checked. A reference inside the scheme or host can only be checked once expanded at send time.
+func validateURL(webhookURL string, relaxed bool, pathPrefix string, hosts ...string) error {
+	literal := envRef.ReplaceAllString(webhookURL, envPlaceholder)
+	if strings.Contains(literal, "${") {
+		return fmt.Errorf("has a malformed ${NAME} reference")
+	}
+	if loc := envRef.FindStringIndex(webhookURL); loc != nil {
+		if loc[0] < authorityEnd(webhookURL) {
+			return nil
+		}
+		webhookURL = literal
+	}
+	u, err := url.Parse(webhookURL)
+	if err != nil || u.Host == "" || (u.Scheme != "https" && u.Scheme != "http") {
+		return fmt.Errorf("must be an absolute http(s) URL")
+	}
+	if relaxed {
+		return nil
+	}
+	expected := "https://" + hosts[0] + pathPrefix + "..."
+	if u.Scheme != "https" || !strings.HasPrefix(u.Path, pathPrefix) {
+		return fmt.Errorf("must look like %s", expected)
+	}
+	for _, host := range hosts {
+		if strings.EqualFold(u.Hostname(), host) {
+			return nil
+		}
+	}
+	return fmt.Errorf("must look like %s", expected)
+}
+
+// authorityEnd returns the offset just past rawURL's scheme and host, or
+// len(rawURL) when it has no "://" and so could be anything
+func authorityEnd(rawURL string) int {
+	i := strings.Index(rawURL, "://")
+	if i < 0 {
+		return len(rawURL)
+	}
+	start := i + len("://")
+	if end := strings.IndexAny(rawURL[start:], "/?#"); end >= 0 {
+		return start + end
+	}
+	return len(rawURL)
+}
+
+// retryBackoff is the delay before the second attempt; it doubles after each failure
+const retryBackoff = time.Second
+
+// postJSON posts payload to webhookURL, retrying network errors, 429s, and 5xx responses.
+// ${NAME} references in webhookURL are expanded first, and the result must pass validate.
+func postJSON(client *http.Client, webhookURL string, validate func(string, bool) error, payload interface{}, cfg *Config) error {
+	if envRef.MatchString(webhookURL) {
+		expanded, err := ExpandEnv(webhookURL)
+		if err != nil {
+			return fmt.Errorf("resolving webhook URL: %w", err)
+		}
+		// Saving only checked the literal parts; the variables may point anywhere
+		if err := validate(expanded, cfg.RelaxedURLs); err != nil {
+			return fmt.Errorf("expanded webhook URL %w", err)
+		}
+		webhookURL = expanded
+	}
+	data, err := json.Marshal(payload)
+	if err != nil {
+		return fmt.Errorf("failed to marshal payload: %w", err)
+	}
+	attempts := cfg.Attempts
+	if attempts < 1 {
+		attempts = 1
+	}
+	timeout := cfg.Timeout
+	if timeout <= 0 {
+		timeout = DefaultTimeout
+	}
+
+	backoff := retryBackoff
+	for attempt := 1; ; attempt++ {
+		retryable, err := postOnce(client, webhookURL, data, timeout)
+		if err == nil {
+			return nil
+		}
+		if !retryable || attempt >= attempts {
+			if attempt > 1 {
+				return fmt.Errorf("%w (after %d attempts)", err, attempt)
+			}
+			return err
+		}
+		time.Sleep(backoff)
+		backoff *= 2
+	}
+}
+
+// postOnce makes a single delivery attempt and reports whether a failure is worth retrying
+func postOnce(client *http.Client, webhookURL string, data []byte, timeout time.Duration) (bool, error) {
+	ctx, cancel := context.WithTimeout(context.Background(), timeout)
+	defer cancel()
+	req, err := http.NewRequestWithContext(ctx, "POST", webhookURL, bytes.NewReader(data))
+	if err != nil {
+		return false, fmt.Errorf("failed to create request: %w", withoutURL(err))
+	}
+	req.Header.Set("Content-Type", "application/json")
+
+	resp, err := client.Do(req)
+	if err != nil {
+		return true, fmt.Errorf("failed to send webhook: %w", withoutURL(err))
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode < 200 || resp.StatusCode >= 300 {
+		retryable := resp.StatusCode == http.StatusTooManyRequests || resp.StatusCode >= 500
+		return retryable, fmt.Errorf("webhook returned status %d", resp.StatusCode)
+	}
+
+	return false, nil
+}
+
+// withoutURL drops the URL from a *url.Error. It may hold an expanded secret,
+// and delivery errors are stored with the run.
+func withoutURL(err error) error {
+	var urlErr *url.Error
+	if errors.As(err, &urlErr) {
+		return urlErr.Err
+	}
+	return err
+}
@@ -0,0 +1,126 @@
+package webhook
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+	"unicode/utf8"
+
+	"github.com/kylemclaren/claude-tasks/internal/db"
+)
+
+// capture starts a server that decodes each posted payload into v
+func capture(t *testing.T, v any) *httptest.Server {
+	t.Helper()
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if err := json.NewDecoder(r.Body).Decode(v); err != nil {
+			t.Errorf("decoding payload: %v", err)
+		}
+		w.WriteHeader(http.StatusNoContent)
+	}))
+	t.Cleanup(srv.Close)
+	return srv
+}
+
+// longNameTask returns a task whose name is 300 multibyte runes
+func longNameTask() (*db.Task, *db.TaskRun) {
+	started := time.Now().Add(-time.Minute)
+	ended := time.Now()
+	task := &db.Task{ID: 1, Name: strings.Repeat("日", 300), WorkingDir: "/tmp"}
+	run := &db.TaskRun{ID: 1, TaskID: 1, Status: db.RunStatusCompleted, StartedAt: started, EndedAt: &ended, Output: "done"}
+	return task, run
+}
+
+func checkTitle(t *testing.T, title string, limit int) {
+	t.Helper()
+	if !utf8.ValidString(title) {
+		t.Fatalf("title is not valid UTF-8: %q", title)
+	}
+	if n := utf8.RuneCountInString(title); n > limit {
+		t.Errorf("title has %d runes, want at most %d", n, limit)
+	}
+	if !strings.HasSuffix(title, "…") {
+		t.Errorf("truncated title %q does not end in an ellipsis", title)
+	}
+}
+
+func TestDiscordTruncatesLongTaskName(t *testing.T) {
+	var payload DiscordPayload
+	srv := capture(t, &payload)
+	task, run := longNameTask()
+
+	if err := NewDiscord().SendResult(srv.URL, task, run); err != nil {
+		t.Fatalf("SendResult: %v", err)
+	}
+	if len(payload.Embeds) != 1 {
+		t.Fatalf("got %d embeds, want 1", len(payload.Embeds))
+	}
+	checkTitle(t, payload.Embeds[0].Title, discordTitleLimit)
+}
+
+func TestSlackTruncatesLongTaskName(t *testing.T) {
+	var payload SlackPayload
+	srv := capture(t, &payload)
+	task, run := longNameTask()
+
+	if err := NewSlack().SendResult(srv.URL, task, run); err != nil {
+		t.Fatalf("SendResult: %v", err)
+	}
+	if len(payload.Attachments) != 1 || len(payload.Attachments[0].Blocks) == 0 {
+		t.Fatalf("payload has no blocks: %+v", payload)
+	}
+	header := payload.Attachments[0].Blocks[0]
+	if header.Type != "header" || header.Text == nil {
+		t.Fatalf("first block is %q, want a header", header.Type)
+	}
+	checkTitle(t, header.Text.Text, slackHeaderLimit)
+}
+
+func TestValidateURLWithEnvRefs(t *testing.T) {
+	tests := []struct {
+		url     string
+		relaxed bool
+		ok      bool
+	}{
+		{"https://discord.com/api/webhooks/${ID}/${TOKEN}", false, true},
+		{"${DISCORD_WEBHOOK_URL}", false, true}, // Checked once expanded
+		{"https://${HOST}/api/webhooks/1/x", false, true},
+		{"https://attacker.example/x${A}", false, false},
+		{"https://attacker.example/api/webhooks/${ID}", false, false},
+		{"http://discord.com/api/webhooks/${ID}", false, false},
+		{"https://discord.com/other/${ID}", false, false},
+		{"https://attacker.example/x${A}", true, true},
+		{"ftp://example.com/${A}", true, false},
+		{"https://discord.com/api/webhooks/${ID", false, false},
+	}
+	for _, tt := range tests {
+		err := ValidateDiscordURL(tt.url, tt.relaxed)
+		if (err == nil) != tt.ok {
+			t.Errorf("ValidateDiscordURL(%q, %v) = %v, want ok=%v", tt.url, tt.relaxed, err, tt.ok)
+		}
+	}
+}
+
+func TestSendRechecksExpandedURL(t *testing.T) {
+	hit := false
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		hit = true
+		w.WriteHeader(http.StatusNoContent)
+	}))
+	defer srv.Close()
+	t.Setenv("TEST_WEBHOOK_URL", srv.URL)
+	task, run := longNameTask()
+
+	slack := NewSlack()
+	if err := slack.SendResult("${TEST_WEBHOOK_URL}", task, run); err == nil || hit {
+		t.Fatalf("strict send to %s: err=%v hit=%v, want a rejected URL", srv.URL, err, hit)
+	}
+
+	slack.SetConfig(&Config{Attempts: 1, RelaxedURLs: true})
+	if err := slack.SendResult("${TEST_WEBHOOK_URL}", task, run); err != nil || !hit {
+		t.Fatalf("relaxed send: err=%v hit=%v, want delivered", err, hit)
+	}
+}
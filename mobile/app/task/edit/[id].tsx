@@ -30,7 +30,7 @@ export default function EditTaskScreen() {
   const [name, setName] = useState('');
   const [prompt, setPrompt] = useState('');
   const [cronExpr, setCronExpr] = useState('');
-  const [workingDir, setWorkingDir] = useState('.');
+  const [workingDir, setWorkingDir] = useState('');
   const [showCronPicker, setShowCronPicker] = useState(false);
   const [initialized, setInitialized] = useState(false);
 
@@ -93,7 +93,7 @@ export default function EditTaskScreen() {
       name: name.trim(),
       prompt: prompt.trim(),
       cron_expr: isOneOff ? '' : cronExpr.trim(),
-      working_dir: workingDir.trim() || '.',
+      working_dir: workingDir.trim(), // Empty uses the server's default_working_dir
       enabled: task?.enabled ?? true,
     };
 
@@ -206,7 +206,7 @@ export default function EditTaskScreen() {
               </Text>
             </Pressable>
             <Text style={[styles.hint, { color: colors.textMuted }]}>
-              6-field cron: second minute hour day month weekday
+              6-field cron: second minute hour day month weekday (5-field also works)
             </Text>
           </View>
         ) : (
@@ -278,7 +278,7 @@ export default function EditTaskScreen() {
             style={[styles.input, { borderColor: colors.border, backgroundColor: colors.surface, color: colors.textPrimary }]}
             value={workingDir}
             onChangeText={setWorkingDir}
-            placeholder="."
+            placeholder="/path/to/project (default if empty)"
             placeholderTextColor={colors.textMuted}
             autoCapitalize="none"
             autoCorrect={false}
@@ -27,7 +27,7 @@ export default function NewTaskScreen() {
   const [name, setName] = useState('');
   const [prompt, setPrompt] = useState('');
   const [cronExpr, setCronExpr] = useState('');
-  const [workingDir, setWorkingDir] = useState('.');
+  const [workingDir, setWorkingDir] = useState('');
   const [showCronPicker, setShowCronPicker] = useState(false);
 
   // One-off task state
@@ -73,7 +73,7 @@ export default function NewTaskScreen() {
       name: name.trim(),
       prompt: prompt.trim(),
       cron_expr: isOneOff ? '' : cronExpr.trim(),
-      working_dir: workingDir.trim() || '.',
+      working_dir: workingDir.trim(), // Empty uses the server's default_working_dir
       enabled: true,
     };
 
@@ -176,7 +176,7 @@ export default function NewTaskScreen() {
               </Text>
             </Pressable>
             <Text style={[styles.hint, { color: colors.textMuted }]}>
-              6-field cron: second minute hour day month weekday
+              6-field cron: second minute hour day month weekday (5-field also works)
             </Text>
           </View>
         ) : (
@@ -248,7 +248,7 @@ export default function NewTaskScreen() {
             style={[styles.input, { borderColor: colors.border, backgroundColor: colors.surface, color: colors.textPrimary }]}
             value={workingDir}
             onChangeText={setWorkingDir}
-            placeholder="."
+            placeholder="/path/to/project (default if empty)"
             placeholderTextColor={colors.textMuted}
             autoCapitalize="none"
             autoCorrect={false}
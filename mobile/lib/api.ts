@@ -7,6 +7,7 @@ import type {
   TaskRunsResponse,
   Settings,
   Usage,
+  UsageStatus,
   SuccessResponse,
   HealthResponse,
 } from './types';
@@ -129,6 +130,14 @@ class ApiClient {
     });
   }
 
+  // Only the given fields change, so concurrent edits to other fields aren't lost
+  async patchTask(id: number, fields: Partial<TaskRequest>): Promise<Task> {
+    return this.request(`/tasks/${id}`, {
+      method: 'PATCH',
+      body: JSON.stringify(fields),
+    });
+  }
+
   async deleteTask(id: number): Promise<SuccessResponse> {
     return this.request(`/tasks/${id}`, { method: 'DELETE' });
   }
@@ -165,6 +174,10 @@ class ApiClient {
   async getUsage(): Promise<Usage> {
     return this.request('/usage');
   }
+
+  async getUsageStatus(): Promise<UsageStatus> {
+    return this.request('/usage/status');
+  }
 }
 
 export const apiClient = new ApiClient();
@@ -1,29 +1,66 @@
+export type OutputFormat = 'text' | 'json' | 'stream-json';
+export type WebhookDetail = 'full' | 'summary' | 'status-only';
+
 export interface Task {
   id: number;
   name: string;
   prompt: string;
+  output_format: OutputFormat;
   cron_expr: string;
+  schedule_description?: string;  // e.g. "every 5 minutes"
   scheduled_at?: string;  // ISO datetime for one-off tasks
   is_one_off: boolean;
   working_dir: string;
   discord_webhook?: string;
   slack_webhook?: string;
+  tags: string[];
+  run_during_quiet_hours: boolean;
+  jitter_seconds: number;
+  active_from?: string;   // ISO datetime; cron runs before this are skipped
+  active_until?: string;  // ISO datetime; task is disabled after this
+  alert_after_seconds: number;
+  disable_after_failures: number;
+  strip_ansi: boolean;
+  include_previous_output: boolean;
+  skip_permissions: boolean;
+  allowed_tools?: string;
+  container?: string;
+  webhook_detail?: WebhookDetail;
+  resume_session: boolean;
+  session_id?: string;    // Session the next run resumes
   enabled: boolean;
   created_at: string;
   updated_at: string;
   last_run_at?: string;
   next_run_at?: string;
-  last_run_status?: 'pending' | 'running' | 'completed' | 'failed';
+  last_run_status?: 'pending' | 'running' | 'completed' | 'failed' | 'skipped';
+  run_count: number;       // Runs that weren't skipped
+  failure_streak: number;  // Consecutive failures since the last completed run
 }
 
 export interface TaskRequest {
   name: string;
   prompt: string;
+  output_format?: OutputFormat;   // Defaults to 'text'
   cron_expr: string;              // Empty for one-off tasks
   scheduled_at?: string;          // ISO datetime for scheduled one-off
-  working_dir: string;
-  discord_webhook?: string;
-  slack_webhook?: string;
+  working_dir: string;            // Absolute path; empty uses default_working_dir
+  discord_webhook?: string;       // Empty uses default_discord_webhook; '-' = none
+  slack_webhook?: string;         // Empty uses default_slack_webhook; '-' = none
+  tags?: string[];
+  run_during_quiet_hours?: boolean;
+  jitter_seconds?: number;        // Random 0..N second delay before cron runs
+  active_from?: string;           // ISO datetime
+  active_until?: string;          // ISO datetime
+  alert_after_seconds?: number;   // Webhook alert if a run is still going after N seconds
+  disable_after_failures?: number; // Disable the task after N consecutive failures; 0 = never
+  strip_ansi?: boolean;           // Default true; false keeps ANSI colors in output
+  include_previous_output?: boolean; // Append the last successful run's output to the prompt
+  skip_permissions?: boolean;     // Default true; false enforces permission prompts
+  allowed_tools?: string;         // --allowedTools list when permissions are enforced
+  container?: string;             // Docker image to run the CLI in
+  webhook_detail?: WebhookDetail; // Omit to use the global setting
+  resume_session?: boolean;       // Continue one Claude session across runs
   enabled: boolean;
 }
 
@@ -35,12 +72,20 @@ export interface TaskListResponse {
 export interface TaskRun {
   id: number;
   task_id: number;
+  seq: number;  // Run number within the task, from 1
   started_at: string;
   ended_at?: string;
-  status: 'pending' | 'running' | 'completed' | 'failed';
+  status: 'pending' | 'running' | 'completed' | 'failed' | 'skipped';
   output: string;
   error?: string;
+  webhook_error?: string;  // Notification delivery failure
+  trigger?: 'cron' | 'oneoff' | 'manual' | 'api';
   duration_ms?: number;
+  cost_usd?: number;  // json and stream-json output only
+  input_tokens?: number;
+  output_tokens?: number;
+  notes?: string;  // Set via PATCH /tasks/{id}/runs/{runId}
+  warning?: string;  // Run succeeded but its json/stream-json output had no result event
 }
 
 export interface TaskRunsResponse {
@@ -48,8 +93,51 @@ export interface TaskRunsResponse {
   total: number;
 }
 
+export interface RecentRun extends TaskRun {
+  task_name: string;
+}
+
+export interface RecentRunsResponse {
+  runs: RecentRun[];
+  total: number;
+}
+
 export interface Settings {
   usage_threshold: number;
+  usage_check_enabled?: boolean;  // false = no usage fetching or threshold enforcement
+  api_allowed_origins?: string;  // Comma-separated CORS allowlist, "*" = any
+  webhook_retry_attempts?: number;
+  webhook_timeout_seconds?: number;  // Per delivery attempt, 1-300 (default 10)
+  public_base_url?: string;  // Enables run links in webhook messages
+  allowed_working_dirs?: string[];  // Empty = unrestricted
+  confirm_before_run?: boolean;  // TUI asks before a manual run
+  render_markdown?: boolean;  // false = TUI shows raw output by default
+  quiet_hours_start?: string;  // "HH:MM" local time; empty = disabled
+  quiet_hours_end?: string;
+  sync_interval_seconds?: number;  // How often the scheduler reloads tasks, 1-3600
+  log_storage?: 'db' | 'file';  // 'file' keeps full run output in log files
+  claude_binary?: string;  // CLI name or path, defaults to 'claude'
+  relaxed_webhook_urls?: boolean;  // Accept any http(s) webhook URL
+  max_concurrent_runs?: number;  // Runs executed at once, 0 = unlimited
+  max_runs_per_task?: number;  // Older runs beyond this are deleted, 0 = keep all
+  api_request_logging?: boolean;  // false = API server doesn't log requests
+  default_working_dir?: string;  // Used for tasks saved without a working_dir
+  default_cron?: string;  // Pre-filled in the TUI add form
+  webhook_detail?: WebhookDetail;  // Default for tasks without their own
+  discord_output_limit?: number;  // Bytes of output in Discord results (1-4000, default 3500)
+  slack_output_limit?: number;  // Bytes of output in Slack results (1-2900, default 2500)
+  list_preview_length?: number;  // First-line previews, e.g. summary webhooks (1-1000, default 300)
+  default_discord_webhook?: string;  // Used by tasks with no discord_webhook
+  default_slack_webhook?: string;  // Used by tasks with no slack_webhook
+}
+
+export interface UsageStatus {
+  enabled: boolean;  // usage_check_enabled and not started with --no-usage-check
+  configured: boolean;  // Credentials were found
+  credentials_path?: string;
+  credentials_error?: string;
+  last_fetch_at?: string;
+  last_fetch_error?: string;
 }
 
 export interface Usage {
@@ -72,3 +160,12 @@ export interface HealthResponse {
   status: string;
   version?: string;
 }
+
+export interface VersionResponse {
+  version: string;
+  commit: string;
+  build_date: string;
+  go_version: string;
+  os: string;
+  arch: string;
+}